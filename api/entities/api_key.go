@@ -0,0 +1,22 @@
+package entities
+
+import (
+	"time"
+	"todo/api/enum"
+)
+
+// APIKey authenticates scripts as its user. Only a SHA-256 hash of the key
+// is stored; Prefix is kept to tell keys apart in listings.
+type APIKey struct {
+	ID         int          `gorm:"primaryKey" json:"id"`
+	UserID     int          `gorm:"not null;index" json:"-"`
+	Name       string       `gorm:"size:100;not null" json:"name"`
+	Prefix     string       `gorm:"size:16;not null" json:"prefix"`
+	TokenHash  string       `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes     []enum.Scope `gorm:"serializer:json;not null" json:"scopes"`
+	ExpiresAt  *time.Time   `json:"expires_at"`
+	LastUsedAt *time.Time   `json:"last_used_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+
+	User *User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
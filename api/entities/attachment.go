@@ -0,0 +1,30 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Attachment is a file uploaded to a task. Its content is stored once per
+// SHA-256 under StorageKey, however many attachments share it.
+type Attachment struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	TaskID      int       `gorm:"not null;index" json:"task_id"`
+	Filename    string    `gorm:"size:255;not null" json:"filename"`
+	ContentType string    `gorm:"size:255;not null" json:"content_type"`
+	Size        int64     `gorm:"not null" json:"size"`
+	SHA256      string    `gorm:"column:sha256;size:64;not null;index" json:"sha256"`
+	StorageKey  string    `gorm:"not null" json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+	// URL points at the download route.
+	URL string `gorm:"-" json:"url"`
+
+	Task *Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (a *Attachment) AfterFind(*gorm.DB) error {
+	a.URL = fmt.Sprintf("/api/tasks/%d/attachments/%d", a.TaskID, a.ID)
+	return nil
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// Comment is owned by the user in AuthorID; Author only names them. Comments
+// without an AuthorID, such as those of guests commenting through a share
+// link or from before authentication, can't be edited or deleted.
+type Comment struct {
+	ID       int    `gorm:"primaryKey" json:"id"`
+	TaskID   int    `gorm:"not null;index" json:"task_id"`
+	AuthorID *int   `gorm:"index" json:"author_id"`
+	Author   string `gorm:"size:255;not null" json:"author"`
+	Body     string `gorm:"not null" json:"body"`
+	Edited   bool   `gorm:"not null;default:false" json:"edited"`
+	// Guest marks comments from share links, whose Author is whatever name
+	// the guest gave.
+	Guest     bool      `gorm:"not null;default:false" json:"guest"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Task *Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	User *User `gorm:"foreignKey:AuthorID;constraint:OnDelete:SET NULL" json:"-"`
+}
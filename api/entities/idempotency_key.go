@@ -0,0 +1,19 @@
+package entities
+
+import "time"
+
+// IdempotencyKey records the response to a request made with an
+// Idempotency-Key header, so a retry gets the same response instead of
+// repeating the request. StatusCode is 0 while the request is in progress.
+type IdempotencyKey struct {
+	UserID      int       `gorm:"primaryKey"`
+	Key         string    `gorm:"primaryKey;size:255"`
+	RequestHash string    `gorm:"size:64;not null"`
+	StatusCode  int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"size:255"`
+	Response    []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
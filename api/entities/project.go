@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// Projects from before workspaces have no workspace and are no longer
+// listed.
+type Project struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	WorkspaceID *int      `gorm:"index" json:"workspace_id"`
+	Name        string    `gorm:"size:100;not null" json:"name"`
+	Color       string    `gorm:"size:7" json:"color"`
+	Archived    bool      `gorm:"not null;default:false" json:"archived"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Workspace *Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
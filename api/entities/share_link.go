@@ -0,0 +1,26 @@
+package entities
+
+import (
+	"time"
+	"todo/api/enum"
+)
+
+// ShareLink opens a single task to anyone holding its token. The token is
+// signed rather than stored, so deleting the link is what revokes it.
+// Links from before workspaces no longer open.
+type ShareLink struct {
+	ID           int              `gorm:"primaryKey" json:"id"`
+	TaskID       int              `gorm:"not null;index" json:"task_id"`
+	WorkspaceID  *int             `gorm:"index" json:"workspace_id"`
+	Access       enum.ShareAccess `gorm:"size:20;not null" json:"access"`
+	PasswordHash string           `json:"-"`
+	CreatedByID  int              `gorm:"not null" json:"created_by_id"`
+	ExpiresAt    time.Time        `gorm:"not null" json:"expires_at"`
+	CreatedAt    time.Time        `json:"created_at"`
+
+	// Protected tells whether opening the link asks for a password.
+	Protected bool `gorm:"-" json:"protected"`
+
+	Task      *Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	CreatedBy *User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
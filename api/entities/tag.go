@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// Tag names are unique within a workspace. Tags from before workspaces
+// have none and are no longer listed.
+type Tag struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	WorkspaceID *int      `gorm:"uniqueIndex:idx_tags_workspace_name" json:"workspace_id"`
+	Name        string    `gorm:"size:50;not null;uniqueIndex:idx_tags_workspace_name" json:"name"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Workspace *Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -1,16 +1,55 @@
 package entities
 
 import (
+	"fmt"
 	"time"
 	"todo/api/enum"
+
+	"gorm.io/gorm"
 )
 
 type Task struct {
-	ID          int             `gorm:"primaryKey" json:"id"`
-	Title       string          `gorm:"size:100" json:"title"`
-	Description string          `json:"description"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	Image       string          `json:"image"`
-	Status      enum.TaskStatus `json:"status"`
+	ID           int               `gorm:"primaryKey" json:"id"`
+	ParentID     *int              `gorm:"index" json:"parent_id"`
+	ProjectID    *int              `gorm:"index" json:"project_id"`
+	WorkspaceID  *int              `gorm:"index" json:"workspace_id"`
+	OwnerID      *int              `gorm:"index" json:"owner_id"`
+	Title        string            `gorm:"size:100" json:"title"`
+	Description  string            `json:"description"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	ImageKey     string            `json:"-"`
+	ThumbnailKey string            `json:"-"`
+	Status       enum.TaskStatus   `json:"status"`
+	Priority     enum.TaskPriority `gorm:"not null;default:MEDIUM" json:"priority"`
+	StartAt      *time.Time        `json:"start_at"`
+	DueAt        *time.Time        `gorm:"index;check:chk_tasks_schedule,start_at IS NULL OR due_at IS NULL OR start_at <= due_at" json:"due_at"`
+	CompletedAt  *time.Time        `json:"completed_at"`
+	DeletedAt    gorm.DeletedAt    `gorm:"index" json:"deleted_at"`
+	Version      int               `gorm:"not null;default:1" json:"version"`
+	Tags         []Tag             `gorm:"many2many:task_tags;constraint:OnDelete:CASCADE" json:"tags,omitempty"`
+	Project      *Project          `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	Workspace    *Workspace        `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Owner        *User             `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+
+	// Progress is the share of descendants that are completed, in percent.
+	// It is only set when the task has descendants.
+	Progress *float64 `gorm:"-" json:"progress,omitempty"`
+	Children []Task   `gorm:"-" json:"children,omitempty"`
+	// CommentCount is only set in task lists.
+	CommentCount *int64 `gorm:"-" json:"comment_count,omitempty"`
+	// ImageURL and ThumbnailURL point at the download routes. The files
+	// themselves live in blob storage under ImageKey and ThumbnailKey.
+	ImageURL     string `gorm:"-" json:"image_url,omitempty"`
+	ThumbnailURL string `gorm:"-" json:"thumbnail_url,omitempty"`
+}
+
+func (t *Task) AfterFind(*gorm.DB) error {
+	if len(t.ImageKey) > 0 {
+		t.ImageURL = fmt.Sprintf("/api/tasks/%d/image", t.ID)
+	}
+	if len(t.ThumbnailKey) > 0 {
+		t.ThumbnailURL = fmt.Sprintf("/api/tasks/%d/thumbnail", t.ID)
+	}
+	return nil
 }
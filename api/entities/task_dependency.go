@@ -0,0 +1,13 @@
+package entities
+
+import "time"
+
+// TaskDependency means TaskID cannot be completed until BlockedByID is done.
+type TaskDependency struct {
+	TaskID      int       `gorm:"primaryKey;check:chk_task_dependencies_self,task_id <> blocked_by_id" json:"task_id"`
+	BlockedByID int       `gorm:"primaryKey;index" json:"blocked_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Task      Task `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	BlockedBy Task `gorm:"foreignKey:BlockedByID;constraint:OnDelete:CASCADE" json:"-"`
+}
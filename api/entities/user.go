@@ -0,0 +1,26 @@
+package entities
+
+import "time"
+
+type User struct {
+	ID           int       `gorm:"primaryKey" json:"id"`
+	Email        string    `gorm:"size:255;not null;uniqueIndex" json:"email"`
+	PasswordHash string    `gorm:"not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// RefreshToken is stored as a SHA-256 hash only. Every refresh replaces the
+// token with a new one of the same family, so presenting a replaced token
+// again reveals that it leaked and revokes the whole family.
+type RefreshToken struct {
+	ID        int       `gorm:"primaryKey"`
+	UserID    int       `gorm:"not null;index"`
+	FamilyID  string    `gorm:"size:32;not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+
+	User *User `gorm:"constraint:OnDelete:CASCADE"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"time"
+	"todo/api/enum"
+)
+
+type Workspace struct {
+	ID        int       `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Role is the caller's role, only set in workspace lists.
+	Role enum.Role `gorm:"-" json:"role,omitempty"`
+}
+
+type Membership struct {
+	WorkspaceID int       `gorm:"primaryKey" json:"workspace_id"`
+	UserID      int       `gorm:"primaryKey;index" json:"user_id"`
+	Role        enum.Role `gorm:"size:20;not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Workspace *Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	User      *User      `gorm:"constraint:OnDelete:CASCADE" json:"user,omitempty"`
+}
+
+// Invitation lets whoever registered with Email join the workspace. Like
+// API keys, only a SHA-256 hash of its token is stored.
+type Invitation struct {
+	ID          int       `gorm:"primaryKey" json:"id"`
+	WorkspaceID int       `gorm:"not null;index" json:"workspace_id"`
+	Email       string    `gorm:"size:255;not null" json:"email"`
+	Role        enum.Role `gorm:"size:20;not null" json:"role"`
+	TokenHash   string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	InvitedByID int       `gorm:"not null" json:"invited_by_id"`
+	ExpiresAt   time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Workspace *Workspace `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	InvitedBy *User      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,23 @@
+package enum
+
+type Permission string
+
+const (
+	PermissionTasksRead   Permission = "tasks:read"
+	PermissionTasksWrite  Permission = "tasks:write"
+	PermissionTasksDelete Permission = "tasks:delete"
+	PermissionMembers     Permission = "members:manage"
+)
+
+// minimum is the lowest role granted the permission.
+func (e Permission) minimum() Role {
+	switch e {
+	case PermissionTasksRead:
+		return RoleViewer
+	case PermissionTasksWrite:
+		return RoleMember
+	case PermissionTasksDelete, PermissionMembers:
+		return RoleAdmin
+	}
+	return RoleOwner
+}
@@ -0,0 +1,39 @@
+package enum
+
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleOwner, RoleAdmin, RoleMember, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// Rank orders the roles from viewer to owner. Nobody can grant or take away
+// a role ranking above their own.
+func (e Role) Rank() int {
+	switch e {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleMember:
+		return 2
+	case RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// Can reports whether the role grants the permission.
+func (e Role) Can(permission Permission) bool {
+	return e.Rank() >= permission.minimum().Rank()
+}
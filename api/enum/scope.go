@@ -0,0 +1,19 @@
+package enum
+
+type Scope string
+
+const (
+	ScopeTasksRead  Scope = "tasks:read"
+	ScopeTasksWrite Scope = "tasks:write"
+)
+
+// Scopes are all the scopes an API key can carry.
+var Scopes = []Scope{ScopeTasksRead, ScopeTasksWrite}
+
+func (e Scope) IsValid() bool {
+	switch e {
+	case ScopeTasksRead, ScopeTasksWrite:
+		return true
+	}
+	return false
+}
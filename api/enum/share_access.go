@@ -0,0 +1,16 @@
+package enum
+
+type ShareAccess string
+
+const (
+	ShareAccessRead    ShareAccess = "read"
+	ShareAccessComment ShareAccess = "comment"
+)
+
+func (e ShareAccess) IsValid() bool {
+	switch e {
+	case ShareAccessRead, ShareAccessComment:
+		return true
+	}
+	return false
+}
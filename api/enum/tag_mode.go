@@ -0,0 +1,16 @@
+package enum
+
+type TagMode string
+
+const (
+	TagModeAny TagMode = "any"
+	TagModeAll TagMode = "all"
+)
+
+func (e TagMode) IsValid() bool {
+	switch e {
+	case TagModeAny, TagModeAll:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package enum
+
+type TaskEvent string
+
+const (
+	TaskEventCreated TaskEvent = "created"
+	TaskEventUpdated TaskEvent = "updated"
+	TaskEventDeleted TaskEvent = "deleted"
+	// TaskEventReset tells a stream that events were missed and the tasks
+	// have to be listed again.
+	TaskEventReset TaskEvent = "reset"
+)
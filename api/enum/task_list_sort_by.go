@@ -4,14 +4,16 @@ type TaskListSortBy string
 
 const (
 	TaskListSortByTitle     TaskListSortBy = "title"
-	TaskListSortByCreatedAt                = "created_at"
-	TaskListSortByUpdatedAt                = "updated_at"
+	TaskListSortByCreatedAt TaskListSortBy = "created_at"
+	TaskListSortByUpdatedAt TaskListSortBy = "updated_at"
 	TaskListSortByStatus    TaskListSortBy = "status"
+	TaskListSortByDueAt     TaskListSortBy = "due_at"
+	TaskListSortByPriority  TaskListSortBy = "priority"
 )
 
 func (e TaskListSortBy) IsValid() bool {
 	switch e {
-	case TaskListSortByTitle, TaskListSortByCreatedAt, TaskListSortByUpdatedAt, TaskListSortByStatus:
+	case TaskListSortByTitle, TaskListSortByCreatedAt, TaskListSortByUpdatedAt, TaskListSortByStatus, TaskListSortByDueAt, TaskListSortByPriority:
 		return true
 	}
 	return false
@@ -0,0 +1,31 @@
+package enum
+
+type TaskPriority string
+
+const (
+	TaskPriorityLow    TaskPriority = "LOW"
+	TaskPriorityMedium TaskPriority = "MEDIUM"
+	TaskPriorityHigh   TaskPriority = "HIGH"
+	TaskPriorityUrgent TaskPriority = "URGENT"
+)
+
+// TaskPriorities lists every priority from least to most important.
+var TaskPriorities = []TaskPriority{TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent}
+
+func (e TaskPriority) IsValid() bool {
+	switch e {
+	case TaskPriorityLow, TaskPriorityMedium, TaskPriorityHigh, TaskPriorityUrgent:
+		return true
+	}
+	return false
+}
+
+// Rank orders priorities by importance rather than alphabetically.
+func (e TaskPriority) Rank() int {
+	for i, p := range TaskPriorities {
+		if p == e {
+			return i + 1
+		}
+	}
+	return 0
+}
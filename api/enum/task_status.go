@@ -3,13 +3,17 @@ package enum
 type TaskStatus string
 
 const (
+	TaskStatusTodo       TaskStatus = "TODO"
 	TaskStatusInProgress TaskStatus = "IN_PROGRESS"
+	TaskStatusBlocked    TaskStatus = "BLOCKED"
+	TaskStatusInReview   TaskStatus = "IN_REVIEW"
 	TaskStatusCompleted  TaskStatus = "COMPLETED"
+	TaskStatusCancelled  TaskStatus = "CANCELLED"
 )
 
 func (e TaskStatus) IsValid() bool {
 	switch e {
-	case TaskStatusInProgress, TaskStatusCompleted:
+	case TaskStatusTodo, TaskStatusInProgress, TaskStatusBlocked, TaskStatusInReview, TaskStatusCompleted, TaskStatusCancelled:
 		return true
 	}
 	return false
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type APIKeyHandler interface {
+	GetAPIKeys(c *fiber.Ctx) error
+	CreateAPIKey(c *fiber.Ctx) error
+	DeleteAPIKey(c *fiber.Ctx) error
+}
+
+type apiKeyHandler struct {
+	apiKeyService services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyService) APIKeyHandler {
+	return &apiKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (h apiKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.apiKeyService.GetAPIKeys(middleware.UserID(c))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: keys})
+}
+
+// CreateAPIKey answers with the key itself. It is not stored and can't be
+// shown again.
+func (h apiKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req request.CreatedAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(middleware.UserID(c), req)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: key})
+}
+
+func (h apiKeyHandler) DeleteAPIKey(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.apiKeyService.DeleteAPIKey(middleware.UserID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
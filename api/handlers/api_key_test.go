@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_apiKeyHandler_GetAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		apiKeyService         *mock.MockAPIKeyService
+		apiKeyServiceBehavior func(*mock.MockAPIKeyService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().GetAPIKeys(0).Return([]entities.APIKey{{ID: 1, Name: "ci"}}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get failed",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().GetAPIKeys(0).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.apiKeyServiceBehavior(tt.fields.apiKeyService)
+
+			app := fiber.New()
+			h := apiKeyHandler{
+				apiKeyService: tt.fields.apiKeyService,
+			}
+			app.Get("/api/me/tokens", h.GetAPIKeys)
+
+			req := httptest.NewRequest("GET", "/api/me/tokens", nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_apiKeyHandler_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		apiKeyService         *mock.MockAPIKeyService
+		apiKeyServiceBehavior func(*mock.MockAPIKeyService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().CreateAPIKey(0, request.CreatedAPIKeyRequest{Name: "ci", Scopes: []enum.Scope{enum.ScopeTasksRead}}).
+						Return(response.CreatedAPIKey{APIKey: entities.APIKey{ID: 1, Name: "ci"}, Token: "todo_key"}, nil)
+				},
+			},
+			args: args{
+				body: `{"name":"ci","scopes":["tasks:read"]}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "name is missing",
+			fields: fields{
+				apiKeyService:         mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {},
+			},
+			args: args{
+				body: `{"scopes":["tasks:read"]}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "scope is unknown",
+			fields: fields{
+				apiKeyService:         mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {},
+			},
+			args: args{
+				body: `{"name":"ci","scopes":["tasks:admin"]}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "expiry is in the past",
+			fields: fields{
+				apiKeyService:         mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {},
+			},
+			args: args{
+				body: `{"name":"ci","expires_at":"2020-01-01T00:00:00Z"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().CreateAPIKey(0, gomock.Any()).Return(response.CreatedAPIKey{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"ci"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.apiKeyServiceBehavior(tt.fields.apiKeyService)
+
+			app := fiber.New()
+			h := apiKeyHandler{
+				apiKeyService: tt.fields.apiKeyService,
+			}
+			app.Post("/api/me/tokens", h.CreateAPIKey)
+
+			req := httptest.NewRequest("POST", "/api/me/tokens", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_apiKeyHandler_DeleteAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		apiKeyService         *mock.MockAPIKeyService
+		apiKeyServiceBehavior func(*mock.MockAPIKeyService)
+	}
+	type args struct {
+		id string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().DeleteAPIKey(0, 1).Return(nil)
+				},
+			},
+			args: args{
+				id: "1",
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "invalid id",
+			fields: fields{
+				apiKeyService:         mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {},
+			},
+			args: args{
+				id: "foo",
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().DeleteAPIKey(0, 1).Return(services.ErrAPIKeyNotFound)
+				},
+			},
+			args: args{
+				id: "1",
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				apiKeyService: mock.NewMockAPIKeyService(ctrl),
+				apiKeyServiceBehavior: func(mas *mock.MockAPIKeyService) {
+					mas.EXPECT().DeleteAPIKey(0, 1).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				id: "1",
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.apiKeyServiceBehavior(tt.fields.apiKeyService)
+
+			app := fiber.New()
+			h := apiKeyHandler{
+				apiKeyService: tt.fields.apiKeyService,
+			}
+			app.Delete("/api/me/tokens/:id", h.DeleteAPIKey)
+
+			req := httptest.NewRequest("DELETE", "/api/me/tokens/"+tt.args.id, nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
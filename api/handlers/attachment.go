@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AttachmentHandler interface {
+	GetAttachments(c *fiber.Ctx) error
+	CreateAttachment(c *fiber.Ctx) error
+	GetAttachment(c *fiber.Ctx) error
+	DeleteAttachment(c *fiber.Ctx) error
+}
+
+type attachmentHandler struct {
+	attachmentService services.AttachmentService
+}
+
+func NewAttachmentHandler(attachmentService services.AttachmentService) AttachmentHandler {
+	return &attachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+func (h attachmentHandler) GetAttachments(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	attachments, err := h.attachmentService.GetAttachments(middleware.WorkspaceID(c), taskID)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: attachments})
+}
+
+func (h attachmentHandler) CreateAttachment(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	defer file.Close()
+
+	upload := request.UploadedAttachment{
+		Reader:      file,
+		Filename:    fh.Filename,
+		Size:        fh.Size,
+		ContentType: fh.Header.Get(fiber.HeaderContentType),
+	}
+	err = upload.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.attachmentService.CreateAttachment(middleware.WorkspaceID(c), taskID, upload)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+// GetAttachment streams the file as a download under its original name.
+func (h attachmentHandler) GetAttachment(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("attachmentId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	attachment, reader, err := h.attachmentService.GetAttachment(taskID, id)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	c.Set(fiber.HeaderContentType, attachment.ContentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	// uploaded HTML must not be rendered as part of the API's origin
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	return c.Status(fiber.StatusOK).SendStream(reader, int(attachment.Size))
+}
+
+func (h attachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("attachmentId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.attachmentService.DeleteAttachment(taskID, id)
+	if err != nil {
+		return attachmentError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func attachmentError(err error) error {
+	if errors.Is(err, services.ErrTaskNotFound) || errors.Is(err, services.ErrAttachmentNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, services.ErrAttachmentLimit) {
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	if errors.Is(err, services.ErrAttachmentTooLarge) {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError)
+}
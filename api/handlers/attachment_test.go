@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func attachmentUploadRequest(t *testing.T, filename string, content string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/api/tasks/1/attachments", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func Test_attachmentHandler_GetAttachments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		attachmentService         *mock.MockAttachmentService
+		attachmentServiceBehavior func(*mock.MockAttachmentService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().GetAttachments(0, 1).Return([]entities.Attachment{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/attachments", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().GetAttachments(0, 1).Return(nil, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/attachments", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.attachmentServiceBehavior(tt.fields.attachmentService)
+
+			app := fiber.New()
+			h := attachmentHandler{
+				attachmentService: tt.fields.attachmentService,
+			}
+			app.Get("/api/tasks/:id/attachments", h.GetAttachments)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_attachmentHandler_CreateAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		attachmentService         *mock.MockAttachmentService
+		attachmentServiceBehavior func(*mock.MockAttachmentService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().CreateAttachment(0, 1, gomock.Any()).DoAndReturn(func(_ int, _ int, upload request.UploadedAttachment) error {
+						content, _ := io.ReadAll(upload.Reader)
+						if string(content) != "hello" || upload.Filename != "notes.txt" || upload.Size != 5 {
+							t.Errorf("upload = %+v, content = %s", upload, content)
+						}
+						return nil
+					})
+				},
+			},
+			args: args{
+				req: attachmentUploadRequest(t, "notes.txt", "hello"),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "file is missing",
+			fields: fields{
+				attachmentService:         mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {},
+			},
+			args: args{
+				req: httptest.NewRequest("POST", "/api/tasks/1/attachments", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "file is empty",
+			fields: fields{
+				attachmentService:         mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {},
+			},
+			args: args{
+				req: attachmentUploadRequest(t, "notes.txt", ""),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "attachment limit reached",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().CreateAttachment(0, 1, gomock.Any()).Return(services.ErrAttachmentLimit)
+				},
+			},
+			args: args{
+				req: attachmentUploadRequest(t, "notes.txt", "hello"),
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "total size exceeded",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().CreateAttachment(0, 1, gomock.Any()).Return(services.ErrAttachmentTooLarge)
+				},
+			},
+			args: args{
+				req: attachmentUploadRequest(t, "notes.txt", "hello"),
+			},
+			code: fiber.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().CreateAttachment(0, 1, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: attachmentUploadRequest(t, "notes.txt", "hello"),
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.attachmentServiceBehavior(tt.fields.attachmentService)
+
+			app := fiber.New()
+			h := attachmentHandler{
+				attachmentService: tt.fields.attachmentService,
+			}
+			app.Post("/api/tasks/:id/attachments", h.CreateAttachment)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_attachmentHandler_GetAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		attachmentService         *mock.MockAttachmentService
+		attachmentServiceBehavior func(*mock.MockAttachmentService)
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		path        string
+		code        int
+		disposition string
+	}{
+		{
+			name: "success",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().GetAttachment(1, 2).Return(entities.Attachment{
+						ID:          2,
+						TaskID:      1,
+						Filename:    "spec sheet.pdf",
+						ContentType: "application/pdf",
+						Size:        5,
+					}, io.NopCloser(strings.NewReader("hello")), nil)
+				},
+			},
+			path:        "/api/tasks/1/attachments/2",
+			code:        fiber.StatusOK,
+			disposition: `attachment; filename="spec sheet.pdf"`,
+		},
+		{
+			name: "attachment id is not int",
+			fields: fields{
+				attachmentService:         mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {},
+			},
+			path: "/api/tasks/1/attachments/foo",
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "attachment not found",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().GetAttachment(1, 2).Return(entities.Attachment{}, nil, services.ErrAttachmentNotFound)
+				},
+			},
+			path: "/api/tasks/1/attachments/2",
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.attachmentServiceBehavior(tt.fields.attachmentService)
+
+			app := fiber.New()
+			h := attachmentHandler{
+				attachmentService: tt.fields.attachmentService,
+			}
+			app.Get("/api/tasks/:id/attachments/:attachmentId", h.GetAttachment)
+
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if len(tt.disposition) > 0 {
+				assert.Equal(t, tt.disposition, resp.Header.Get("Content-Disposition"))
+				assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, "hello", string(body))
+			}
+		})
+	}
+}
+
+func Test_attachmentHandler_DeleteAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		attachmentService         *mock.MockAttachmentService
+		attachmentServiceBehavior func(*mock.MockAttachmentService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().DeleteAttachment(1, 2).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "attachment not found",
+			fields: fields{
+				attachmentService: mock.NewMockAttachmentService(ctrl),
+				attachmentServiceBehavior: func(mas *mock.MockAttachmentService) {
+					mas.EXPECT().DeleteAttachment(1, 2).Return(services.ErrAttachmentNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.attachmentServiceBehavior(tt.fields.attachmentService)
+
+			app := fiber.New()
+			h := attachmentHandler{
+				attachmentService: tt.fields.attachmentService,
+			}
+			app.Delete("/api/tasks/:id/attachments/:attachmentId", h.DeleteAttachment)
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/tasks/1/attachments/2", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
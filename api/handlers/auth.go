@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AuthHandler interface {
+	Register(c *fiber.Ctx) error
+	Login(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+}
+
+type authHandler struct {
+	authService services.AuthService
+}
+
+func NewAuthHandler(authService services.AuthService) AuthHandler {
+	return &authHandler{
+		authService: authService,
+	}
+}
+
+func (h authHandler) Register(c *fiber.Ctx) error {
+	var req request.RegisterRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.authService.Register(req)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h authHandler) Login(c *fiber.Ctx) error {
+	var req request.LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := h.authService.Login(req)
+	if err != nil {
+		return authError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tokens})
+}
+
+// Refresh rotates the refresh token; the one in the request can't be used
+// again.
+func (h authHandler) Refresh(c *fiber.Ctx) error {
+	var req request.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		return authError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tokens})
+}
+
+func (h authHandler) Logout(c *fiber.Ctx) error {
+	var req request.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.authService.Logout(req.RefreshToken)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func authError(err error) error {
+	if errors.Is(err, services.ErrInvalidCredentials) ||
+		errors.Is(err, services.ErrInvalidRefreshToken) ||
+		errors.Is(err, services.ErrRefreshTokenReplayed) {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError)
+}
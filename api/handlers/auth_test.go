@@ -0,0 +1,386 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_authHandler_Register(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		authService         *mock.MockAuthService
+		authServiceBehavior func(*mock.MockAuthService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Register(request.RegisterRequest{Email: "jane@example.com", Password: "password"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"password"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "email is invalid",
+			fields: fields{
+				authService:         mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {},
+			},
+			args: args{
+				body: `{"email":"jane","password":"password"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "password is too short",
+			fields: fields{
+				authService:         mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"short"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "email taken",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Register(gomock.Any()).Return(services.ErrEmailTaken)
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"password"}`,
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "register failed",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Register(gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"password"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.authServiceBehavior(tt.fields.authService)
+
+			app := fiber.New()
+			h := authHandler{
+				authService: tt.fields.authService,
+			}
+			app.Post("/api/auth/register", h.Register)
+
+			req := httptest.NewRequest("POST", "/api/auth/register", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_authHandler_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := response.AuthTokens{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh"}
+
+	type fields struct {
+		authService         *mock.MockAuthService
+		authServiceBehavior func(*mock.MockAuthService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+		want   *response.AuthTokens
+	}{
+		{
+			name: "success",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Login(request.LoginRequest{Email: "jane@example.com", Password: "password"}).Return(tokens, nil)
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"password"}`,
+			},
+			code: fiber.StatusOK,
+			want: &tokens,
+		},
+		{
+			name: "password is missing",
+			fields: fields{
+				authService:         mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {},
+			},
+			args: args{
+				body: `{"email":"jane@example.com"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "invalid credentials",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Login(gomock.Any()).Return(response.AuthTokens{}, services.ErrInvalidCredentials)
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"wrong password"}`,
+			},
+			code: fiber.StatusUnauthorized,
+		},
+		{
+			name: "login failed",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Login(gomock.Any()).Return(response.AuthTokens{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"email":"jane@example.com","password":"password"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.authServiceBehavior(tt.fields.authService)
+
+			app := fiber.New()
+			h := authHandler{
+				authService: tt.fields.authService,
+			}
+			app.Post("/api/auth/login", h.Login)
+
+			req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if tt.want != nil {
+				var body struct {
+					Data response.AuthTokens `json:"data"`
+				}
+				json.NewDecoder(resp.Body).Decode(&body)
+				assert.Equal(t, *tt.want, body.Data)
+			}
+		})
+	}
+}
+
+func Test_authHandler_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		authService         *mock.MockAuthService
+		authServiceBehavior func(*mock.MockAuthService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Refresh("refresh").Return(response.AuthTokens{AccessToken: "access"}, nil)
+				},
+			},
+			args: args{
+				body: `{"refresh_token":"refresh"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "refresh token is missing",
+			fields: fields{
+				authService:         mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "refresh token is invalid",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Refresh("refresh").Return(response.AuthTokens{}, services.ErrInvalidRefreshToken)
+				},
+			},
+			args: args{
+				body: `{"refresh_token":"refresh"}`,
+			},
+			code: fiber.StatusUnauthorized,
+		},
+		{
+			name: "refresh token replayed",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Refresh("refresh").Return(response.AuthTokens{}, services.ErrRefreshTokenReplayed)
+				},
+			},
+			args: args{
+				body: `{"refresh_token":"refresh"}`,
+			},
+			code: fiber.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.authServiceBehavior(tt.fields.authService)
+
+			app := fiber.New()
+			h := authHandler{
+				authService: tt.fields.authService,
+			}
+			app.Post("/api/auth/refresh", h.Refresh)
+
+			req := httptest.NewRequest("POST", "/api/auth/refresh", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_authHandler_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		authService         *mock.MockAuthService
+		authServiceBehavior func(*mock.MockAuthService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Logout("refresh").Return(nil)
+				},
+			},
+			args: args{
+				body: `{"refresh_token":"refresh"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "refresh token is missing",
+			fields: fields{
+				authService:         mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "logout failed",
+			fields: fields{
+				authService: mock.NewMockAuthService(ctrl),
+				authServiceBehavior: func(mas *mock.MockAuthService) {
+					mas.EXPECT().Logout("refresh").Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"refresh_token":"refresh"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.authServiceBehavior(tt.fields.authService)
+
+			app := fiber.New()
+			h := authHandler{
+				authService: tt.fields.authService,
+			}
+			app.Post("/api/auth/logout", h.Logout)
+
+			req := httptest.NewRequest("POST", "/api/auth/logout", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type CommentHandler interface {
+	GetComments(c *fiber.Ctx) error
+	CreateComment(c *fiber.Ctx) error
+	UpdateComment(c *fiber.Ctx) error
+	DeleteComment(c *fiber.Ctx) error
+}
+
+type commentHandler struct {
+	commentService services.CommentService
+}
+
+func NewCommentHandler(commentService services.CommentService) CommentHandler {
+	return &commentHandler{
+		commentService: commentService,
+	}
+}
+
+func (h commentHandler) GetComments(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	comments, err := h.commentService.GetComments(middleware.WorkspaceID(c), taskID)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: comments})
+}
+
+func (h commentHandler) CreateComment(c *fiber.Ctx) error {
+	var req request.CreatedCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.commentService.CreateComment(middleware.WorkspaceID(c), taskID, middleware.UserID(c), req)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h commentHandler) UpdateComment(c *fiber.Ctx) error {
+	var req request.UpdatedCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("commentId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.commentService.UpdateComment(taskID, id, middleware.UserID(c), req)
+	if err != nil {
+		return commentError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h commentHandler) DeleteComment(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("commentId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.commentService.DeleteComment(taskID, id, middleware.UserID(c))
+	if err != nil {
+		return commentError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func commentError(err error) error {
+	if errors.Is(err, services.ErrCommentNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, services.ErrCommentForbidden) {
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError)
+}
@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_commentHandler_GetComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		commentService         *mock.MockCommentService
+		commentServiceBehavior func(*mock.MockCommentService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().GetComments(0, 1).Return([]entities.Comment{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/comments", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/foo/comments", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().GetComments(0, 1).Return(nil, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/comments", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get comments failed",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().GetComments(0, 1).Return(nil, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/comments", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.commentServiceBehavior(tt.fields.commentService)
+
+			app := fiber.New()
+			h := commentHandler{
+				commentService: tt.fields.commentService,
+			}
+			app.Get("/api/tasks/:id/comments", h.GetComments)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_commentHandler_CreateComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		commentService         *mock.MockCommentService
+		commentServiceBehavior func(*mock.MockCommentService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().CreateComment(0, 1, 0, request.CreatedCommentRequest{Body: "foo"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"body":"foo"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "body is required",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+				},
+			},
+			args: args{
+				body: `{"body":" "}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().CreateComment(0, 1, 0, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				body: `{"body":"foo"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "create comment failed",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().CreateComment(0, 1, 0, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"body":"foo"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.commentServiceBehavior(tt.fields.commentService)
+
+			app := fiber.New()
+			h := commentHandler{
+				commentService: tt.fields.commentService,
+			}
+			app.Post("/api/tasks/:id/comments", h.CreateComment)
+
+			req := httptest.NewRequest("POST", "/api/tasks/1/comments", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_commentHandler_UpdateComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		commentService         *mock.MockCommentService
+		commentServiceBehavior func(*mock.MockCommentService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().UpdateComment(1, 2, 0, request.UpdatedCommentRequest{Body: "bar"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"body":"bar"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "comment of another author",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().UpdateComment(1, 2, 0, gomock.Any()).Return(services.ErrCommentForbidden)
+				},
+			},
+			args: args{
+				body: `{"body":"bar"}`,
+			},
+			code: fiber.StatusForbidden,
+		},
+		{
+			name: "comment not found",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().UpdateComment(1, 2, 0, gomock.Any()).Return(services.ErrCommentNotFound)
+				},
+			},
+			args: args{
+				body: `{"body":"bar"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "update comment failed",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().UpdateComment(1, 2, 0, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"body":"bar"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.commentServiceBehavior(tt.fields.commentService)
+
+			app := fiber.New()
+			h := commentHandler{
+				commentService: tt.fields.commentService,
+			}
+			app.Put("/api/tasks/:id/comments/:commentId", h.UpdateComment)
+
+			req := httptest.NewRequest("PUT", "/api/tasks/1/comments/2", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_commentHandler_DeleteComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		commentService         *mock.MockCommentService
+		commentServiceBehavior func(*mock.MockCommentService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().DeleteComment(1, 2, 0).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "comment of another author",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().DeleteComment(1, 2, 0).Return(services.ErrCommentForbidden)
+				},
+			},
+			code: fiber.StatusForbidden,
+		},
+		{
+			name: "comment not found",
+			fields: fields{
+				commentService: mock.NewMockCommentService(ctrl),
+				commentServiceBehavior: func(mcs *mock.MockCommentService) {
+					mcs.EXPECT().DeleteComment(1, 2, 0).Return(services.ErrCommentNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.commentServiceBehavior(tt.fields.commentService)
+
+			app := fiber.New()
+			h := commentHandler{
+				commentService: tt.fields.commentService,
+			}
+			app.Delete("/api/tasks/:id/comments/:commentId", h.DeleteComment)
+
+			req := httptest.NewRequest("DELETE", "/api/tasks/1/comments/2", nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
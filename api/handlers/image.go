@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/pkg/storage"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ImageHandler interface {
+	UploadTaskImage(c *fiber.Ctx) error
+	GetTaskImage(c *fiber.Ctx) error
+	GetTaskThumbnail(c *fiber.Ctx) error
+	DeleteTaskImage(c *fiber.Ctx) error
+}
+
+type imageHandler struct {
+	imageService services.ImageService
+}
+
+func NewImageHandler(imageService services.ImageService) ImageHandler {
+	return &imageHandler{
+		imageService: imageService,
+	}
+}
+
+func (h imageHandler) UploadTaskImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	fh, err := c.FormFile("image")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "image file is required")
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	defer file.Close()
+
+	upload := request.UploadedImage{
+		Reader: file,
+		Size:   fh.Size,
+	}
+	err = upload.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.imageService.UploadTaskImage(middleware.WorkspaceID(c), id, version, upload)
+	if err != nil {
+		return imageError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+// GetTaskImage streams the image from blob storage without buffering it.
+func (h imageHandler) GetTaskImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	reader, object, err := h.imageService.GetTaskImage(middleware.WorkspaceID(c), id)
+	if err != nil {
+		return imageError(err)
+	}
+
+	return sendBlob(c, reader, object)
+}
+
+func (h imageHandler) GetTaskThumbnail(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	reader, object, err := h.imageService.GetTaskThumbnail(middleware.WorkspaceID(c), id)
+	if err != nil {
+		return imageError(err)
+	}
+
+	return sendBlob(c, reader, object)
+}
+
+func (h imageHandler) DeleteTaskImage(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.imageService.DeleteTaskImage(middleware.WorkspaceID(c), id, version)
+	if err != nil {
+		return imageError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func sendBlob(c *fiber.Ctx, reader io.ReadCloser, object storage.Object) error {
+	if len(object.ContentType) > 0 {
+		c.Set(fiber.HeaderContentType, object.ContentType)
+	}
+	size := -1
+	if object.Size > 0 {
+		size = int(object.Size)
+	}
+
+	return c.Status(fiber.StatusOK).SendStream(reader, size)
+}
+
+func imageError(err error) error {
+	if errors.Is(err, services.ErrTaskNotFound) || errors.Is(err, services.ErrImageNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	if errors.Is(err, services.ErrImageUnsupported) {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, err.Error())
+	}
+	if errors.Is(err, services.ErrImageTooLarge) {
+		return fiber.NewError(fiber.StatusRequestEntityTooLarge, err.Error())
+	}
+	if errors.Is(err, services.ErrImageInvalid) {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if errors.Is(err, services.ErrTaskVersionMismatch) {
+		return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError)
+}
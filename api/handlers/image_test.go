@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+	"todo/api/models/request"
+	"todo/api/services"
+	"todo/api/services/mock"
+	"todo/pkg/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func imageUploadRequest(t *testing.T, contentType string, content string) *http.Request {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="image"; filename="a.png"`)
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	w.Close()
+
+	req := httptest.NewRequest("POST", "/api/tasks/1/image", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("If-Match", `"1"`)
+	return req
+}
+
+func Test_imageHandler_UploadTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		imageService         *mock.MockImageService
+		imageServiceBehavior func(*mock.MockImageService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).DoAndReturn(func(_ int, _ int, _ int, upload request.UploadedImage) error {
+						content, _ := io.ReadAll(upload.Reader)
+						if string(content) != "png" || upload.Size != 3 {
+							t.Errorf("upload = %+v, content = %s", upload, content)
+						}
+						return nil
+					})
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "png"),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "image is missing",
+			fields: fields{
+				imageService:         mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("POST", "/api/tasks/1/image", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "image is empty",
+			fields: fields{
+				imageService:         mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", ""),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "not an image",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).Return(services.ErrImageUnsupported)
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "foo"),
+			},
+			code: fiber.StatusUnsupportedMediaType,
+		},
+		{
+			name: "image too large",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).Return(services.ErrImageTooLarge)
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "png"),
+			},
+			code: fiber.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "image is corrupt",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).Return(services.ErrImageInvalid)
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "png"),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "if match is missing",
+			fields: fields{
+				imageService:         mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := imageUploadRequest(t, "image/png", "png")
+					req.Header.Del("If-Match")
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionRequired,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "png"),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().UploadTaskImage(0, 1, 1, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: imageUploadRequest(t, "image/png", "png"),
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.imageServiceBehavior(tt.fields.imageService)
+
+			app := fiber.New()
+			h := imageHandler{
+				imageService: tt.fields.imageService,
+			}
+			app.Post("/api/tasks/:id/image", h.UploadTaskImage)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_imageHandler_GetTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		imageService         *mock.MockImageService
+		imageServiceBehavior func(*mock.MockImageService)
+	}
+	tests := []struct {
+		name        string
+		fields      fields
+		code        int
+		contentType string
+		body        string
+	}{
+		{
+			name: "success",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().GetTaskImage(0, 1).
+						Return(io.NopCloser(strings.NewReader("png")), storage.Object{ContentType: "image/png", Size: 3}, nil)
+				},
+			},
+			code:        fiber.StatusOK,
+			contentType: "image/png",
+			body:        "png",
+		},
+		{
+			name: "image not found",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().GetTaskImage(0, 1).Return(nil, storage.Object{}, services.ErrImageNotFound)
+				},
+			},
+			code:        fiber.StatusNotFound,
+			contentType: fiber.MIMEApplicationJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.imageServiceBehavior(tt.fields.imageService)
+
+			app := fiber.New(fiber.Config{
+				ErrorHandler: func(c *fiber.Ctx, err error) error {
+					return c.Status(err.(*fiber.Error).Code).JSON(err)
+				},
+			})
+			h := imageHandler{
+				imageService: tt.fields.imageService,
+			}
+			app.Get("/api/tasks/:id/image", h.GetTaskImage)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks/1/image", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get("Content-Type"))
+			if len(tt.body) > 0 {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.body, string(body))
+			}
+		})
+	}
+}
+
+func Test_imageHandler_GetTaskThumbnail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		imageService         *mock.MockImageService
+		imageServiceBehavior func(*mock.MockImageService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().GetTaskThumbnail(0, 1).
+						Return(io.NopCloser(strings.NewReader("jpg")), storage.Object{ContentType: "image/jpeg", Size: 3}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "thumbnail not found",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().GetTaskThumbnail(0, 1).Return(nil, storage.Object{}, services.ErrImageNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.imageServiceBehavior(tt.fields.imageService)
+
+			app := fiber.New()
+			h := imageHandler{
+				imageService: tt.fields.imageService,
+			}
+			app.Get("/api/tasks/:id/thumbnail", h.GetTaskThumbnail)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks/1/thumbnail", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_imageHandler_DeleteTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		imageService         *mock.MockImageService
+		imageServiceBehavior func(*mock.MockImageService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().DeleteTaskImage(0, 1, 2).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "image not found",
+			fields: fields{
+				imageService: mock.NewMockImageService(ctrl),
+				imageServiceBehavior: func(mis *mock.MockImageService) {
+					mis.EXPECT().DeleteTaskImage(0, 1, 2).Return(services.ErrImageNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.imageServiceBehavior(tt.fields.imageService)
+
+			app := fiber.New()
+			h := imageHandler{
+				imageService: tt.fields.imageService,
+			}
+			app.Delete("/api/tasks/:id/image", h.DeleteTaskImage)
+
+			req := httptest.NewRequest("DELETE", "/api/tasks/1/image", nil)
+			req.Header.Set("If-Match", `"2"`)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/pkg/pagination"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProjectHandler interface {
+	CreateProject(c *fiber.Ctx) error
+	GetProjects(c *fiber.Ctx) error
+	GetProject(c *fiber.Ctx) error
+	UpdateProject(c *fiber.Ctx) error
+	DeleteProject(c *fiber.Ctx) error
+	GetProjectTasks(c *fiber.Ctx) error
+	CreateProjectTask(c *fiber.Ctx) error
+}
+
+type projectHandler struct {
+	projectService services.ProjectService
+	taskService    services.TaskService
+}
+
+func NewProjectHandler(projectService services.ProjectService, taskService services.TaskService) ProjectHandler {
+	return &projectHandler{
+		projectService: projectService,
+		taskService:    taskService,
+	}
+}
+
+func (h projectHandler) CreateProject(c *fiber.Ctx) error {
+	var req request.CreatedProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.projectService.CreateProject(middleware.WorkspaceID(c), req)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h projectHandler) GetProjects(c *fiber.Ctx) error {
+	projects, err := h.projectService.GetProjects(middleware.WorkspaceID(c), c.QueryBool("include_archived"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: projects})
+}
+
+func (h projectHandler) GetProject(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	project, err := h.projectService.GetProject(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: project})
+}
+
+func (h projectHandler) UpdateProject(c *fiber.Ctx) error {
+	var req request.UpdatedProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.projectService.UpdateProject(middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h projectHandler) DeleteProject(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.projectService.DeleteProject(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+// GetProjectTasks lists the project's tasks with the same filters as
+// GET /tasks. Tasks of an archived project are listed too.
+func (h projectHandler) GetProjectTasks(c *fiber.Ctx) error {
+	var query request.TaskListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	query.ProjectID = &id
+
+	err = query.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	query.WorkspaceID = middleware.WorkspaceID(c)
+	_, err = h.projectService.GetProject(query.WorkspaceID, id)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	tasks, page, err := h.taskService.GetTasks(query)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tasks, Pagination: &page})
+}
+
+func (h projectHandler) CreateProjectTask(c *fiber.Ctx) error {
+	var req request.CreatedTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	req.ProjectID = &id
+
+	err = req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	req.OwnerID = middleware.UserID(c)
+	req.WorkspaceID = middleware.WorkspaceID(c)
+	err = h.taskService.CreateTask(req)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrParentNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
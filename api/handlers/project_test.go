@@ -0,0 +1,633 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_projectHandler_CreateProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		projectServiceBehavior func(*mock.MockProjectService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().CreateProject(0, request.CreatedProjectRequest{Name: "Home", Color: "#ff0000"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"name":"Home","color":"#ff0000"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "name is required",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+				},
+			},
+			args: args{
+				body: `{"name":""}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "color is invalid",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+				},
+			},
+			args: args{
+				body: `{"name":"Home","color":"red"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "create project failed",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().CreateProject(0, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"Home"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+			}
+			app.Post("/api/projects", h.CreateProject)
+
+			req := httptest.NewRequest("POST", "/api/projects", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_GetProjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		projectServiceBehavior func(*mock.MockProjectService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProjects(0, false).Return([]entities.Project{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "success including archived",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProjects(0, true).Return([]entities.Project{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects?include_archived=true", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get projects failed",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProjects(0, false).Return(nil, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+			}
+			app.Get("/api/projects", h.GetProjects)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_GetProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		projectServiceBehavior func(*mock.MockProjectService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProject(0, 1).Return(entities.Project{ID: 1, Name: "Home"}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/foo", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProject(0, 1).Return(entities.Project{}, services.ErrProjectNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+			}
+			app.Get("/api/projects/:id", h.GetProject)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_UpdateProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		projectServiceBehavior func(*mock.MockProjectService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().UpdateProject(0, 1, request.UpdatedProjectRequest{Name: "Home", Archived: true}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"name":"Home","archived":true}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "name is required",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+				},
+			},
+			args: args{
+				body: `{"archived":true}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().UpdateProject(0, 1, gomock.Any()).Return(services.ErrProjectNotFound)
+				},
+			},
+			args: args{
+				body: `{"name":"Home"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "update project failed",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().UpdateProject(0, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"Home"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+			}
+			app.Put("/api/projects/:id", h.UpdateProject)
+
+			req := httptest.NewRequest("PUT", "/api/projects/1", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_DeleteProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		projectServiceBehavior func(*mock.MockProjectService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().DeleteProject(0, 1).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().DeleteProject(0, 1).Return(services.ErrProjectNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "delete project failed",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().DeleteProject(0, 1).Return(errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+			}
+			app.Delete("/api/projects/:id", h.DeleteProject)
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/projects/1", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_GetProjectTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectID := 1
+
+	type fields struct {
+		projectService         *mock.MockProjectService
+		taskService            *mock.MockTaskService
+		projectServiceBehavior func(*mock.MockProjectService)
+		taskServiceBehavior    func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProject(0, 1).Return(entities.Project{ID: 1, Archived: true}, nil)
+				},
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(request.TaskListQuery{ProjectID: &projectID, Title: "foo"}).Return([]entities.Task{}, response.Pagination{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1/tasks?title=foo", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "sort by is invalid",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+				},
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1/tasks?sort_by=foo", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProject(0, 1).Return(entities.Project{}, services.ErrProjectNotFound)
+				},
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1/tasks", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get tasks failed",
+			fields: fields{
+				projectService: mock.NewMockProjectService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				projectServiceBehavior: func(mps *mock.MockProjectService) {
+					mps.EXPECT().GetProject(0, 1).Return(entities.Project{ID: 1}, nil)
+				},
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(gomock.Any()).Return(nil, response.Pagination{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/projects/1/tasks", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.projectServiceBehavior(tt.fields.projectService)
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := projectHandler{
+				projectService: tt.fields.projectService,
+				taskService:    tt.fields.taskService,
+			}
+			app.Get("/api/projects/:id/tasks", h.GetProjectTasks)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_projectHandler_CreateProjectTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectID := 1
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CreateTask(request.CreatedTaskRequest{ProjectID: &projectID, Title: "foo", Status: "TODO"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"title":"foo","status":"TODO","project_id":2}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "status is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				body: `{"title":"foo","status":"foo"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CreateTask(gomock.Any()).Return(services.ErrProjectNotFound)
+				},
+			},
+			args: args{
+				body: `{"title":"foo","status":"TODO"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "create task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CreateTask(gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"title":"foo","status":"TODO"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := projectHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Post("/api/projects/:id/tasks", h.CreateProjectTask)
+
+			req := httptest.NewRequest("POST", "/api/projects/1/tasks", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sharePasswordHeader carries the password of a protected share link. It
+// is kept out of the URL so it doesn't end up in access logs.
+const sharePasswordHeader = "X-Share-Password"
+
+// sharedMemberName stands in for the email of members on shared tasks.
+const sharedMemberName = "Team member"
+
+type ShareHandler interface {
+	GetShareLinks(c *fiber.Ctx) error
+	CreateShareLink(c *fiber.Ctx) error
+	DeleteShareLink(c *fiber.Ctx) error
+	GetSharedTask(c *fiber.Ctx) error
+	CreateSharedComment(c *fiber.Ctx) error
+}
+
+type shareHandler struct {
+	shareService   services.ShareService
+	taskService    services.TaskService
+	commentService services.CommentService
+}
+
+func NewShareHandler(shareService services.ShareService, taskService services.TaskService, commentService services.CommentService) ShareHandler {
+	return &shareHandler{
+		shareService:   shareService,
+		taskService:    taskService,
+		commentService: commentService,
+	}
+}
+
+func (h shareHandler) GetShareLinks(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	links, err := h.shareService.GetShareLinks(taskID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: links})
+}
+
+// CreateShareLink answers with the link token, which can't be shown again.
+func (h shareHandler) CreateShareLink(c *fiber.Ctx) error {
+	var req request.CreatedShareLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	link, err := h.shareService.CreateShareLink(middleware.WorkspaceID(c), taskID, middleware.UserID(c), req)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: link})
+}
+
+func (h shareHandler) DeleteShareLink(c *fiber.Ctx) error {
+	taskID, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("shareId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.shareService.DeleteShareLink(taskID, id)
+	if err != nil {
+		if errors.Is(err, services.ErrShareLinkNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+// GetSharedTask shows the task of a share link, with its comments, to
+// anyone holding the token.
+func (h shareHandler) GetSharedTask(c *fiber.Ctx) error {
+	link, err := h.openShareLink(c)
+	if err != nil {
+		return err
+	}
+
+	task, err := h.taskService.GetTask(*link.WorkspaceID, link.TaskID)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	comments, err := h.commentService.GetComments(*link.WorkspaceID, link.TaskID)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: sharedTask(link, task, comments)})
+}
+
+func sharedTask(link entities.ShareLink, task entities.Task, comments []entities.Comment) response.SharedTask {
+	shared := response.SharedTask{
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      task.Status,
+		DueAt:       task.DueAt,
+		Comments:    make([]response.SharedComment, 0, len(comments)),
+		Access:      link.Access,
+		ExpiresAt:   link.ExpiresAt,
+	}
+	for _, comment := range comments {
+		author := comment.Author
+		if !comment.Guest {
+			author = sharedMemberName
+		}
+		shared.Comments = append(shared.Comments, response.SharedComment{
+			Author:    author,
+			Body:      comment.Body,
+			Guest:     comment.Guest,
+			Edited:    comment.Edited,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+	return shared
+}
+
+// CreateSharedComment comments on the task of a share link that grants
+// comment access.
+func (h shareHandler) CreateSharedComment(c *fiber.Ctx) error {
+	var req request.CreatedSharedCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	link, err := h.openShareLink(c)
+	if err != nil {
+		return err
+	}
+
+	if link.Access != enum.ShareAccessComment {
+		return fiber.NewError(fiber.StatusForbidden, "share link is read-only")
+	}
+
+	err = h.commentService.CreateGuestComment(*link.WorkspaceID, link.TaskID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h shareHandler) openShareLink(c *fiber.Ctx) (entities.ShareLink, error) {
+	link, err := h.shareService.OpenShareLink(c.Params("token"), c.Get(sharePasswordHeader))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidShareLink):
+			return entities.ShareLink{}, fiber.NewError(fiber.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrShareLinkExpired):
+			return entities.ShareLink{}, fiber.NewError(fiber.StatusGone, err.Error())
+		case errors.Is(err, services.ErrSharePassword):
+			return entities.ShareLink{}, fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		return entities.ShareLink{}, fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return link, nil
+}
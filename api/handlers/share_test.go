@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_shareHandler_GetShareLinks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		shareService         *mock.MockShareService
+		shareServiceBehavior func(*mock.MockShareService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().GetShareLinks(1).Return([]entities.ShareLink{{ID: 2, TaskID: 1}}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get failed",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().GetShareLinks(1).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.shareServiceBehavior(tt.fields.shareService)
+
+			app := fiber.New()
+			h := shareHandler{
+				shareService: tt.fields.shareService,
+			}
+			app.Get("/api/tasks/:id/shares", h.GetShareLinks)
+
+			req := httptest.NewRequest("GET", "/api/tasks/1/shares", nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_shareHandler_CreateShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		shareService         *mock.MockShareService
+		shareServiceBehavior func(*mock.MockShareService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().CreateShareLink(0, 1, 0, request.CreatedShareLinkRequest{Access: enum.ShareAccessComment, Password: "password"}).
+						Return(response.CreatedShareLink{ShareLink: entities.ShareLink{ID: 2, TaskID: 1}, Token: "token"}, nil)
+				},
+			},
+			args: args{
+				body: `{"access":"comment","password":"password"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "access is unknown",
+			fields: fields{
+				shareService:         mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {},
+			},
+			args: args{
+				body: `{"access":"write"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "expiry is in the past",
+			fields: fields{
+				shareService:         mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {},
+			},
+			args: args{
+				body: `{"expires_at":"2020-01-01T00:00:00Z"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "password is too short",
+			fields: fields{
+				shareService:         mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {},
+			},
+			args: args{
+				body: `{"password":"short"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().CreateShareLink(0, 1, 0, gomock.Any()).Return(response.CreatedShareLink{}, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().CreateShareLink(0, 1, 0, gomock.Any()).Return(response.CreatedShareLink{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.shareServiceBehavior(tt.fields.shareService)
+
+			app := fiber.New()
+			h := shareHandler{
+				shareService: tt.fields.shareService,
+			}
+			app.Post("/api/tasks/:id/share", h.CreateShareLink)
+
+			req := httptest.NewRequest("POST", "/api/tasks/1/share", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_shareHandler_DeleteShareLink(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		shareService         *mock.MockShareService
+		shareServiceBehavior func(*mock.MockShareService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().DeleteShareLink(1, 2).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "link not found",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().DeleteShareLink(1, 2).Return(services.ErrShareLinkNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				shareService: mock.NewMockShareService(ctrl),
+				shareServiceBehavior: func(mss *mock.MockShareService) {
+					mss.EXPECT().DeleteShareLink(1, 2).Return(errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.shareServiceBehavior(tt.fields.shareService)
+
+			app := fiber.New()
+			h := shareHandler{
+				shareService: tt.fields.shareService,
+			}
+			app.Delete("/api/tasks/:id/shares/:shareId", h.DeleteShareLink)
+
+			req := httptest.NewRequest("DELETE", "/api/tasks/1/shares/2", nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_shareHandler_GetSharedTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workspaceID, ownerID := 3, 7
+	link := entities.ShareLink{ID: 2, TaskID: 1, WorkspaceID: &workspaceID, Access: enum.ShareAccessRead}
+
+	type fields struct {
+		shareService     *mock.MockShareService
+		taskService      *mock.MockTaskService
+		commentService   *mock.MockCommentService
+		servicesBehavior func(*mock.MockShareService, *mock.MockTaskService, *mock.MockCommentService)
+	}
+	type args struct {
+		password string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+		want   *response.SharedTask
+	}{
+		{
+			name: "success",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "password").Return(link, nil)
+					mts.EXPECT().GetTask(3, 1).Return(entities.Task{ID: 1, OwnerID: &ownerID, WorkspaceID: &workspaceID, Title: "foo", Status: enum.TaskStatusTodo, ImageURL: "/api/tasks/1/image"}, nil)
+					mcs.EXPECT().GetComments(3, 1).Return([]entities.Comment{
+						{ID: 4, TaskID: 1, AuthorID: &ownerID, Author: "alice@example.com", Body: "bar"},
+						{ID: 5, TaskID: 1, Author: "Bob", Body: "baz", Guest: true},
+					}, nil)
+				},
+			},
+			args: args{
+				password: "password",
+			},
+			code: fiber.StatusOK,
+			want: &response.SharedTask{
+				Title:  "foo",
+				Status: enum.TaskStatusTodo,
+				Comments: []response.SharedComment{
+					{Author: sharedMemberName, Body: "bar"},
+					{Author: "Bob", Body: "baz", Guest: true},
+				},
+				Access: enum.ShareAccessRead,
+			},
+		},
+		{
+			name: "link is revoked",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{}, services.ErrInvalidShareLink)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "link has expired",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{}, services.ErrShareLinkExpired)
+				},
+			},
+			code: fiber.StatusGone,
+		},
+		{
+			name: "password is wrong",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "wrong").Return(entities.ShareLink{}, services.ErrSharePassword)
+				},
+			},
+			args: args{
+				password: "wrong",
+			},
+			code: fiber.StatusUnauthorized,
+		},
+		{
+			name: "task is trashed",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(link, nil)
+					mts.EXPECT().GetTask(3, 1).Return(entities.Task{}, services.ErrTaskNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get comments failed",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				taskService:    mock.NewMockTaskService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mts *mock.MockTaskService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(link, nil)
+					mts.EXPECT().GetTask(3, 1).Return(entities.Task{ID: 1}, nil)
+					mcs.EXPECT().GetComments(3, 1).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.servicesBehavior(tt.fields.shareService, tt.fields.taskService, tt.fields.commentService)
+
+			app := fiber.New()
+			h := shareHandler{
+				shareService:   tt.fields.shareService,
+				taskService:    tt.fields.taskService,
+				commentService: tt.fields.commentService,
+			}
+			app.Get("/api/shared/:token", h.GetSharedTask)
+
+			req := httptest.NewRequest("GET", "/api/shared/token", nil)
+			if len(tt.args.password) > 0 {
+				req.Header.Set(sharePasswordHeader, tt.args.password)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if tt.want != nil {
+				body, _ := io.ReadAll(resp.Body)
+				assert.NotContains(t, string(body), "example.com")
+				assert.NotContains(t, string(body), "_id")
+				assert.NotContains(t, string(body), "/api/")
+
+				var result struct {
+					Data response.SharedTask `json:"data"`
+				}
+				json.Unmarshal(body, &result)
+				assert.Equal(t, *tt.want, result.Data)
+			}
+		})
+	}
+}
+
+func Test_shareHandler_CreateSharedComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	workspaceID := 3
+
+	type fields struct {
+		shareService     *mock.MockShareService
+		commentService   *mock.MockCommentService
+		servicesBehavior func(*mock.MockShareService, *mock.MockCommentService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{ID: 2, TaskID: 1, WorkspaceID: &workspaceID, Access: enum.ShareAccessComment}, nil)
+					mcs.EXPECT().CreateGuestComment(3, 1, request.CreatedSharedCommentRequest{Author: "guest", Body: "looks good"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"author":"guest","body":"looks good"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "link is read-only",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{ID: 2, TaskID: 1, WorkspaceID: &workspaceID, Access: enum.ShareAccessRead}, nil)
+				},
+			},
+			args: args{
+				body: `{"author":"guest","body":"looks good"}`,
+			},
+			code: fiber.StatusForbidden,
+		},
+		{
+			name: "author is missing",
+			fields: fields{
+				shareService:     mock.NewMockShareService(ctrl),
+				commentService:   mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mcs *mock.MockCommentService) {},
+			},
+			args: args{
+				body: `{"body":"looks good"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "link is revoked",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{}, services.ErrInvalidShareLink)
+				},
+			},
+			args: args{
+				body: `{"author":"guest","body":"looks good"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				shareService:   mock.NewMockShareService(ctrl),
+				commentService: mock.NewMockCommentService(ctrl),
+				servicesBehavior: func(mss *mock.MockShareService, mcs *mock.MockCommentService) {
+					mss.EXPECT().OpenShareLink("token", "").Return(entities.ShareLink{ID: 2, TaskID: 1, WorkspaceID: &workspaceID, Access: enum.ShareAccessComment}, nil)
+					mcs.EXPECT().CreateGuestComment(3, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"author":"guest","body":"looks good"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.servicesBehavior(tt.fields.shareService, tt.fields.commentService)
+
+			app := fiber.New()
+			h := shareHandler{
+				shareService:   tt.fields.shareService,
+				commentService: tt.fields.commentService,
+			}
+			app.Post("/api/shared/:token/comments", h.CreateSharedComment)
+
+			req := httptest.NewRequest("POST", "/api/shared/token/comments", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TagHandler interface {
+	CreateTag(c *fiber.Ctx) error
+	GetTags(c *fiber.Ctx) error
+	GetTag(c *fiber.Ctx) error
+	UpdateTag(c *fiber.Ctx) error
+	DeleteTag(c *fiber.Ctx) error
+	MergeTag(c *fiber.Ctx) error
+}
+
+type tagHandler struct {
+	tagService services.TagService
+}
+
+func NewTagHandler(tagService services.TagService) TagHandler {
+	return &tagHandler{
+		tagService: tagService,
+	}
+}
+
+func (h tagHandler) CreateTag(c *fiber.Ctx) error {
+	var req request.CreatedTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.tagService.CreateTag(middleware.WorkspaceID(c), req)
+	if err != nil {
+		if errors.Is(err, services.ErrTagExists) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h tagHandler) GetTags(c *fiber.Ctx) error {
+	tags, err := h.tagService.GetTags(middleware.WorkspaceID(c))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tags})
+}
+
+func (h tagHandler) GetTag(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tag, err := h.tagService.GetTag(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tag})
+}
+
+func (h tagHandler) UpdateTag(c *fiber.Ctx) error {
+	var req request.UpdatedTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.tagService.UpdateTag(middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTagExists) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h tagHandler) DeleteTag(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.tagService.DeleteTag(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h tagHandler) MergeTag(c *fiber.Ctx) error {
+	var req request.MergedTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.tagService.MergeTag(middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrTagMergeSelf) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrTagNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
@@ -0,0 +1,512 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tagHandler_CreateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().CreateTag(0, request.CreatedTagRequest{Name: "bug"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"name":"bug"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "name is required",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+				},
+			},
+			args: args{
+				body: `{"name":" "}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "tag exists",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().CreateTag(0, gomock.Any()).Return(services.ErrTagExists)
+				},
+			},
+			args: args{
+				body: `{"name":"bug"}`,
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "create tag failed",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().CreateTag(0, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"bug"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Post("/api/tags", h.CreateTag)
+
+			req := httptest.NewRequest("POST", "/api/tags", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_tagHandler_GetTags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().GetTags(0).Return([]entities.Tag{}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get tags failed",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().GetTags(0).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Get("/api/tags", h.GetTags)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/tags", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_tagHandler_GetTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().GetTag(0, 1).Return(entities.Tag{ID: 1, Name: "bug"}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tags/1", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tags/foo", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "tag not found",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().GetTag(0, 1).Return(entities.Tag{}, services.ErrTagNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tags/1", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Get("/api/tags/:id", h.GetTag)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_tagHandler_UpdateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().UpdateTag(0, 1, request.UpdatedTagRequest{Name: "defect"}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"name":"defect"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "tag not found",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().UpdateTag(0, 1, gomock.Any()).Return(services.ErrTagNotFound)
+				},
+			},
+			args: args{
+				body: `{"name":"defect"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "tag exists",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().UpdateTag(0, 1, gomock.Any()).Return(services.ErrTagExists)
+				},
+			},
+			args: args{
+				body: `{"name":"defect"}`,
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "update tag failed",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().UpdateTag(0, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"defect"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Put("/api/tags/:id", h.UpdateTag)
+
+			req := httptest.NewRequest("PUT", "/api/tags/1", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_tagHandler_DeleteTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().DeleteTag(0, 1).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "tag not found",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().DeleteTag(0, 1).Return(services.ErrTagNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "delete tag failed",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().DeleteTag(0, 1).Return(errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Delete("/api/tags/:id", h.DeleteTag)
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/tags/1", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_tagHandler_MergeTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		tagService         *mock.MockTagService
+		tagServiceBehavior func(*mock.MockTagService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().MergeTag(0, 1, request.MergedTagRequest{IntoID: 2}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"into_id":2}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "into id is invalid",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+				},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "merge into itself",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().MergeTag(0, 1, gomock.Any()).Return(services.ErrTagMergeSelf)
+				},
+			},
+			args: args{
+				body: `{"into_id":1}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "tag not found",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().MergeTag(0, 1, gomock.Any()).Return(services.ErrTagNotFound)
+				},
+			},
+			args: args{
+				body: `{"into_id":2}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "merge tag failed",
+			fields: fields{
+				tagService: mock.NewMockTagService(ctrl),
+				tagServiceBehavior: func(mts *mock.MockTagService) {
+					mts.EXPECT().MergeTag(0, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"into_id":2}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.tagServiceBehavior(tt.fields.tagService)
+
+			app := fiber.New()
+			h := tagHandler{
+				tagService: tt.fields.tagService,
+			}
+			app.Post("/api/tags/:id/merge", h.MergeTag)
+
+			req := httptest.NewRequest("POST", "/api/tags/1/merge", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
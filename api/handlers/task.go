@@ -1,9 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"strconv"
+	"strings"
+	"todo/api/enum"
+	"todo/api/middleware"
 	"todo/api/models/request"
 	"todo/api/models/response"
 	"todo/api/services"
+	"todo/pkg/pagination"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -11,7 +17,21 @@ import (
 type TaskHandler interface {
 	CreateTask(c *fiber.Ctx) error
 	GetTasks(c *fiber.Ctx) error
+	StreamTasks(c *fiber.Ctx) error
+	GetTask(c *fiber.Ctx) error
+	GetChildren(c *fiber.Ctx) error
+	GetTaskTree(c *fiber.Ctx) error
+	MoveTask(c *fiber.Ctx) error
+	GetDependencies(c *fiber.Ctx) error
+	AddDependency(c *fiber.Ctx) error
+	RemoveDependency(c *fiber.Ctx) error
+	AssignProject(c *fiber.Ctx) error
 	UpdateTask(c *fiber.Ctx) error
+	PatchTask(c *fiber.Ctx) error
+	DeleteTask(c *fiber.Ctx) error
+	GetTrashedTasks(c *fiber.Ctx) error
+	RestoreTask(c *fiber.Ctx) error
+	PurgeTask(c *fiber.Ctx) error
 }
 
 type taskHandler struct {
@@ -35,8 +55,13 @@ func (h taskHandler) CreateTask(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
+	req.OwnerID = middleware.UserID(c)
+	req.WorkspaceID = middleware.WorkspaceID(c)
 	err = h.taskService.CreateTask(req)
 	if err != nil {
+		if errors.Is(err, services.ErrParentNotFound) || errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError)
 	}
 
@@ -56,14 +81,229 @@ func (h taskHandler) GetTasks(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	tasks, err := h.taskService.GetTasks(query)
+	query.WorkspaceID = middleware.WorkspaceID(c)
+	tasks, page, err := h.taskService.GetTasks(query)
 	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tasks, Pagination: &page})
+}
+
+func (h taskHandler) GetTask(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	task, err := h.taskService.GetTask(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	c.Set(fiber.HeaderETag, etag(task.Version))
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: task})
+}
+
+func (h taskHandler) GetChildren(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	tasks, err := h.taskService.GetChildren(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tasks})
 }
 
+func (h taskHandler) GetTaskTree(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	task, err := h.taskService.GetTaskTree(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: task})
+}
+
+func (h taskHandler) MoveTask(c *fiber.Ctx) error {
+	var req request.MovedTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.taskService.MoveTask(middleware.WorkspaceID(c), id, version, req)
+	if err != nil {
+		if errors.Is(err, services.ErrParentNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskCycle) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskVersionMismatch) {
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) GetDependencies(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	dependencies, err := h.taskService.GetDependencies(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: dependencies})
+}
+
+func (h taskHandler) AddDependency(c *fiber.Ctx) error {
+	var req request.CreatedDependencyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.taskService.AddDependency(middleware.WorkspaceID(c), id, req)
+	if err != nil {
+		if errors.Is(err, services.ErrBlockerNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrDependencyCycle) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) RemoveDependency(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	blockerID, err := c.ParamsInt("blockerId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.taskService.RemoveDependency(middleware.WorkspaceID(c), id, blockerID)
+	if err != nil {
+		if errors.Is(err, services.ErrDependencyNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) AssignProject(c *fiber.Ctx) error {
+	var req request.AssignedProjectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.taskService.AssignProject(middleware.WorkspaceID(c), id, version, req)
+	if err != nil {
+		if errors.Is(err, services.ErrProjectNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskVersionMismatch) {
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
 func (h taskHandler) UpdateTask(c *fiber.Ctx) error {
 	var req request.UpdatedTaskRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -80,8 +320,152 @@ func (h taskHandler) UpdateTask(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	err = h.taskService.UpdateTask(id, req)
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	req.Cascade = c.QueryBool("cascade")
+
+	err = h.taskService.UpdateTask(middleware.WorkspaceID(c), id, version, req)
+	if err != nil {
+		var transitionErr *services.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			return transitionError(c, transitionErr)
+		}
+		if errors.Is(err, services.ErrTaskBlocked) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskVersionMismatch) {
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) PatchTask(c *fiber.Ctx) error {
+	var req request.PatchedTaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+	req.Cascade = c.QueryBool("cascade")
+
+	err = h.taskService.PatchTask(middleware.WorkspaceID(c), id, version, req)
+	if err != nil {
+		var transitionErr *services.InvalidTransitionError
+		if errors.As(err, &transitionErr) {
+			return transitionError(c, transitionErr)
+		}
+		if errors.Is(err, services.ErrTaskBlocked) {
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		if errors.Is(err, services.ErrInvalidSchedule) {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskVersionMismatch) {
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) DeleteTask(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	err = h.taskService.DeleteTask(middleware.WorkspaceID(c), id, version)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		if errors.Is(err, services.ErrTaskVersionMismatch) {
+			return fiber.NewError(fiber.StatusPreconditionFailed, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) GetTrashedTasks(c *fiber.Ctx) error {
+	tasks, err := h.taskService.GetTrashedTasks(middleware.WorkspaceID(c))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: tasks})
+}
+
+func (h taskHandler) RestoreTask(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.taskService.RestoreTask(middleware.WorkspaceID(c), id)
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h taskHandler) PurgeTask(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.taskService.PurgeTask(middleware.WorkspaceID(c), id)
 	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
 		return fiber.NewError(fiber.StatusInternalServerError)
 	}
 
@@ -89,3 +473,39 @@ func (h taskHandler) UpdateTask(c *fiber.Ctx) error {
 		Status: fiber.StatusOK,
 	})
 }
+
+func transitionError(c *fiber.Ctx, err *services.InvalidTransitionError) error {
+	allowed := err.Allowed
+	if allowed == nil {
+		allowed = []enum.TaskStatus{}
+	}
+
+	return c.Status(fiber.StatusConflict).JSON(response.TransitionError{
+		Code:            fiber.StatusConflict,
+		Message:         err.Error(),
+		AllowedStatuses: allowed,
+	})
+}
+
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersion reads the task version out of the If-Match header. "*"
+// matches any version and is returned as 0.
+func ifMatchVersion(c *fiber.Ctx) (int, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if len(header) == 0 {
+		return 0, fiber.NewError(fiber.StatusPreconditionRequired, "If-Match header is required")
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+	if err != nil || version <= 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "If-Match header is invalid")
+	}
+
+	return version, nil
+}
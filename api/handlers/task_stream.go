@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+// lastEventIDHeader is sent by EventSource when it reconnects. WebSocket
+// clients, which cannot set headers, pass the last_event_id parameter.
+const lastEventIDHeader = "Last-Event-ID"
+
+// streamHeartbeat keeps idle streams from being cut by proxies and finds
+// clients that are gone.
+const streamHeartbeat = 15 * time.Second
+
+// streamRetry is how long EventSource waits before reconnecting.
+const streamRetry = 3 * time.Second
+
+// StreamTasks pushes task changes as Server-Sent Events, or over a
+// WebSocket when the request asks for an upgrade. It takes the filters of
+// GetTasks.
+func (h taskHandler) StreamTasks(c *fiber.Ctx) error {
+	var query request.TaskListQuery
+	if err := c.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := query.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	after, err := lastEventID(c)
+	if err != nil {
+		return err
+	}
+
+	query.WorkspaceID = middleware.WorkspaceID(c)
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := h.taskService.WatchTasks(ctx, query, after)
+	if err != nil {
+		cancel()
+		if errors.Is(err, services.ErrTaskStreamUnavailable) {
+			return fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	if websocket.IsWebSocketUpgrade(c) {
+		err = websocket.New(func(conn *websocket.Conn) {
+			defer cancel()
+			writeSocketEvents(conn, events, cancel)
+		})(c)
+		if err != nil {
+			cancel()
+		}
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// keeps nginx from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		writeStreamEvents(w, events)
+	})
+
+	return nil
+}
+
+func lastEventID(c *fiber.Ctx) (int64, error) {
+	value := c.Get(lastEventIDHeader)
+	if len(value) == 0 {
+		value = c.Query("last_event_id")
+	}
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "last event id is invalid")
+	}
+
+	return id, nil
+}
+
+// writeStreamEvents writes events until they end or the client is gone.
+func writeStreamEvents(w *bufio.Writer, events <-chan response.TaskEvent) {
+	ticker := time.NewTicker(streamHeartbeat)
+	defer ticker.Stop()
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	for {
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			if event.ID > 0 {
+				fmt.Fprintf(w, "id: %d\n", event.ID)
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		case <-ticker.C:
+			w.WriteString(": heartbeat\n\n")
+		}
+	}
+}
+
+// writeSocketEvents sends events as JSON messages until they end or the
+// client is gone. Messages from the client are read only to notice that.
+func writeSocketEvents(conn *websocket.Conn, events <-chan response.TaskEvent, cancel context.CancelFunc) {
+	read := make(chan struct{})
+	go func() {
+		defer close(read)
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// conn is reused once the handler returns, so the reads have to stop
+	// first; the deadline leaves the client time to answer a close
+	defer func() {
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		<-read
+	}()
+
+	ticker := time.NewTicker(streamHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// the client resumes from the last event it received
+				message := websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "stream ended")
+				_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamHeartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func taskEvents(events ...response.TaskEvent) <-chan response.TaskEvent {
+	ch := make(chan response.TaskEvent, len(events))
+	for _, event := range events {
+		ch <- event
+	}
+	close(ch)
+	return ch
+}
+
+func taskJSON(t *testing.T, task entities.Task) string {
+	t.Helper()
+
+	b, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func Test_taskHandler_StreamTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+		body   string
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().WatchTasks(gomock.Any(), request.TaskListQuery{Priorities: []enum.TaskPriority{enum.TaskPriorityHigh, enum.TaskPriorityUrgent}}, int64(0)).
+						Return(taskEvents(
+							response.TaskEvent{ID: 4, Type: enum.TaskEventCreated, TaskID: 1, Task: &entities.Task{ID: 1, Title: "foo"}},
+							response.TaskEvent{ID: 5, Type: enum.TaskEventDeleted, TaskID: 2},
+						), nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/stream?priority=HIGH,URGENT", nil),
+			},
+			code: fiber.StatusOK,
+			body: "retry: 3000\n\n" +
+				"id: 4\nevent: created\ndata: {\"id\":4,\"type\":\"created\",\"task_id\":1,\"task\":" + taskJSON(t, entities.Task{ID: 1, Title: "foo"}) + "}\n\n" +
+				"id: 5\nevent: deleted\ndata: {\"id\":5,\"type\":\"deleted\",\"task_id\":2}\n\n",
+		},
+		{
+			name: "resume with Last-Event-ID",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().WatchTasks(gomock.Any(), gomock.Any(), int64(41)).
+						Return(taskEvents(response.TaskEvent{Type: enum.TaskEventReset}), nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("GET", "/api/tasks/stream?last_event_id=7", nil)
+					req.Header.Set(lastEventIDHeader, "41")
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+			body: "retry: 3000\n\nevent: reset\ndata: {\"type\":\"reset\"}\n\n",
+		},
+		{
+			name: "resume with last_event_id",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().WatchTasks(gomock.Any(), gomock.Any(), int64(7)).Return(taskEvents(), nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/stream?last_event_id=7", nil),
+			},
+			code: fiber.StatusOK,
+			body: "retry: 3000\n\n",
+		},
+		{
+			name: "last event id is invalid",
+			fields: fields{
+				taskService:         mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("GET", "/api/tasks/stream", nil)
+					req.Header.Set(lastEventIDHeader, "foo")
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "filter is invalid",
+			fields: fields{
+				taskService:         mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/stream?due_before=foo", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "stream unavailable",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().WatchTasks(gomock.Any(), gomock.Any(), int64(0)).Return(nil, services.ErrTaskStreamUnavailable)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/stream", nil),
+			},
+			code: fiber.StatusServiceUnavailable,
+		},
+		{
+			name: "watch tasks failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().WatchTasks(gomock.Any(), gomock.Any(), int64(0)).Return(nil, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/stream", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New(fiber.Config{EnableSplittingOnParsers: true})
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/stream", h.StreamTasks)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if tt.code == fiber.StatusOK {
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, "text/event-stream", resp.Header.Get(fiber.HeaderContentType))
+				assert.Equal(t, tt.body, string(body))
+			}
+		})
+	}
+}
+
+func Test_taskHandler_StreamTasks_cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the stream is stopped once the response is written
+	done := make(chan struct{})
+	taskService := mock.NewMockTaskService(ctrl)
+	taskService.EXPECT().WatchTasks(gomock.Any(), gomock.Any(), int64(0)).
+		DoAndReturn(func(ctx context.Context, query request.TaskListQuery, after int64) (<-chan response.TaskEvent, error) {
+			go func() {
+				<-ctx.Done()
+				close(done)
+			}()
+			return taskEvents(), nil
+		})
+
+	app := fiber.New()
+	h := taskHandler{taskService: taskService}
+	app.Get("/api/tasks/stream", h.StreamTasks)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks/stream", nil))
+	if err != nil {
+		t.Fatalf("Error while performing the request: %v", err)
+	}
+	io.ReadAll(resp.Body)
+
+	<-done
+}
+
+func Test_taskHandler_StreamTasks_websocket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	taskService := mock.NewMockTaskService(ctrl)
+	taskService.EXPECT().WatchTasks(gomock.Any(), request.TaskListQuery{Title: "foo"}, int64(7)).
+		Return(taskEvents(response.TaskEvent{ID: 8, Type: enum.TaskEventDeleted, TaskID: 2}), nil)
+
+	app := fiber.New()
+	h := taskHandler{taskService: taskService}
+	app.Get("/api/tasks/stream", h.StreamTasks)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+
+	conn, _, err := fastws.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/api/tasks/stream?title=foo&last_event_id=7", nil)
+	if err != nil {
+		t.Fatalf("Error while dialing: %v", err)
+	}
+	defer conn.Close()
+
+	var event response.TaskEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("Error while reading an event: %v", err)
+	}
+	assert.Equal(t, response.TaskEvent{ID: 8, Type: enum.TaskEventDeleted, TaskID: 2}, event)
+
+	// the client is asked to reconnect once the stream ends
+	_, _, err = conn.ReadMessage()
+	assert.True(t, fastws.IsCloseError(err, fastws.CloseTryAgainLater), "unexpected error %v", err)
+}
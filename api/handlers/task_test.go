@@ -7,11 +7,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"todo/api/entities"
 	"todo/api/enum"
 	"todo/api/models/request"
 	"todo/api/models/response"
+	"todo/api/services"
 	"todo/api/services/mock"
+	"todo/pkg/pagination"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang/mock/gomock"
@@ -48,7 +51,6 @@ func Test_taskHandler_CreateTask(t *testing.T) {
 					b, _ := json.Marshal(request.CreatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
@@ -83,7 +85,6 @@ func Test_taskHandler_CreateTask(t *testing.T) {
 					b, _ := json.Marshal(request.CreatedTaskRequest{
 						Title:       "fooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
@@ -104,7 +105,6 @@ func Test_taskHandler_CreateTask(t *testing.T) {
 					b, _ := json.Marshal(request.CreatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      "foo",
 					})
 
@@ -113,6 +113,49 @@ func Test_taskHandler_CreateTask(t *testing.T) {
 			},
 			code: fiber.StatusBadRequest,
 		},
+		{
+			name: "start at after due at",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					startAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+					dueAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+					b, _ := json.Marshal(request.CreatedTaskRequest{
+						Title:   "foo",
+						Status:  enum.TaskStatusCompleted,
+						StartAt: &startAt,
+						DueAt:   &dueAt,
+					})
+
+					return httptest.NewRequest("POST", "/api/tasks", bytes.NewReader(b))
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "priority is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.CreatedTaskRequest{
+						Title:    "foo",
+						Status:   enum.TaskStatusCompleted,
+						Priority: "foo",
+					})
+
+					return httptest.NewRequest("POST", "/api/tasks", bytes.NewReader(b))
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
 		{
 			name: "create task failed",
 			fields: fields{
@@ -126,7 +169,6 @@ func Test_taskHandler_CreateTask(t *testing.T) {
 					b, _ := json.Marshal(request.CreatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
@@ -181,7 +223,7 @@ func Test_taskHandler_GetTasks(t *testing.T) {
 			fields: fields{
 				taskService: mock.NewMockTaskService(ctrl),
 				taskServiceBehavior: func(mts *mock.MockTaskService) {
-					mts.EXPECT().GetTasks(gomock.Any()).Return([]entities.Task{}, nil)
+					mts.EXPECT().GetTasks(gomock.Any()).Return([]entities.Task{}, response.Pagination{}, nil)
 				},
 			},
 			args: args{
@@ -205,12 +247,149 @@ func Test_taskHandler_GetTasks(t *testing.T) {
 			},
 			code: fiber.StatusBadRequest,
 		},
+		{
+			name: "page size exceeded",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?page_size=101", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "cursor is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?cursor=foo", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "cursor of another sort",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					cursor := pagination.Encode(pagination.Cursor{Value: "foo", ID: 1, SortBy: "title", SortOrder: "asc"})
+					return httptest.NewRequest("GET", "/api/tasks?sort_by=created_at&cursor="+cursor, nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "cursor of the same sort",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(gomock.Any()).Return([]entities.Task{}, response.Pagination{}, nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					cursor := pagination.Encode(pagination.Cursor{Value: "foo", ID: 1, SortBy: "title", SortOrder: "asc"})
+					return httptest.NewRequest("GET", "/api/tasks?sort_by=title&cursor="+cursor, nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "cursor value is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(gomock.Any()).Return(nil, response.Pagination{}, pagination.ErrInvalidCursor)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					cursor := pagination.Encode(pagination.Cursor{Value: "foo", ID: 1, SortBy: "priority", SortOrder: "desc"})
+					return httptest.NewRequest("GET", "/api/tasks?sort_by=priority&sort_order=desc&cursor="+cursor, nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "due before is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?due_before=tomorrow", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "success with priorities",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(request.TaskListQuery{
+						Priorities: []enum.TaskPriority{enum.TaskPriorityHigh, enum.TaskPriorityUrgent},
+					}).Return([]entities.Task{}, response.Pagination{}, nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?priority=HIGH,URGENT", nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "success with blocked filter",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTasks(request.TaskListQuery{
+						Blocked: func() *bool { b := true; return &b }(),
+					}).Return([]entities.Task{}, response.Pagination{}, nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?blocked=true", nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "priority is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks?priority=HIGH&priority=foo", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
 		{
 			name: "get tasks failed",
 			fields: fields{
 				taskService: mock.NewMockTaskService(ctrl),
 				taskServiceBehavior: func(mts *mock.MockTaskService) {
-					mts.EXPECT().GetTasks(gomock.Any()).Return([]entities.Task{}, errors.New("foo"))
+					mts.EXPECT().GetTasks(gomock.Any()).Return([]entities.Task{}, response.Pagination{}, errors.New("foo"))
 				},
 			},
 			args: args{
@@ -225,7 +404,7 @@ func Test_taskHandler_GetTasks(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.fields.taskServiceBehavior(tt.fields.taskService)
 
-			app := fiber.New()
+			app := fiber.New(fiber.Config{EnableSplittingOnParsers: true})
 			h := taskHandler{
 				taskService: tt.fields.taskService,
 			}
@@ -244,6 +423,108 @@ func Test_taskHandler_GetTasks(t *testing.T) {
 	}
 }
 
+func Test_taskHandler_GetTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+		etag   string
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTask(0, 1).Return(entities.Task{ID: 1, Version: 3}, nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks/1", nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+			etag: `"3"`,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks/foo", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTask(0, 1).Return(entities.Task{}, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks/1", nil)
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTask(0, 1).Return(entities.Task{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("GET", "/api/tasks/1", nil)
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/:id", h.GetTask)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, tt.etag, resp.Header.Get(fiber.HeaderETag))
+		})
+	}
+}
+
 func Test_taskHandler_UpdateTask(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -266,7 +547,7 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 			fields: fields{
 				taskService: mock.NewMockTaskService(ctrl),
 				taskServiceBehavior: func(mts *mock.MockTaskService) {
-					mts.EXPECT().UpdateTask(gomock.Any(), gomock.Any()).Return(nil)
+					mts.EXPECT().UpdateTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
 				},
 			},
 			args: args{
@@ -274,11 +555,12 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 					b, _ := json.Marshal(request.UpdatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
-					return httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
 			code: fiber.StatusOK,
@@ -292,7 +574,9 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 			},
 			args: args{
 				req: func() *http.Request {
-					return httptest.NewRequest("PUT", "/api/tasks/1", nil)
+					req := httptest.NewRequest("PUT", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
 			code: fiber.StatusBadRequest,
@@ -309,11 +593,12 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 					b, _ := json.Marshal(request.UpdatedTaskRequest{
 						Title:       "fooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooooo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
-					return httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
 			code: fiber.StatusBadRequest,
@@ -330,11 +615,12 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 					b, _ := json.Marshal(request.UpdatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      "foo",
 					})
 
-					return httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
 			code: fiber.StatusBadRequest,
@@ -351,21 +637,22 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 					b, _ := json.Marshal(request.UpdatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
-					return httptest.NewRequest("PUT", "/api/tasks/foo", bytes.NewReader(b))
+					req := httptest.NewRequest("PUT", "/api/tasks/foo", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
 			code: fiber.StatusBadRequest,
 		},
 		{
-			name: "update task failed",
+			name: "task not found",
 			fields: fields{
 				taskService: mock.NewMockTaskService(ctrl),
 				taskServiceBehavior: func(mts *mock.MockTaskService) {
-					mts.EXPECT().UpdateTask(gomock.Any(), gomock.Any()).Return(errors.New("foo"))
+					mts.EXPECT().UpdateTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(services.ErrTaskNotFound)
 				},
 			},
 			args: args{
@@ -373,25 +660,1542 @@ func Test_taskHandler_UpdateTask(t *testing.T) {
 					b, _ := json.Marshal(request.UpdatedTaskRequest{
 						Title:       "foo",
 						Description: "foo",
-						Image:       "foo",
 						Status:      enum.TaskStatusCompleted,
 					})
 
-					return httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
 				}(),
 			},
-			code: fiber.StatusInternalServerError,
+			code: fiber.StatusNotFound,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tt.fields.taskServiceBehavior(tt.fields.taskService)
+		{
+			name: "if match is missing",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:       "foo",
+						Description: "foo",
+						Status:      enum.TaskStatusCompleted,
+					})
 
-			app := fiber.New()
-			h := taskHandler{
-				taskService: tt.fields.taskService,
-			}
-			app.Put("/api/tasks/:id", h.UpdateTask)
+					return httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+				}(),
+			},
+			code: fiber.StatusPreconditionRequired,
+		},
+		{
+			name: "if match is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:       "foo",
+						Description: "foo",
+						Status:      enum.TaskStatusCompleted,
+					})
+
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"foo"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().UpdateTask(0, 1, 1, gomock.Any()).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:       "foo",
+						Description: "foo",
+						Status:      enum.TaskStatusCompleted,
+					})
+
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+		{
+			name: "task is blocked",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().UpdateTask(0, 1, 1, gomock.Any()).Return(services.ErrTaskBlocked)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:  "foo",
+						Status: enum.TaskStatusCompleted,
+					})
+
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "invalid transition",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().UpdateTask(0, 1, 1, gomock.Any()).Return(&services.InvalidTransitionError{
+						From:    enum.TaskStatusCancelled,
+						To:      enum.TaskStatusCompleted,
+						Allowed: []enum.TaskStatus{enum.TaskStatusTodo},
+					})
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:  "foo",
+						Status: enum.TaskStatusCompleted,
+					})
+
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "update task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().UpdateTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					b, _ := json.Marshal(request.UpdatedTaskRequest{
+						Title:       "foo",
+						Description: "foo",
+						Status:      enum.TaskStatusCompleted,
+					})
+
+					req := httptest.NewRequest("PUT", "/api/tasks/1", bytes.NewReader(b))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Put("/api/tasks/:id", h.UpdateTask)
+
+			tt.args.req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_DeleteTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().DeleteTask(0, 1, 1).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/foo", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().DeleteTask(0, 1, 1).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "if match is missing",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+				}(),
+			},
+			code: fiber.StatusPreconditionRequired,
+		},
+		{
+			name: "if match is any",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().DeleteTask(0, 1, 0).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", "*")
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().DeleteTask(0, 1, 1).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+		{
+			name: "delete task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().DeleteTask(0, 1, 1).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("DELETE", "/api/tasks/1", nil)
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Delete("/api/tasks/:id", h.DeleteTask)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_GetTrashedTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTrashedTasks(0).Return([]entities.Task{}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get trashed tasks failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTrashedTasks(0).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/trash", h.GetTrashedTasks)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks/trash", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_RestoreTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RestoreTask(0, 1).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("POST", "/api/tasks/1/restore", nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("POST", "/api/tasks/foo/restore", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not in trash",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RestoreTask(0, 1).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("POST", "/api/tasks/1/restore", nil)
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "restore task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RestoreTask(0, 1).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("POST", "/api/tasks/1/restore", nil)
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Post("/api/tasks/:id/restore", h.RestoreTask)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_PurgeTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PurgeTask(0, 1).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("DELETE", "/api/tasks/1/purge", nil)
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("DELETE", "/api/tasks/foo/purge", nil)
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not in trash",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PurgeTask(0, 1).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("DELETE", "/api/tasks/1/purge", nil)
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "purge task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PurgeTask(0, 1).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					return httptest.NewRequest("DELETE", "/api/tasks/1/purge", nil)
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Delete("/api/tasks/:id/purge", h.PurgeTask)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_PatchTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, 1, 1, request.PatchedTaskRequest{
+						Description: request.Optional[string]{Set: true, Null: true},
+					}).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"description":null}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "success with cascade",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, 1, 1, request.PatchedTaskRequest{
+						Status:  request.Optional[enum.TaskStatus]{Set: true, Value: enum.TaskStatusCompleted},
+						Cascade: true,
+					}).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1?cascade=true", bytes.NewReader([]byte(`{"status":"COMPLETED"}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "body parser failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"title":1}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "title is null",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"title":null}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "status is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"status":"foo"}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/foo", bytes.NewReader([]byte(`{}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"title":"foo"}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, 1, 2, gomock.Any()).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"title":"foo"}`)))
+					req.Header.Set("If-Match", `W/"2"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+		{
+			name: "schedule violated",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(services.ErrInvalidSchedule)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"due_at":"2024-01-01T00:00:00Z"}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "patch task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().PatchTask(0, gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader([]byte(`{"title":"foo"}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Patch("/api/tasks/:id", h.PatchTask)
+
+			tt.args.req.Header.Set("Content-Type", "application/merge-patch+json")
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_GetChildren(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetChildren(0, 1).Return([]entities.Task{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/children", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/foo/children", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetChildren(0, 1).Return(nil, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/children", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get children failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetChildren(0, 1).Return(nil, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/children", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/:id/children", h.GetChildren)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_GetTaskTree(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTaskTree(0, 1).Return(entities.Task{ID: 1}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/tree", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTaskTree(0, 1).Return(entities.Task{}, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/tree", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get task tree failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetTaskTree(0, 1).Return(entities.Task{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/tree", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/:id/tree", h.GetTaskTree)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_MoveTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parentID := 2
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, request.MovedTaskRequest{ParentID: &parentID}).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "parent id is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":0}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "if match is missing",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`))),
+			},
+			code: fiber.StatusPreconditionRequired,
+		},
+		{
+			name: "parent not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, gomock.Any()).Return(services.ErrParentNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "cycle",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, gomock.Any()).Return(services.ErrTaskCycle)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":null}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, gomock.Any()).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+		{
+			name: "move task failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().MoveTask(0, 1, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/parent", bytes.NewReader([]byte(`{"parent_id":2}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Put("/api/tasks/:id/parent", h.MoveTask)
+
+			tt.args.req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_GetDependencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetDependencies(0, 1).Return(response.TaskDependencies{}, nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/dependencies", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetDependencies(0, 1).Return(response.TaskDependencies{}, services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/dependencies", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "get dependencies failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().GetDependencies(0, 1).Return(response.TaskDependencies{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("GET", "/api/tasks/1/dependencies", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Get("/api/tasks/:id/dependencies", h.GetDependencies)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_AddDependency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AddDependency(0, 1, request.CreatedDependencyRequest{BlockedByID: 2}).Return(nil)
+				},
+			},
+			args: args{
+				body: `{"blocked_by_id":2}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "blocked by id is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "blocker not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AddDependency(0, 1, gomock.Any()).Return(services.ErrBlockerNotFound)
+				},
+			},
+			args: args{
+				body: `{"blocked_by_id":2}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "cycle",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AddDependency(0, 1, gomock.Any()).Return(services.ErrDependencyCycle)
+				},
+			},
+			args: args{
+				body: `{"blocked_by_id":2}`,
+			},
+			code: fiber.StatusConflict,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AddDependency(0, 1, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				body: `{"blocked_by_id":2}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "add dependency failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AddDependency(0, 1, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"blocked_by_id":2}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Post("/api/tasks/:id/dependencies", h.AddDependency)
+
+			req := httptest.NewRequest("POST", "/api/tasks/1/dependencies", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_RemoveDependency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RemoveDependency(0, 1, 2).Return(nil)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("DELETE", "/api/tasks/1/dependencies/2", nil),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "blocker id is not int",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("DELETE", "/api/tasks/1/dependencies/foo", nil),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "dependency not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RemoveDependency(0, 1, 2).Return(services.ErrDependencyNotFound)
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("DELETE", "/api/tasks/1/dependencies/2", nil),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "remove dependency failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().RemoveDependency(0, 1, 2).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: httptest.NewRequest("DELETE", "/api/tasks/1/dependencies/2", nil),
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Delete("/api/tasks/:id/dependencies/:blockerId", h.RemoveDependency)
+
+			resp, err := app.Test(tt.args.req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			var result response.Response
+			json.NewDecoder(resp.Body).Decode(&result)
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_taskHandler_AssignProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	projectID := 3
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AssignProject(0, 1, 1, request.AssignedProjectRequest{ProjectID: &projectID}).Return(nil)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/project", bytes.NewReader([]byte(`{"project_id":3}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "project id is invalid",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/project", bytes.NewReader([]byte(`{"project_id":-1}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "project not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AssignProject(0, 1, 1, gomock.Any()).Return(services.ErrProjectNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/project", bytes.NewReader([]byte(`{"project_id":3}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AssignProject(0, 1, 1, gomock.Any()).Return(services.ErrTaskNotFound)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/project", bytes.NewReader([]byte(`{"project_id":null}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().AssignProject(0, 1, 1, gomock.Any()).Return(services.ErrTaskVersionMismatch)
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest("PUT", "/api/tasks/1/project", bytes.NewReader([]byte(`{"project_id":3}`)))
+					req.Header.Set("If-Match", `"1"`)
+					return req
+				}(),
+			},
+			code: fiber.StatusPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			h := taskHandler{
+				taskService: tt.fields.taskService,
+			}
+			app.Put("/api/tasks/:id/project", h.AssignProject)
 
 			tt.args.req.Header.Set("Content-Type", "application/json")
 			resp, err := app.Test(tt.args.req)
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"todo/api/middleware"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type WorkspaceHandler interface {
+	GetWorkspaces(c *fiber.Ctx) error
+	CreateWorkspace(c *fiber.Ctx) error
+	GetMembers(c *fiber.Ctx) error
+	CreateInvitation(c *fiber.Ctx) error
+	AcceptInvitation(c *fiber.Ctx) error
+	LeaveWorkspace(c *fiber.Ctx) error
+	RemoveMember(c *fiber.Ctx) error
+}
+
+type workspaceHandler struct {
+	workspaceService services.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService services.WorkspaceService) WorkspaceHandler {
+	return &workspaceHandler{
+		workspaceService: workspaceService,
+	}
+}
+
+func (h workspaceHandler) GetWorkspaces(c *fiber.Ctx) error {
+	workspaces, err := h.workspaceService.GetWorkspaces(middleware.UserID(c))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: workspaces})
+}
+
+func (h workspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	var req request.CreatedWorkspaceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(middleware.UserID(c), req)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: workspace})
+}
+
+func (h workspaceHandler) GetMembers(c *fiber.Ctx) error {
+	members, err := h.workspaceService.GetMembers(middleware.WorkspaceID(c))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: members})
+}
+
+// CreateInvitation answers with the invitation token, which the inviter
+// passes on to the invitee. It can't be shown again.
+func (h workspaceHandler) CreateInvitation(c *fiber.Ctx) error {
+	var req request.CreatedInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	invitation, err := h.workspaceService.CreateInvitation(middleware.WorkspaceID(c), middleware.UserID(c), middleware.Role(c), req)
+	if err != nil {
+		return membershipError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: invitation})
+}
+
+func (h workspaceHandler) AcceptInvitation(c *fiber.Ctx) error {
+	var req request.AcceptedInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := req.Validate()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	membership, err := h.workspaceService.AcceptInvitation(middleware.UserID(c), req)
+	if err != nil {
+		return membershipError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK, Data: membership})
+}
+
+func (h workspaceHandler) LeaveWorkspace(c *fiber.Ctx) error {
+	err := h.workspaceService.LeaveWorkspace(middleware.WorkspaceID(c), middleware.UserID(c))
+	if err != nil {
+		return membershipError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func (h workspaceHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("userId")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = h.workspaceService.RemoveMember(middleware.WorkspaceID(c), middleware.Role(c), userID)
+	if err != nil {
+		return membershipError(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response.Response{
+		Status: fiber.StatusOK,
+	})
+}
+
+func membershipError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrMemberNotFound), errors.Is(err, services.ErrInvitationNotFound):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrAlreadyMember), errors.Is(err, services.ErrLastOwner):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrRoleTooHigh):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError)
+}
@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_workspaceHandler_GetWorkspaces(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetWorkspaces(0).Return([]entities.Workspace{{ID: 1, Name: "Personal", Role: enum.RoleOwner}}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get failed",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetWorkspaces(0).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Get("/api/workspaces", h.GetWorkspaces)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/workspaces", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_CreateWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().CreateWorkspace(0, request.CreatedWorkspaceRequest{Name: "Team"}).Return(entities.Workspace{ID: 3, Name: "Team"}, nil)
+				},
+			},
+			args: args{
+				body: `{"name":"Team"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "name is missing",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				body: `{"name":" "}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().CreateWorkspace(0, gomock.Any()).Return(entities.Workspace{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"name":"Team"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Post("/api/workspaces", h.CreateWorkspace)
+
+			req := httptest.NewRequest("POST", "/api/workspaces", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_GetMembers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetMembers(0).Return([]entities.Membership{{WorkspaceID: 3, UserID: 7, Role: enum.RoleOwner}}, nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "get failed",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetMembers(0).Return(nil, errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Get("/api/workspaces/:id/members", h.GetMembers)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/workspaces/3/members", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_CreateInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().CreateInvitation(0, 0, enum.Role(""), request.CreatedInvitationRequest{Email: "john@example.com", Role: enum.RoleViewer}).
+						Return(response.CreatedInvitation{Token: "token"}, nil)
+				},
+			},
+			args: args{
+				body: `{"email":"john@example.com","role":"viewer"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "email is invalid",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				body: `{"email":"john"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "role is invalid",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				body: `{"email":"john@example.com","role":"guest"}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "role above the inviter",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().CreateInvitation(0, 0, gomock.Any(), gomock.Any()).Return(response.CreatedInvitation{}, services.ErrRoleTooHigh)
+				},
+			},
+			args: args{
+				body: `{"email":"john@example.com","role":"owner"}`,
+			},
+			code: fiber.StatusForbidden,
+		},
+		{
+			name: "already a member",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().CreateInvitation(0, 0, gomock.Any(), gomock.Any()).Return(response.CreatedInvitation{}, services.ErrAlreadyMember)
+				},
+			},
+			args: args{
+				body: `{"email":"john@example.com"}`,
+			},
+			code: fiber.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Post("/api/workspaces/:id/invitations", h.CreateInvitation)
+
+			req := httptest.NewRequest("POST", "/api/workspaces/3/invitations", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_AcceptInvitation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	type args struct {
+		body string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().AcceptInvitation(0, request.AcceptedInvitationRequest{Token: "token"}).
+						Return(entities.Membership{WorkspaceID: 3, Role: enum.RoleMember}, nil)
+				},
+			},
+			args: args{
+				body: `{"token":"token"}`,
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "token is missing",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				body: `{}`,
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "invitation not found",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().AcceptInvitation(0, gomock.Any()).Return(entities.Membership{}, services.ErrInvitationNotFound)
+				},
+			},
+			args: args{
+				body: `{"token":"token"}`,
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "accept failed",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().AcceptInvitation(0, gomock.Any()).Return(entities.Membership{}, errors.New("foo"))
+				},
+			},
+			args: args{
+				body: `{"token":"token"}`,
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Post("/api/workspaces/invitations/accept", h.AcceptInvitation)
+
+			req := httptest.NewRequest("POST", "/api/workspaces/invitations/accept", bytes.NewReader([]byte(tt.args.body)))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_LeaveWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().LeaveWorkspace(0, 0).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "last owner",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().LeaveWorkspace(0, 0).Return(services.ErrLastOwner)
+				},
+			},
+			code: fiber.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Post("/api/workspaces/:id/leave", h.LeaveWorkspace)
+
+			resp, err := app.Test(httptest.NewRequest("POST", "/api/workspaces/3/leave", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func Test_workspaceHandler_RemoveMember(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	type args struct {
+		userID string
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		code   int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().RemoveMember(0, enum.Role(""), 8).Return(nil)
+				},
+			},
+			args: args{
+				userID: "8",
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "invalid user id",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				userID: "foo",
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "not a member",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().RemoveMember(0, gomock.Any(), 8).Return(services.ErrMemberNotFound)
+				},
+			},
+			args: args{
+				userID: "8",
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "member ranks above the caller",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().RemoveMember(0, gomock.Any(), 8).Return(services.ErrRoleTooHigh)
+				},
+			},
+			args: args{
+				userID: "8",
+			},
+			code: fiber.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			h := workspaceHandler{
+				workspaceService: tt.fields.workspaceService,
+			}
+			app.Delete("/api/workspaces/:id/members/:userId", h.RemoveMember)
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/workspaces/3/members/"+tt.args.userID, nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
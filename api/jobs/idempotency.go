@@ -0,0 +1,54 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"todo/api/services"
+	"todo/pkg/logger"
+)
+
+type IdempotencySweeper interface {
+	Run(ctx context.Context)
+}
+
+type idempotencySweeper struct {
+	idempotencyService services.IdempotencyService
+	interval           time.Duration
+	log                logger.Logger
+}
+
+func NewIdempotencySweeper(idempotencyService services.IdempotencyService, interval time.Duration) IdempotencySweeper {
+	return &idempotencySweeper{
+		idempotencyService: idempotencyService,
+		interval:           interval,
+		log:                logger.WithPrefix("job/idempotency"),
+	}
+}
+
+// Run deletes expired idempotency keys on every tick until ctx is
+// cancelled.
+func (j idempotencySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j idempotencySweeper) sweep() {
+	purged, err := j.idempotencyService.PurgeExpiredKeys(time.Now())
+	if err != nil {
+		j.log.Wrap("purge expired idempotency keys failed: %v", err).Error()
+		return
+	}
+	if purged > 0 {
+		j.log.Wrap("purged %d expired idempotency keys", purged).Info()
+	}
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"time"
+	"todo/api/services"
+	"todo/pkg/logger"
+)
+
+type TrashSweeper interface {
+	Run(ctx context.Context)
+}
+
+type trashSweeper struct {
+	taskService services.TaskService
+	retention   time.Duration
+	interval    time.Duration
+	log         logger.Logger
+}
+
+func NewTrashSweeper(taskService services.TaskService, retention time.Duration, interval time.Duration) TrashSweeper {
+	return &trashSweeper{
+		taskService: taskService,
+		retention:   retention,
+		interval:    interval,
+		log:         logger.WithPrefix("job/trash"),
+	}
+}
+
+// Run hard-deletes trashed tasks older than the retention period on every
+// tick until ctx is cancelled.
+func (j trashSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.sweep()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j trashSweeper) sweep() {
+	purged, err := j.taskService.PurgeExpiredTasks(time.Now().Add(-j.retention))
+	if err != nil {
+		j.log.Wrap("purge expired tasks failed: %v", err).Error()
+		return
+	}
+	if purged > 0 {
+		j.log.Wrap("purged %d expired tasks", purged).Info()
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/services"
+	"todo/pkg/auth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// userIDKey holds the authenticated user ID in the request locals.
+	userIDKey = "userID"
+	// scopesKey holds the scopes of the API key the request was made with.
+	// It is unset for access tokens, which carry every scope.
+	scopesKey = "scopes"
+	// apiKeyKey holds an API key RateLimit has verified already.
+	apiKeyKey = "apiKey"
+)
+
+// Authenticate rejects requests without a valid bearer access token or API
+// key.
+func Authenticate(tokens auth.Tokens, apiKeys services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token, ok := bearerToken(c)
+		if !ok {
+			return unauthorized(c, "access token is required")
+		}
+
+		if strings.HasPrefix(token, services.APIKeyPrefix) {
+			key, ok := c.Locals(apiKeyKey).(entities.APIKey)
+			if !ok {
+				var err error
+				key, err = apiKeys.Authenticate(token)
+				if err != nil {
+					if errors.Is(err, services.ErrInvalidAPIKey) {
+						return unauthorized(c, err.Error())
+					}
+					return fiber.NewError(fiber.StatusInternalServerError)
+				}
+			}
+
+			c.Locals(userIDKey, key.UserID)
+			c.Locals(scopesKey, key.Scopes)
+			return c.Next()
+		}
+
+		userID, err := tokens.Verify(token)
+		if err != nil {
+			return unauthorized(c, err.Error())
+		}
+
+		c.Locals(userIDKey, userID)
+		return c.Next()
+	}
+}
+
+// RequireScope lets API keys through with the read scope for safe methods
+// and the write scope for the others. It must run after Authenticate.
+func RequireScope(read enum.Scope, write enum.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		scopes, ok := c.Locals(scopesKey).([]enum.Scope)
+		if !ok {
+			return c.Next()
+		}
+
+		scope := write
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			scope = read
+		}
+		if !slices.Contains(scopes, scope) {
+			return fiber.NewError(fiber.StatusForbidden, "api key lacks the "+string(scope)+" scope")
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireSession rejects API keys, for routes such as key management that
+// need an interactive login. It must run after Authenticate.
+func RequireSession() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals(scopesKey).([]enum.Scope); ok {
+			return fiber.NewError(fiber.StatusForbidden, "api keys cannot be used here")
+		}
+
+		return c.Next()
+	}
+}
+
+// QueryCredentials takes the access token and the workspace from the
+// access_token and workspace_id query parameters when their headers are
+// missing, for EventSource and WebSocket clients in browsers, which cannot
+// set headers. Tokens in URLs end up in logs, so it is only meant for
+// streams. It must run before Authenticate.
+func QueryCredentials() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("access_token")
+		if len(token) > 0 && len(c.Get(fiber.HeaderAuthorization)) == 0 {
+			c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+token)
+		}
+
+		workspaceID := c.Query("workspace_id")
+		if len(workspaceID) > 0 && len(c.Get(WorkspaceHeader)) == 0 {
+			c.Request().Header.Set(WorkspaceHeader, workspaceID)
+		}
+
+		return c.Next()
+	}
+}
+
+// UserID is the user authenticated by Authenticate, or 0 outside of it.
+func UserID(c *fiber.Ctx) int {
+	userID, _ := c.Locals(userIDKey).(int)
+	return userID
+}
+
+func bearerToken(c *fiber.Ctx) (string, bool) {
+	scheme, token, ok := strings.Cut(c.Get(fiber.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, len(token) > 0
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="todo"`)
+	return fiber.NewError(fiber.StatusUnauthorized, message)
+}
@@ -0,0 +1,317 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/services"
+	"todo/api/services/mock"
+	"todo/pkg/auth"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := auth.New([]byte("secret"), time.Minute, time.Hour)
+	valid, _, err := tokens.Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, _, err := auth.New([]byte("other secret"), time.Minute, time.Hour).Issue(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := auth.Sign(auth.Claims{Audience: auth.AccessAudience, Subject: "7", IssuedAt: 1, ExpiresAt: 2}, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unscoped, err := auth.Sign(auth.Claims{Subject: "7", IssuedAt: 1, ExpiresAt: time.Now().Add(time.Minute).Unix()}, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// what the share service signs for link 7
+	share, err := auth.Sign(map[string]any{"aud": "share", "sub": "7", "exp": time.Now().Add(time.Minute).Unix()}, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		authorization   string
+		apiKeys         *mock.MockAPIKeyService
+		apiKeysBehavior func(*mock.MockAPIKeyService)
+		code            int
+		userID          string
+	}{
+		{
+			name:          "success",
+			authorization: "Bearer " + valid,
+			code:          fiber.StatusOK,
+			userID:        "7",
+		},
+		{
+			name:          "scheme is case insensitive",
+			authorization: "bearer " + valid,
+			code:          fiber.StatusOK,
+			userID:        "7",
+		},
+		{
+			name: "token is missing",
+			code: fiber.StatusUnauthorized,
+		},
+		{
+			name:          "scheme is not bearer",
+			authorization: "Basic " + valid,
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "token is signed with another secret",
+			authorization: "Bearer " + forged,
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "token has expired",
+			authorization: "Bearer " + expired,
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "token has no audience",
+			authorization: "Bearer " + unscoped,
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "share token",
+			authorization: "Bearer " + share,
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "token is malformed",
+			authorization: "Bearer foo",
+			code:          fiber.StatusUnauthorized,
+		},
+		{
+			name:          "api key",
+			authorization: "Bearer todo_key",
+			apiKeys:       mock.NewMockAPIKeyService(ctrl),
+			apiKeysBehavior: func(maks *mock.MockAPIKeyService) {
+				maks.EXPECT().Authenticate("todo_key").Return(entities.APIKey{UserID: 8, Scopes: []enum.Scope{enum.ScopeTasksRead}}, nil)
+			},
+			code:   fiber.StatusOK,
+			userID: "8",
+		},
+		{
+			name:          "api key is invalid",
+			authorization: "Bearer todo_key",
+			apiKeys:       mock.NewMockAPIKeyService(ctrl),
+			apiKeysBehavior: func(maks *mock.MockAPIKeyService) {
+				maks.EXPECT().Authenticate("todo_key").Return(entities.APIKey{}, services.ErrInvalidAPIKey)
+			},
+			code: fiber.StatusUnauthorized,
+		},
+		{
+			name:          "api key lookup failed",
+			authorization: "Bearer todo_key",
+			apiKeys:       mock.NewMockAPIKeyService(ctrl),
+			apiKeysBehavior: func(maks *mock.MockAPIKeyService) {
+				maks.EXPECT().Authenticate("todo_key").Return(entities.APIKey{}, errors.New("foo"))
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.apiKeysBehavior != nil {
+				tt.apiKeysBehavior(tt.apiKeys)
+			}
+
+			app := fiber.New()
+			app.Get("/api/tasks", Authenticate(tokens, tt.apiKeys), func(c *fiber.Ctx) error {
+				return c.SendString(strconv.Itoa(UserID(c)))
+			})
+
+			req := httptest.NewRequest("GET", "/api/tasks", nil)
+			if len(tt.authorization) > 0 {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if tt.code == fiber.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="todo"`, resp.Header.Get("WWW-Authenticate"))
+			}
+			if len(tt.userID) > 0 {
+				body := make([]byte, 8)
+				n, _ := resp.Body.Read(body)
+				assert.Equal(t, tt.userID, string(body[:n]))
+			}
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		scopes []enum.Scope
+		code   int
+	}{
+		{
+			name:   "access token",
+			method: fiber.MethodDelete,
+			scopes: nil,
+			code:   fiber.StatusOK,
+		},
+		{
+			name:   "read with read scope",
+			method: fiber.MethodGet,
+			scopes: []enum.Scope{enum.ScopeTasksRead},
+			code:   fiber.StatusOK,
+		},
+		{
+			name:   "write with read scope",
+			method: fiber.MethodPost,
+			scopes: []enum.Scope{enum.ScopeTasksRead},
+			code:   fiber.StatusForbidden,
+		},
+		{
+			name:   "read with write scope",
+			method: fiber.MethodGet,
+			scopes: []enum.Scope{enum.ScopeTasksWrite},
+			code:   fiber.StatusForbidden,
+		},
+		{
+			name:   "write with write scope",
+			method: fiber.MethodPatch,
+			scopes: []enum.Scope{enum.ScopeTasksWrite},
+			code:   fiber.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.scopes != nil {
+					c.Locals(scopesKey, tt.scopes)
+				}
+				return c.Next()
+			})
+			app.Use(RequireScope(enum.ScopeTasksRead, enum.ScopeTasksWrite))
+			app.All("/api/tasks", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/api/tasks", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func TestRequireSession(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []enum.Scope
+		code   int
+	}{
+		{
+			name:   "access token",
+			scopes: nil,
+			code:   fiber.StatusOK,
+		},
+		{
+			name:   "api key",
+			scopes: enum.Scopes,
+			code:   fiber.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.scopes != nil {
+					c.Locals(scopesKey, tt.scopes)
+				}
+				return c.Next()
+			})
+			app.Get("/api/me/tokens", RequireSession(), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/me/tokens", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func TestQueryCredentials(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		headers       map[string]string
+		authorization string
+		workspace     string
+	}{
+		{
+			name:          "from the query",
+			url:           "/api/tasks/stream?access_token=foo&workspace_id=3",
+			authorization: "Bearer foo",
+			workspace:     "3",
+		},
+		{
+			name: "headers take precedence",
+			url:  "/api/tasks/stream?access_token=foo&workspace_id=3",
+			headers: map[string]string{
+				fiber.HeaderAuthorization: "Bearer bar",
+				WorkspaceHeader:           "4",
+			},
+			authorization: "Bearer bar",
+			workspace:     "4",
+		},
+		{
+			name: "none",
+			url:  "/api/tasks/stream",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authorization, workspace string
+			app := fiber.New()
+			app.Get("/api/tasks/stream", QueryCredentials(), func(c *fiber.Ctx) error {
+				authorization = c.Get(fiber.HeaderAuthorization)
+				workspace = c.Get(WorkspaceHeader)
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", tt.url, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			_, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.authorization, authorization)
+			assert.Equal(t, tt.workspace, workspace)
+		})
+	}
+}
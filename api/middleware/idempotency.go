@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"todo/api/services"
+	"todo/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks responses replayed from an earlier
+	// request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// Idempotent makes retries of a request with the same Idempotency-Key
+// header get the response of the first one instead of repeating it. A key
+// reused for another request is refused with 422. Requests without the
+// header pass through. It must run after Authenticate and
+// CurrentWorkspace.
+func Idempotent(idempotencyService services.IdempotencyService) fiber.Handler {
+	log := logger.WithPrefix("middleware/idempotency")
+
+	return func(c *fiber.Ctx) error {
+		key := strings.TrimSpace(c.Get(IdempotencyKeyHeader))
+		if len(key) == 0 {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, IdempotencyKeyHeader+" header is exceeded more than 255")
+		}
+
+		userID := UserID(c)
+		hash := requestHash(c)
+		stored, err := idempotencyService.BeginRequest(userID, key, hash)
+		if err != nil {
+			if errors.Is(err, services.ErrIdempotencyKeyReused) {
+				return fiber.NewError(fiber.StatusUnprocessableEntity, err.Error())
+			}
+			if errors.Is(err, services.ErrIdempotencyKeyInProgress) {
+				return fiber.NewError(fiber.StatusConflict, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError)
+		}
+		if stored != nil {
+			c.Set(IdempotentReplayedHeader, "true")
+			c.Set(fiber.HeaderContentType, stored.ContentType)
+			return c.Status(stored.StatusCode).Send(stored.Response)
+		}
+
+		// errors only become responses in the error handler, which has to
+		// run here for the response to be stored
+		if err := c.Next(); err != nil {
+			if err := c.App().ErrorHandler(c, err); err != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			// the request may succeed when retried
+			if err := idempotencyService.ReleaseRequest(userID, key, hash); err != nil {
+				log.Wrap("release idempotency key failed: %v", err).Error()
+			}
+			return nil
+		}
+
+		err = idempotencyService.CompleteRequest(userID, key, hash, status, string(c.Response().Header.ContentType()), c.Response().Body())
+		if err != nil {
+			// the key is released once its lease runs out
+			log.Wrap("store idempotent response failed: %v", err).Error()
+		}
+
+		return nil
+	}
+}
+
+// requestHash tells requests apart by route, workspace and body.
+func requestHash(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.Path() + " " + strconv.Itoa(WorkspaceID(c)) + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
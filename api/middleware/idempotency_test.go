@@ -0,0 +1,251 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo/api/entities"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		idempotencyService         *mock.MockIdempotencyService
+		idempotencyServiceBehavior func(*mock.MockIdempotencyService)
+	}
+	type args struct {
+		key string
+		err error
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		args         args
+		code         int
+		body         string
+		replayed     bool
+		handlerCalls int
+	}{
+		{
+			name: "no key",
+			fields: fields{
+				idempotencyService:         mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {},
+			},
+			code:         fiber.StatusOK,
+			body:         `{"id":1}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "first request",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, nil)
+					mis.EXPECT().CompleteRequest(7, "key", gomock.Any(), fiber.StatusOK, fiber.MIMEApplicationJSON, []byte(`{"id":1}`)).Return(nil)
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code:         fiber.StatusOK,
+			body:         `{"id":1}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "storing the response failed",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, nil)
+					mis.EXPECT().CompleteRequest(7, "key", gomock.Any(), fiber.StatusOK, fiber.MIMEApplicationJSON, gomock.Any()).Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code:         fiber.StatusOK,
+			body:         `{"id":1}`,
+			handlerCalls: 1,
+		},
+		{
+			name: "client error is stored",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, nil)
+					mis.EXPECT().CompleteRequest(7, "key", gomock.Any(), fiber.StatusBadRequest, gomock.Any(), []byte("bar")).Return(nil)
+				},
+			},
+			args: args{
+				key: "key",
+				err: fiber.NewError(fiber.StatusBadRequest, "bar"),
+			},
+			code:         fiber.StatusBadRequest,
+			body:         "bar",
+			handlerCalls: 1,
+		},
+		{
+			name: "server error releases the key",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, nil)
+					mis.EXPECT().ReleaseRequest(7, "key", gomock.Any()).Return(nil)
+				},
+			},
+			args: args{
+				key: "key",
+				err: fiber.NewError(fiber.StatusInternalServerError),
+			},
+			code:         fiber.StatusInternalServerError,
+			body:         "Internal Server Error",
+			handlerCalls: 1,
+		},
+		{
+			name: "replay",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(&entities.IdempotencyKey{
+						StatusCode:  fiber.StatusOK,
+						ContentType: fiber.MIMEApplicationJSON,
+						Response:    []byte(`{"id":1}`),
+					}, nil)
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code:     fiber.StatusOK,
+			body:     `{"id":1}`,
+			replayed: true,
+		},
+		{
+			name: "key of another request",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, services.ErrIdempotencyKeyReused)
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code: fiber.StatusUnprocessableEntity,
+			body: services.ErrIdempotencyKeyReused.Error(),
+		},
+		{
+			name: "key in progress",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, services.ErrIdempotencyKeyInProgress)
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code: fiber.StatusConflict,
+			body: services.ErrIdempotencyKeyInProgress.Error(),
+		},
+		{
+			name: "begin failed",
+			fields: fields{
+				idempotencyService: mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {
+					mis.EXPECT().BeginRequest(7, "key", gomock.Any()).Return(nil, errors.New("foo"))
+				},
+			},
+			args: args{
+				key: "key",
+			},
+			code: fiber.StatusInternalServerError,
+			body: "Internal Server Error",
+		},
+		{
+			name: "key too long",
+			fields: fields{
+				idempotencyService:         mock.NewMockIdempotencyService(ctrl),
+				idempotencyServiceBehavior: func(mis *mock.MockIdempotencyService) {},
+			},
+			args: args{
+				key: strings.Repeat("k", 256),
+			},
+			code: fiber.StatusBadRequest,
+			body: "Idempotency-Key header is exceeded more than 255",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.idempotencyServiceBehavior(tt.fields.idempotencyService)
+
+			calls := 0
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(userIDKey, 7)
+				return c.Next()
+			})
+			app.Post("/api/tasks", Idempotent(tt.fields.idempotencyService), func(c *fiber.Ctx) error {
+				calls++
+				if tt.args.err != nil {
+					return tt.args.err
+				}
+				return c.Status(fiber.StatusOK).JSON(fiber.Map{"id": 1})
+			})
+
+			req := httptest.NewRequest("POST", "/api/tasks", strings.NewReader(`{"title":"foo"}`))
+			if len(tt.args.key) > 0 {
+				req.Header.Set(IdempotencyKeyHeader, tt.args.key)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+			assert.Equal(t, tt.replayed, resp.Header.Get(IdempotentReplayedHeader) == "true")
+			assert.Equal(t, tt.handlerCalls, calls)
+		})
+	}
+}
+
+func TestRequestHash(t *testing.T) {
+	hashes := map[string]bool{}
+	for _, r := range []struct {
+		path        string
+		workspaceID int
+		body        string
+	}{
+		{path: "/api/tasks", body: `{"title":"foo"}`},
+		{path: "/api/tasks", body: `{"title":"bar"}`},
+		{path: "/api/tasks", workspaceID: 3, body: `{"title":"foo"}`},
+		{path: "/api/projects", body: `{"title":"foo"}`},
+	} {
+		app := fiber.New()
+		app.Post("/*", func(c *fiber.Ctx) error {
+			if r.workspaceID > 0 {
+				c.Locals(workspaceIDKey, r.workspaceID)
+			}
+			hashes[requestHash(c)] = true
+			return nil
+		})
+		if _, err := app.Test(httptest.NewRequest("POST", r.path, strings.NewReader(r.body))); err != nil {
+			t.Fatalf("Error while performing the request: %v", err)
+		}
+	}
+
+	assert.Len(t, hashes, 4)
+}
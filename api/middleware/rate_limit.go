@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"todo/api/services"
+	"todo/pkg/auth"
+	"todo/pkg/logger"
+	"todo/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit counts requests per client against the rule of their route and
+// answers 429 once a client is over the limit. It runs before any route, so
+// it tells clients apart by a valid API key, the user of a valid access
+// token or else their IP.
+func RateLimit(limiter ratelimit.Limiter, policy ratelimit.Policy, tokens auth.Tokens, apiKeys services.APIKeyService) fiber.Handler {
+	log := logger.WithPrefix("middleware/rate_limit")
+
+	return func(c *fiber.Ctx) error {
+		rule := policy.Match(c.Method(), c.Path())
+		if rule.Limit <= 0 {
+			return c.Next()
+		}
+
+		result, err := limiter.Allow(c.UserContext(), clientKey(c, tokens, apiKeys, log), rule)
+		if err != nil {
+			// an unavailable limiter is no reason to turn clients away
+			log.Wrap("rate limit failed: %v", err).Error()
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		c.Set("RateLimit-Policy", strconv.Itoa(rule.Limit)+";w="+strconv.Itoa(seconds(rule.Window)))
+		if !result.Allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds(result.RetryAfter)))
+			return fiber.NewError(fiber.StatusTooManyRequests, "rate limit exceeded")
+		}
+
+		return c.Next()
+	}
+}
+
+// clientKey names the client of a request. Credentials that don't verify
+// count against the IP, so made-up keys can't buy fresh limits. A verified
+// API key is kept for Authenticate, which then needn't look it up again.
+func clientKey(c *fiber.Ctx, tokens auth.Tokens, apiKeys services.APIKeyService, log logger.Logger) string {
+	if token, ok := bearerToken(c); ok {
+		if strings.HasPrefix(token, services.APIKeyPrefix) {
+			key, err := apiKeys.Authenticate(token)
+			if err == nil {
+				c.Locals(apiKeyKey, key)
+				return "key:" + strconv.Itoa(key.ID)
+			}
+			if !errors.Is(err, services.ErrInvalidAPIKey) {
+				log.Wrap("verify api key failed: %v", err).Error()
+			}
+		} else if userID, err := tokens.Verify(token); err == nil {
+			return "user:" + strconv.Itoa(userID)
+		}
+	}
+
+	return "ip:" + c.IP()
+}
+
+// seconds rounds up, so clients never come back too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/services"
+	"todo/api/services/mock"
+	"todo/pkg/auth"
+	"todo/pkg/ratelimit"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	tokens := auth.New([]byte("secret"), time.Minute, time.Hour)
+	accessToken := func(userID int) string {
+		token, _, err := tokens.Issue(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeys := mock.NewMockAPIKeyService(ctrl)
+	apiKeys.EXPECT().Authenticate(gomock.Any()).DoAndReturn(func(token string) (entities.APIKey, error) {
+		switch token {
+		case "todo_a":
+			return entities.APIKey{ID: 1, UserID: 1}, nil
+		case "todo_b":
+			return entities.APIKey{ID: 2, UserID: 1}, nil
+		}
+		return entities.APIKey{}, services.ErrInvalidAPIKey
+	}).AnyTimes()
+
+	policy := ratelimit.Policy{
+		Default: ratelimit.Rule{Limit: 2, Window: time.Minute},
+		Rules: []ratelimit.Rule{
+			{Path: "/api/health", Limit: 0, Window: time.Minute},
+			{Method: "POST", Path: "/api/auth/login", Limit: 1, Window: time.Minute},
+		},
+	}
+
+	type request struct {
+		method        string
+		path          string
+		authorization string
+		code          int
+	}
+	tests := []struct {
+		name     string
+		requests []request
+	}{
+		{
+			name: "by ip",
+			requests: []request{
+				{method: "GET", path: "/api/tasks", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "by user",
+			requests: []request{
+				{method: "GET", path: "/api/tasks", authorization: accessToken(1), code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: accessToken(1), code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: accessToken(2), code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: accessToken(1), code: fiber.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "by api key",
+			requests: []request{
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_a", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_a", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_b", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_a", code: fiber.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "invalid api key",
+			requests: []request{
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_c", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_d", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer todo_a", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "invalid access token",
+			requests: []request{
+				{method: "GET", path: "/api/tasks", authorization: "Bearer foo", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", authorization: "Bearer bar", code: fiber.StatusOK},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "by route",
+			requests: []request{
+				{method: "POST", path: "/api/auth/login", code: fiber.StatusOK},
+				{method: "POST", path: "/api/auth/login", code: fiber.StatusTooManyRequests},
+				{method: "GET", path: "/api/tasks", code: fiber.StatusOK},
+			},
+		},
+		{
+			name: "unlimited",
+			requests: []request{
+				{method: "GET", path: "/api/health", code: fiber.StatusOK},
+				{method: "GET", path: "/api/health", code: fiber.StatusOK},
+				{method: "GET", path: "/api/health", code: fiber.StatusOK},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(RateLimit(ratelimit.NewMemoryLimiter(), policy, tokens, apiKeys))
+			app.All("/*", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			for _, r := range tt.requests {
+				req := httptest.NewRequest(r.method, r.path, nil)
+				if len(r.authorization) > 0 {
+					req.Header.Set(fiber.HeaderAuthorization, r.authorization)
+				}
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatalf("Error while performing the request: %v", err)
+				}
+
+				assert.Equal(t, r.code, resp.StatusCode, "%s %s", r.method, r.path)
+			}
+		})
+	}
+}
+
+func TestRateLimit_headers(t *testing.T) {
+	policy := ratelimit.Policy{Default: ratelimit.Rule{Limit: 1, Window: time.Minute}}
+
+	app := fiber.New()
+	app.Use(RateLimit(ratelimit.NewMemoryLimiter(), policy, auth.New([]byte("secret"), 0, 0), nil))
+	app.Get("/api/tasks", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks", nil))
+	if err != nil {
+		t.Fatalf("Error while performing the request: %v", err)
+	}
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	assert.Equal(t, "1", resp.Header.Get("RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get("RateLimit-Reset"))
+	assert.Equal(t, "1;w=60", resp.Header.Get("RateLimit-Policy"))
+	assert.Empty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/tasks", nil))
+	if err != nil {
+		t.Fatalf("Error while performing the request: %v", err)
+	}
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+	assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+}
+
+func TestRateLimit_apiKeyLookedUpOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tokens := auth.New([]byte("secret"), time.Minute, time.Hour)
+	apiKeys := mock.NewMockAPIKeyService(ctrl)
+	apiKeys.EXPECT().Authenticate("todo_a").Return(entities.APIKey{ID: 1, UserID: 7}, nil).Times(1)
+
+	policy := ratelimit.Policy{Default: ratelimit.Rule{Limit: 1, Window: time.Minute}}
+
+	app := fiber.New()
+	app.Use(RateLimit(ratelimit.NewMemoryLimiter(), policy, tokens, apiKeys))
+	app.Get("/api/tasks", Authenticate(tokens, apiKeys), func(c *fiber.Ctx) error {
+		assert.Equal(t, 7, UserID(c))
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/api/tasks", nil)
+	req.Header.Set(fiber.HeaderAuthorization, "Bearer todo_a")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("Error while performing the request: %v", err)
+	}
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"errors"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TaskWorkspace answers 404 for tasks outside the current workspace, so
+// routes below /tasks/:id don't have to check it themselves. It must run
+// after CurrentWorkspace.
+func TaskWorkspace(taskService services.TaskService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		err = taskService.CheckWorkspace(id, WorkspaceID(c))
+		if err != nil {
+			if errors.Is(err, services.ErrTaskNotFound) {
+				return fiber.NewError(fiber.StatusNotFound, err.Error())
+			}
+			return fiber.NewError(fiber.StatusInternalServerError)
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		taskService         *mock.MockTaskService
+		taskServiceBehavior func(*mock.MockTaskService)
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		code   int
+	}{
+		{
+			name: "task of the workspace",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CheckWorkspace(1, 3).Return(nil)
+				},
+			},
+			code: fiber.StatusOK,
+		},
+		{
+			name: "task of another workspace",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CheckWorkspace(1, 3).Return(services.ErrTaskNotFound)
+				},
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "check workspace failed",
+			fields: fields{
+				taskService: mock.NewMockTaskService(ctrl),
+				taskServiceBehavior: func(mts *mock.MockTaskService) {
+					mts.EXPECT().CheckWorkspace(1, 3).Return(errors.New("foo"))
+				},
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.taskServiceBehavior(tt.fields.taskService)
+
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(workspaceIDKey, 3)
+				return c.Next()
+			})
+			app.Use("/api/tasks/:id<int>", TaskWorkspace(tt.fields.taskService))
+			app.Get("/api/tasks/:id/comments", func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/api/tasks/1/comments", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"todo/api/enum"
+	"todo/api/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WorkspaceHeader names the workspace a request acts in.
+const WorkspaceHeader = "X-Workspace-ID"
+
+const (
+	// workspaceIDKey holds the workspace the request acts in.
+	workspaceIDKey = "workspaceID"
+	// roleKey holds the caller's role in that workspace.
+	roleKey = "role"
+)
+
+// CurrentWorkspace resolves the workspace named by the X-Workspace-ID
+// header, or the caller's default one without it. Workspaces the caller
+// isn't a member of answer 404. It must run after Authenticate.
+func CurrentWorkspace(workspaceService services.WorkspaceService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(WorkspaceHeader)
+		if len(header) == 0 {
+			workspaceID, err := workspaceService.DefaultWorkspace(UserID(c))
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError)
+			}
+			return member(c, workspaceService, workspaceID)
+		}
+
+		workspaceID, err := strconv.Atoi(header)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "workspace id is invalid")
+		}
+
+		return member(c, workspaceService, workspaceID)
+	}
+}
+
+// WorkspaceMember resolves the workspace of the :id route parameter. It
+// must run after Authenticate.
+func WorkspaceMember(workspaceService services.WorkspaceService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		workspaceID, err := c.ParamsInt("id")
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+
+		return member(c, workspaceService, workspaceID)
+	}
+}
+
+// Authorize rejects callers whose role lacks the permission. It must run
+// after CurrentWorkspace or WorkspaceMember.
+func Authorize(permission enum.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !Role(c).Can(permission) {
+			return fiber.NewError(fiber.StatusForbidden, "your role lacks the "+string(permission)+" permission")
+		}
+
+		return c.Next()
+	}
+}
+
+// AuthorizeMethod asks for the read permission on safe methods and the write
+// permission on the others, like RequireScope does for API keys.
+func AuthorizeMethod(read enum.Permission, write enum.Permission) fiber.Handler {
+	authorizeRead, authorizeWrite := Authorize(read), Authorize(write)
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return authorizeRead(c)
+		}
+		return authorizeWrite(c)
+	}
+}
+
+// WorkspaceID is the workspace resolved by CurrentWorkspace or
+// WorkspaceMember, or 0 outside of them.
+func WorkspaceID(c *fiber.Ctx) int {
+	workspaceID, _ := c.Locals(workspaceIDKey).(int)
+	return workspaceID
+}
+
+// Role is the caller's role in WorkspaceID, or empty outside of it.
+func Role(c *fiber.Ctx) enum.Role {
+	role, _ := c.Locals(roleKey).(enum.Role)
+	return role
+}
+
+func member(c *fiber.Ctx, workspaceService services.WorkspaceService, workspaceID int) error {
+	role, err := workspaceService.GetRole(workspaceID, UserID(c))
+	if err != nil {
+		if errors.Is(err, services.ErrWorkspaceNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError)
+	}
+
+	c.Locals(workspaceIDKey, workspaceID)
+	c.Locals(roleKey, role)
+	return c.Next()
+}
@@ -0,0 +1,230 @@
+package middleware
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"todo/api/enum"
+	"todo/api/services"
+	"todo/api/services/mock"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrentWorkspace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		workspaceService         *mock.MockWorkspaceService
+		workspaceServiceBehavior func(*mock.MockWorkspaceService)
+	}
+	type args struct {
+		header string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		code      int
+		workspace int
+	}{
+		{
+			name: "named workspace",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetRole(3, 7).Return(enum.RoleMember, nil)
+				},
+			},
+			args: args{
+				header: "3",
+			},
+			code:      fiber.StatusOK,
+			workspace: 3,
+		},
+		{
+			name: "default workspace",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().DefaultWorkspace(7).Return(1, nil)
+					mws.EXPECT().GetRole(1, 7).Return(enum.RoleOwner, nil)
+				},
+			},
+			args: args{
+				header: "",
+			},
+			code:      fiber.StatusOK,
+			workspace: 1,
+		},
+		{
+			name: "invalid workspace id",
+			fields: fields{
+				workspaceService:         mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {},
+			},
+			args: args{
+				header: "foo",
+			},
+			code: fiber.StatusBadRequest,
+		},
+		{
+			name: "not a member",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().GetRole(3, 7).Return(enum.Role(""), services.ErrWorkspaceNotFound)
+				},
+			},
+			args: args{
+				header: "3",
+			},
+			code: fiber.StatusNotFound,
+		},
+		{
+			name: "default workspace failed",
+			fields: fields{
+				workspaceService: mock.NewMockWorkspaceService(ctrl),
+				workspaceServiceBehavior: func(mws *mock.MockWorkspaceService) {
+					mws.EXPECT().DefaultWorkspace(7).Return(0, errors.New("foo"))
+				},
+			},
+			args: args{
+				header: "",
+			},
+			code: fiber.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.workspaceServiceBehavior(tt.fields.workspaceService)
+
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(userIDKey, 7)
+				return c.Next()
+			})
+			app.Get("/api/tasks", CurrentWorkspace(tt.fields.workspaceService), func(c *fiber.Ctx) error {
+				return c.SendString(strconv.Itoa(WorkspaceID(c)))
+			})
+
+			req := httptest.NewRequest("GET", "/api/tasks", nil)
+			if len(tt.args.header) > 0 {
+				req.Header.Set(WorkspaceHeader, tt.args.header)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+			if tt.code == fiber.StatusOK {
+				body := make([]byte, 8)
+				n, _ := resp.Body.Read(body)
+				assert.Equal(t, strconv.Itoa(tt.workspace), string(body[:n]))
+			}
+		})
+	}
+}
+
+func TestAuthorizeMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		role   enum.Role
+		code   int
+	}{
+		{
+			name:   "viewer reads",
+			method: "GET",
+			role:   enum.RoleViewer,
+			code:   fiber.StatusOK,
+		},
+		{
+			name:   "viewer writes",
+			method: "POST",
+			role:   enum.RoleViewer,
+			code:   fiber.StatusForbidden,
+		},
+		{
+			name:   "member writes",
+			method: "PUT",
+			role:   enum.RoleMember,
+			code:   fiber.StatusOK,
+		},
+		{
+			name:   "not resolved",
+			method: "GET",
+			role:   "",
+			code:   fiber.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if len(tt.role) > 0 {
+					c.Locals(roleKey, tt.role)
+				}
+				return c.Next()
+			})
+			app.All("/api/tasks", AuthorizeMethod(enum.PermissionTasksRead, enum.PermissionTasksWrite), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest(tt.method, "/api/tasks", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name string
+		role enum.Role
+		code int
+	}{
+		{
+			name: "owner",
+			role: enum.RoleOwner,
+			code: fiber.StatusOK,
+		},
+		{
+			name: "admin",
+			role: enum.RoleAdmin,
+			code: fiber.StatusOK,
+		},
+		{
+			name: "member",
+			role: enum.RoleMember,
+			code: fiber.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals(roleKey, tt.role)
+				return c.Next()
+			})
+			app.Delete("/api/tasks/:id", Authorize(enum.PermissionTasksDelete), func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/api/tasks/1", nil))
+			if err != nil {
+				t.Fatalf("Error while performing the request: %v", err)
+			}
+
+			assert.Equal(t, tt.code, resp.StatusCode)
+		})
+	}
+}
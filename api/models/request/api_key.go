@@ -0,0 +1,37 @@
+package request
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo/api/enum"
+)
+
+type CreatedAPIKeyRequest struct {
+	Name string `json:"name"`
+	// Scopes default to all scopes when empty.
+	Scopes    []enum.Scope `json:"scopes"`
+	ExpiresAt *time.Time   `json:"expires_at"`
+}
+
+func (r CreatedAPIKeyRequest) Validate() error {
+	if len(strings.TrimSpace(r.Name)) == 0 {
+		return errors.New("name is required")
+	}
+
+	if len(r.Name) > 100 {
+		return errors.New("name is exceeded more than 100")
+	}
+
+	for _, scope := range r.Scopes {
+		if !scope.IsValid() {
+			return errors.New("scope is invalid")
+		}
+	}
+
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return errors.New("expires at must be in the future")
+	}
+
+	return nil
+}
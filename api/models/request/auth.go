@@ -0,0 +1,62 @@
+package request
+
+import (
+	"errors"
+	"net/mail"
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes.
+	maxPasswordLength = 72
+)
+
+type RegisterRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (r RegisterRequest) Validate() error {
+	if len(r.Email) > 255 {
+		return errors.New("email is exceeded more than 255")
+	}
+
+	if address, err := mail.ParseAddress(r.Email); err != nil || address.Address != r.Email {
+		return errors.New("email is invalid")
+	}
+
+	if len(r.Password) < minPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	if len(r.Password) > maxPasswordLength {
+		return errors.New("password is exceeded more than 72 bytes")
+	}
+
+	return nil
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (r LoginRequest) Validate() error {
+	if len(r.Email) == 0 || len(r.Password) == 0 {
+		return errors.New("email and password are required")
+	}
+
+	return nil
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (r RefreshTokenRequest) Validate() error {
+	if len(r.RefreshToken) == 0 {
+		return errors.New("refresh token is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package request
+
+import (
+	"errors"
+	"strings"
+)
+
+const maxCommentLength = 10000
+
+type CreatedCommentRequest struct {
+	Body string `json:"body"`
+}
+
+func (r CreatedCommentRequest) Validate() error {
+	return validateCommentBody(r.Body)
+}
+
+// CreatedSharedCommentRequest comes from someone without an account, who
+// names themselves.
+type CreatedSharedCommentRequest struct {
+	Author string `json:"author"`
+	Body   string `json:"body"`
+}
+
+func (r CreatedSharedCommentRequest) Validate() error {
+	author := strings.TrimSpace(r.Author)
+	if len(author) == 0 {
+		return errors.New("author is required")
+	}
+
+	if len(author) > 100 {
+		return errors.New("author is exceeded more than 100")
+	}
+
+	return validateCommentBody(r.Body)
+}
+
+type UpdatedCommentRequest struct {
+	Body string `json:"body"`
+}
+
+func (r UpdatedCommentRequest) Validate() error {
+	return validateCommentBody(r.Body)
+}
+
+func validateCommentBody(body string) error {
+	if len(strings.TrimSpace(body)) == 0 {
+		return errors.New("body is required")
+	}
+
+	if len(body) > maxCommentLength {
+		return errors.New("body is exceeded more than 10000")
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package request
+
+import "encoding/json"
+
+// Optional tells apart a field that is absent from the JSON body, one that
+// is explicitly null and one that carries a value, as JSON Merge Patch
+// (RFC 7396) requires.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if string(b) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(b, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
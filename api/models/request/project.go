@@ -0,0 +1,59 @@
+package request
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type CreatedProjectRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func (r CreatedProjectRequest) Validate() error {
+	return validateProject(r.Name, r.Color)
+}
+
+type UpdatedProjectRequest struct {
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Archived bool   `json:"archived"`
+}
+
+func (r UpdatedProjectRequest) Validate() error {
+	return validateProject(r.Name, r.Color)
+}
+
+// AssignedProjectRequest moves a task into a project, or out of every
+// project when ProjectID is nil.
+type AssignedProjectRequest struct {
+	ProjectID *int `json:"project_id"`
+}
+
+func (r AssignedProjectRequest) Validate() error {
+	if r.ProjectID != nil && *r.ProjectID <= 0 {
+		return errors.New("project id is invalid")
+	}
+
+	return nil
+}
+
+func validateProject(name string, color string) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("project name is required")
+	}
+
+	if len(name) > 100 {
+		return errors.New("project name is exceeded more than 100")
+	}
+
+	if len(color) > 0 && !colorPattern.MatchString(color) {
+		return errors.New("color is invalid")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package request
+
+import (
+	"errors"
+	"time"
+	"todo/api/enum"
+)
+
+const maxShareTTL = 365 * 24 * time.Hour
+
+type CreatedShareLinkRequest struct {
+	// Access defaults to read.
+	Access enum.ShareAccess `json:"access"`
+	// ExpiresAt defaults to a week from now.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Password is optional.
+	Password string `json:"password"`
+}
+
+func (r CreatedShareLinkRequest) Validate() error {
+	if len(r.Access) > 0 && !r.Access.IsValid() {
+		return errors.New("access is invalid")
+	}
+
+	if r.ExpiresAt != nil {
+		if !r.ExpiresAt.After(time.Now()) {
+			return errors.New("expires at must be in the future")
+		}
+		if r.ExpiresAt.After(time.Now().Add(maxShareTTL)) {
+			return errors.New("expires at is more than a year ahead")
+		}
+	}
+
+	if len(r.Password) > 0 && len(r.Password) < minPasswordLength {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	if len(r.Password) > maxPasswordLength {
+		return errors.New("password is exceeded more than 72 bytes")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package request
+
+import (
+	"errors"
+	"strings"
+)
+
+const maxTagLength = 50
+
+type CreatedTagRequest struct {
+	Name string `json:"name"`
+}
+
+func (r CreatedTagRequest) Validate() error {
+	return validateTagName(r.Name)
+}
+
+type UpdatedTagRequest struct {
+	Name string `json:"name"`
+}
+
+func (r UpdatedTagRequest) Validate() error {
+	return validateTagName(r.Name)
+}
+
+type MergedTagRequest struct {
+	IntoID int `json:"into_id"`
+}
+
+func (r MergedTagRequest) Validate() error {
+	if r.IntoID <= 0 {
+		return errors.New("into id is invalid")
+	}
+
+	return nil
+}
+
+func validateTagName(name string) error {
+	name = strings.TrimSpace(name)
+	if len(name) == 0 {
+		return errors.New("tag name is required")
+	}
+
+	if len(name) > maxTagLength {
+		return errors.New("tag name is exceeded more than 50")
+	}
+
+	return nil
+}
+
+func validateTagNames(names []string) error {
+	for _, name := range names {
+		if err := validateTagName(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,14 +2,28 @@ package request
 
 import (
 	"errors"
+	"fmt"
+	"time"
 	"todo/api/enum"
+	"todo/pkg/pagination"
 )
 
+var errInvalidSchedule = errors.New("start at must be before due at")
+
 type CreatedTaskRequest struct {
-	Title       string          `json:"title"`
-	Description string          `json:"description"`
-	Image       string          `json:"image"`
-	Status      enum.TaskStatus `json:"status"`
+	// OwnerID is the authenticated caller and WorkspaceID their current
+	// workspace, never read from the body.
+	OwnerID     int               `json:"-"`
+	WorkspaceID int               `json:"-"`
+	ParentID    *int              `json:"parent_id"`
+	ProjectID   *int              `json:"project_id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      enum.TaskStatus   `json:"status"`
+	Priority    enum.TaskPriority `json:"priority"`
+	StartAt     *time.Time        `json:"start_at"`
+	DueAt       *time.Time        `json:"due_at"`
+	Tags        []string          `json:"tags"`
 }
 
 func (r CreatedTaskRequest) Validate() error {
@@ -21,6 +35,22 @@ func (r CreatedTaskRequest) Validate() error {
 		return errors.New("status is invalid")
 	}
 
+	if len(r.Priority) > 0 && !r.Priority.IsValid() {
+		return errors.New("priority is invalid")
+	}
+
+	if r.StartAt != nil && r.DueAt != nil && r.StartAt.After(*r.DueAt) {
+		return errInvalidSchedule
+	}
+
+	if err := validateTagNames(r.Tags); err != nil {
+		return err
+	}
+
+	if r.ProjectID != nil && *r.ProjectID <= 0 {
+		return errors.New("project id is invalid")
+	}
+
 	return nil
 }
 
@@ -29,6 +59,23 @@ type TaskListQuery struct {
 	Description string              `query:"description"`
 	SortBy      enum.TaskListSortBy `query:"sort_by"`
 	SortOrder   enum.SortOrder      `query:"sort_order"`
+	Page        int                 `query:"page"`
+	PageSize    int                 `query:"page_size"`
+	Cursor      string              `query:"cursor"`
+	DueBefore   string              `query:"due_before"`
+	DueAfter    string              `query:"due_after"`
+	Overdue     bool                `query:"overdue"`
+	Priorities  []enum.TaskPriority `query:"priority"`
+	Blocked     *bool               `query:"blocked"`
+	Tags        []string            `query:"tags"`
+	TagMode     enum.TagMode        `query:"tag_mode"`
+	ProjectID   *int                `query:"project_id"`
+	// WorkspaceID is the caller's current workspace; only its tasks are
+	// listed.
+	WorkspaceID int `query:"-"`
+	// IncludeArchived lists tasks of archived projects too. They are hidden
+	// by default unless ProjectID names the project.
+	IncludeArchived bool `query:"include_archived"`
 }
 
 func (r TaskListQuery) Validate() error {
@@ -40,14 +87,108 @@ func (r TaskListQuery) Validate() error {
 		return errors.New("sort order is invalid")
 	}
 
+	if r.Page < 0 {
+		return errors.New("page is invalid")
+	}
+
+	if r.PageSize < 0 {
+		return errors.New("page size is invalid")
+	}
+
+	if r.PageSize > pagination.MaxPageSize {
+		return fmt.Errorf("page size is exceeded more than %d", pagination.MaxPageSize)
+	}
+
+	if len(r.Cursor) > 0 {
+		if r.Page > 0 {
+			return errors.New("page and cursor cannot be used together")
+		}
+
+		cursor, err := pagination.Decode(r.Cursor)
+		if err != nil {
+			return err
+		}
+
+		// the position in one ordering means nothing in another
+		order := r.SortOrder
+		if len(order) == 0 {
+			order = enum.SortOrderAsc
+		}
+		if cursor.SortBy != string(r.SortBy) || cursor.SortOrder != string(order) {
+			return errors.New("cursor belongs to another sort order")
+		}
+	}
+
+	if _, _, err := r.DueRange(); err != nil {
+		return err
+	}
+
+	for _, priority := range r.Priorities {
+		if !priority.IsValid() {
+			return errors.New("priority is invalid")
+		}
+	}
+
+	if len(r.TagMode) > 0 && !r.TagMode.IsValid() {
+		return errors.New("tag mode is invalid")
+	}
+
+	if r.ProjectID != nil && *r.ProjectID <= 0 {
+		return errors.New("project id is invalid")
+	}
+
 	return nil
 }
 
+// DueRange parses due_before and due_after as RFC 3339 timestamps. Either
+// bound is nil when it was not given.
+func (r TaskListQuery) DueRange() (*time.Time, *time.Time, error) {
+	before, err := parseTime(r.DueBefore)
+	if err != nil {
+		return nil, nil, errors.New("due before is invalid")
+	}
+
+	after, err := parseTime(r.DueAfter)
+	if err != nil {
+		return nil, nil, errors.New("due after is invalid")
+	}
+
+	return before, after, nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if len(value) == 0 {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r TaskListQuery) Limit() int {
+	if r.PageSize == 0 {
+		return pagination.DefaultPageSize
+	}
+	return r.PageSize
+}
+
 type UpdatedTaskRequest struct {
-	Title       string          `json:"title"`
-	Description string          `json:"description"`
-	Image       string          `json:"image"`
-	Status      enum.TaskStatus `json:"status"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Status      enum.TaskStatus   `json:"status"`
+	Priority    enum.TaskPriority `json:"priority"`
+	StartAt     *time.Time        `json:"start_at"`
+	DueAt       *time.Time        `json:"due_at"`
+	// Tags replaces the task's tags by name. Leaving it out keeps them as
+	// they are, an empty list removes them all.
+	Tags []string `json:"tags"`
+
+	// Cascade completes every open descendant when the task is completed.
+	// It is read from the cascade query parameter.
+	Cascade bool `json:"-"`
 }
 
 func (r UpdatedTaskRequest) Validate() error {
@@ -55,9 +196,97 @@ func (r UpdatedTaskRequest) Validate() error {
 		return errors.New("title is exceeded more than 100")
 	}
 
-	if len(r.Status) > 0 && !r.Status.IsValid() {
+	if !r.Status.IsValid() {
 		return errors.New("status is invalid")
 	}
 
+	if len(r.Priority) > 0 && !r.Priority.IsValid() {
+		return errors.New("priority is invalid")
+	}
+
+	if r.StartAt != nil && r.DueAt != nil && r.StartAt.After(*r.DueAt) {
+		return errInvalidSchedule
+	}
+
+	if err := validateTagNames(r.Tags); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type PatchedTaskRequest struct {
+	Title       Optional[string]            `json:"title"`
+	Description Optional[string]            `json:"description"`
+	Status      Optional[enum.TaskStatus]   `json:"status"`
+	Priority    Optional[enum.TaskPriority] `json:"priority"`
+	StartAt     Optional[time.Time]         `json:"start_at"`
+	DueAt       Optional[time.Time]         `json:"due_at"`
+	Tags        Optional[[]string]          `json:"tags"`
+
+	// Cascade completes every open descendant when the task is completed.
+	// It is read from the cascade query parameter.
+	Cascade bool `json:"-"`
+}
+
+func (r PatchedTaskRequest) Validate() error {
+	if r.Title.Null {
+		return errors.New("title cannot be null")
+	}
+
+	if len(r.Title.Value) > 100 {
+		return errors.New("title is exceeded more than 100")
+	}
+
+	if r.Status.Null {
+		return errors.New("status cannot be null")
+	}
+
+	if r.Status.Set && !r.Status.Value.IsValid() {
+		return errors.New("status is invalid")
+	}
+
+	if r.Priority.Null {
+		return errors.New("priority cannot be null")
+	}
+
+	if r.Priority.Set && !r.Priority.Value.IsValid() {
+		return errors.New("priority is invalid")
+	}
+
+	startSet := r.StartAt.Set && !r.StartAt.Null
+	dueSet := r.DueAt.Set && !r.DueAt.Null
+	if startSet && dueSet && r.StartAt.Value.After(r.DueAt.Value) {
+		return errInvalidSchedule
+	}
+
+	if err := validateTagNames(r.Tags.Value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type MovedTaskRequest struct {
+	ParentID *int `json:"parent_id"`
+}
+
+func (r MovedTaskRequest) Validate() error {
+	if r.ParentID != nil && *r.ParentID <= 0 {
+		return errors.New("parent id is invalid")
+	}
+
+	return nil
+}
+
+type CreatedDependencyRequest struct {
+	BlockedByID int `json:"blocked_by_id"`
+}
+
+func (r CreatedDependencyRequest) Validate() error {
+	if r.BlockedByID <= 0 {
+		return errors.New("blocked by id is invalid")
+	}
+
 	return nil
 }
@@ -0,0 +1,40 @@
+package request
+
+import (
+	"errors"
+	"io"
+)
+
+// UploadedImage is a file taken from a multipart form. Its declared
+// content type is ignored, the bytes are sniffed instead.
+type UploadedImage struct {
+	Reader io.Reader
+	Size   int64
+}
+
+func (r UploadedImage) Validate() error {
+	if r.Size <= 0 {
+		return errors.New("image is empty")
+	}
+
+	return nil
+}
+
+type UploadedAttachment struct {
+	Reader      io.Reader
+	Filename    string
+	Size        int64
+	ContentType string
+}
+
+func (r UploadedAttachment) Validate() error {
+	if r.Size <= 0 {
+		return errors.New("file is empty")
+	}
+
+	if len(r.Filename) == 0 {
+		return errors.New("filename is required")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package request
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"todo/api/enum"
+)
+
+type CreatedWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+func (r CreatedWorkspaceRequest) Validate() error {
+	if len(strings.TrimSpace(r.Name)) == 0 {
+		return errors.New("name is required")
+	}
+
+	if len(r.Name) > 100 {
+		return errors.New("name is exceeded more than 100")
+	}
+
+	return nil
+}
+
+type CreatedInvitationRequest struct {
+	Email string `json:"email"`
+	// Role defaults to member.
+	Role enum.Role `json:"role"`
+}
+
+func (r CreatedInvitationRequest) Validate() error {
+	if len(r.Email) > 255 {
+		return errors.New("email is exceeded more than 255")
+	}
+
+	if address, err := mail.ParseAddress(r.Email); err != nil || address.Address != r.Email {
+		return errors.New("email is invalid")
+	}
+
+	if len(r.Role) > 0 && !r.Role.IsValid() {
+		return errors.New("role is invalid")
+	}
+
+	return nil
+}
+
+type AcceptedInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+func (r AcceptedInvitationRequest) Validate() error {
+	if len(r.Token) == 0 {
+		return errors.New("token is required")
+	}
+
+	return nil
+}
@@ -1,6 +1,89 @@
 package response
 
+import (
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+)
+
 type Response struct {
-	Status int         `json:"status"`
-	Data   interface{} `json:"data,omitempty"`
+	Status     int         `json:"status"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type TransitionError struct {
+	Code            int               `json:"code"`
+	Message         string            `json:"message"`
+	AllowedStatuses []enum.TaskStatus `json:"allowed_statuses"`
+}
+
+type TaskDependencies struct {
+	BlockedBy []entities.Task `json:"blocked_by"`
+	Blocks    []entities.Task `json:"blocks"`
+}
+
+type AuthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CreatedAPIKey carries the key itself, which is only ever returned here.
+type CreatedAPIKey struct {
+	entities.APIKey
+	Token string `json:"token"`
+}
+
+// CreatedInvitation carries the invitation token, which is only ever
+// returned here.
+type CreatedInvitation struct {
+	entities.Invitation
+	Token string `json:"token"`
+}
+
+// CreatedShareLink carries the link token, which is only ever returned
+// here.
+type CreatedShareLink struct {
+	entities.ShareLink
+	Token string `json:"token"`
+}
+
+// SharedTask is what a share link shows of its task. Anyone holding the
+// link sees it, so it leaves out IDs, emails and image URLs.
+type SharedTask struct {
+	Title       string           `json:"title"`
+	Description string           `json:"description"`
+	Status      enum.TaskStatus  `json:"status"`
+	DueAt       *time.Time       `json:"due_at"`
+	Comments    []SharedComment  `json:"comments"`
+	Access      enum.ShareAccess `json:"access"`
+	ExpiresAt   time.Time        `json:"expires_at"`
+}
+
+// SharedComment names guests by the name they gave and members by a
+// generic label.
+type SharedComment struct {
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	Guest     bool      `json:"guest"`
+	Edited    bool      `json:"edited"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TaskEvent is a change to a task sent on the task stream. Task is the task
+// as it is now; deleted and reset events leave it out.
+type TaskEvent struct {
+	ID     int64          `json:"id,omitempty"`
+	Type   enum.TaskEvent `json:"type"`
+	TaskID int            `json:"task_id,omitempty"`
+	Task   *entities.Task `json:"task,omitempty"`
 }
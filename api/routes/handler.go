@@ -1,23 +1,101 @@
 package routes
 
 import (
+	"todo/api/enum"
 	"todo/api/handlers"
+	"todo/api/middleware"
 	"todo/api/services"
+	"todo/pkg/auth"
 	"todo/pkg/base"
+	"todo/pkg/cache"
+	"todo/pkg/config"
 	"todo/pkg/database"
+	"todo/pkg/imaging"
+	"todo/pkg/pubsub"
+	"todo/pkg/storage"
+	"todo/pkg/workflow"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type handler struct {
-	task handlers.TaskHandler
+	auth       handlers.AuthHandler
+	apiKey     handlers.APIKeyHandler
+	task       handlers.TaskHandler
+	tag        handlers.TagHandler
+	project    handlers.ProjectHandler
+	comment    handlers.CommentHandler
+	image      handlers.ImageHandler
+	attachment handlers.AttachmentHandler
+	workspace  handlers.WorkspaceHandler
+	share      handlers.ShareHandler
+
+	// middleware
+	queryCredentials fiber.Handler
+	authenticate     fiber.Handler
+	session          fiber.Handler
+	taskScope        fiber.Handler
+	// currentWorkspace resolves the workspace of task routes and
+	// workspaceMember the one of /workspaces/:id routes.
+	currentWorkspace fiber.Handler
+	workspaceMember  fiber.Handler
+	taskWorkspace    fiber.Handler
+	// permissions of the caller's role in the workspace
+	taskAccess    fiber.Handler
+	deleteTasks   fiber.Handler
+	manageMembers fiber.Handler
+	idempotent    fiber.Handler
 }
 
 func NewHandler() handler {
 	repository := base.NewBaseRepository[any](database.GetDatabase())
 
+	// every service writing tasks announces the changes through events,
+	// which also drops the cached task lists they are in
+	events := services.NewTaskEvents(pubsub.GetBroker(), cache.GetCache(), config.GetConfig().Cache.TaskListTTL)
+
 	// services
-	taskService := services.NewTaskService(repository)
+	authService := services.NewAuthService(repository, auth.GetTokens())
+	apiKeyService := services.NewAPIKeyService(repository)
+	workspaceService := services.NewWorkspaceService(repository, events)
+	taskService := services.NewTaskService(repository, storage.GetStore(), workflow.GetWorkflow(), events)
+	tagService := services.NewTagService(repository, events)
+	projectService := services.NewProjectService(repository, events)
+	commentService := services.NewCommentService(repository)
+	imageService := services.NewImageService(repository, storage.GetStore(), imaging.GetProcessor(), events)
+	attachments := config.GetConfig().Attachment
+	attachmentService := services.NewAttachmentService(repository, storage.GetStore(), services.AttachmentLimits{
+		MaxPerTask:    attachments.MaxPerTask,
+		MaxTotalBytes: attachments.MaxTotalBytes,
+	})
+	idempotencyService := services.NewIdempotencyService(repository, config.GetConfig().Idempotency.TTL)
+	shareService := services.NewShareService(repository, []byte(config.GetConfig().Auth.Secret))
 
 	return handler{
-		task: handlers.NewTaskHandler(taskService),
+		auth:       handlers.NewAuthHandler(authService),
+		apiKey:     handlers.NewAPIKeyHandler(apiKeyService),
+		task:       handlers.NewTaskHandler(taskService),
+		tag:        handlers.NewTagHandler(tagService),
+		project:    handlers.NewProjectHandler(projectService, taskService),
+		comment:    handlers.NewCommentHandler(commentService),
+		image:      handlers.NewImageHandler(imageService),
+		attachment: handlers.NewAttachmentHandler(attachmentService),
+		workspace:  handlers.NewWorkspaceHandler(workspaceService),
+		share:      handlers.NewShareHandler(shareService, taskService, commentService),
+
+		queryCredentials: middleware.QueryCredentials(),
+		authenticate:     middleware.Authenticate(auth.GetTokens(), apiKeyService),
+		session:          middleware.RequireSession(),
+		taskScope:        middleware.RequireScope(enum.ScopeTasksRead, enum.ScopeTasksWrite),
+
+		currentWorkspace: middleware.CurrentWorkspace(workspaceService),
+		workspaceMember:  middleware.WorkspaceMember(workspaceService),
+		taskWorkspace:    middleware.TaskWorkspace(taskService),
+
+		taskAccess:    middleware.AuthorizeMethod(enum.PermissionTasksRead, enum.PermissionTasksWrite),
+		deleteTasks:   middleware.Authorize(enum.PermissionTasksDelete),
+		manageMembers: middleware.Authorize(enum.PermissionMembers),
+
+		idempotent: middleware.Idempotent(idempotencyService),
 	}
 }
@@ -14,8 +14,87 @@ func NewRoutes(app *fiber.App) {
 		return c.Status(fiber.StatusOK).JSON(response.Response{Status: fiber.StatusOK})
 	})
 
-	taskGroup := apiGroup.Group("/tasks")
-	taskGroup.Post("", handler.task.CreateTask)
+	authGroup := apiGroup.Group("/auth")
+	authGroup.Post("/register", handler.auth.Register)
+	authGroup.Post("/login", handler.auth.Login)
+	authGroup.Post("/refresh", handler.auth.Refresh)
+	authGroup.Post("/logout", handler.auth.Logout)
+
+	meGroup := apiGroup.Group("/me", handler.authenticate, handler.session)
+	meGroup.Get("/tokens", handler.apiKey.GetAPIKeys)
+	meGroup.Post("/tokens", handler.apiKey.CreateAPIKey)
+	meGroup.Delete("/tokens/:id", handler.apiKey.DeleteAPIKey)
+
+	workspaceGroup := apiGroup.Group("/workspaces", handler.authenticate, handler.session)
+	workspaceGroup.Get("", handler.workspace.GetWorkspaces)
+	workspaceGroup.Post("", handler.workspace.CreateWorkspace)
+	workspaceGroup.Post("/invitations/accept", handler.workspace.AcceptInvitation)
+	// every route below /workspaces/:id needs the caller to be a member
+	workspaceGroup.Use("/:id<int>", handler.workspaceMember)
+	workspaceGroup.Get("/:id/members", handler.workspace.GetMembers)
+	workspaceGroup.Delete("/:id/members/:userId", handler.manageMembers, handler.workspace.RemoveMember)
+	workspaceGroup.Post("/:id/invitations", handler.manageMembers, handler.workspace.CreateInvitation)
+	workspaceGroup.Post("/:id/leave", handler.workspace.LeaveWorkspace)
+
+	// browsers cannot set headers on streams
+	apiGroup.Use("/tasks/stream", handler.queryCredentials)
+
+	taskGroup := apiGroup.Group("/tasks", handler.authenticate, handler.taskScope, handler.currentWorkspace, handler.taskAccess)
+	// every route below /tasks/:id acts on a task of the current workspace
+	taskGroup.Use("/:id<int>", handler.taskWorkspace)
+	taskGroup.Post("", handler.idempotent, handler.task.CreateTask)
 	taskGroup.Get("", handler.task.GetTasks)
+	taskGroup.Get("/stream", handler.task.StreamTasks)
+	taskGroup.Get("/trash", handler.task.GetTrashedTasks)
+	taskGroup.Get("/:id", handler.task.GetTask)
 	taskGroup.Put("/:id", handler.task.UpdateTask)
+	taskGroup.Patch("/:id", handler.task.PatchTask)
+	taskGroup.Delete("/:id", handler.deleteTasks, handler.task.DeleteTask)
+	taskGroup.Post("/:id/restore", handler.task.RestoreTask)
+	taskGroup.Delete("/:id/purge", handler.deleteTasks, handler.task.PurgeTask)
+	taskGroup.Get("/:id/children", handler.task.GetChildren)
+	taskGroup.Get("/:id/tree", handler.task.GetTaskTree)
+	taskGroup.Put("/:id/parent", handler.task.MoveTask)
+	taskGroup.Get("/:id/dependencies", handler.task.GetDependencies)
+	taskGroup.Post("/:id/dependencies", handler.task.AddDependency)
+	taskGroup.Delete("/:id/dependencies/:blockerId", handler.task.RemoveDependency)
+	taskGroup.Put("/:id/project", handler.task.AssignProject)
+	taskGroup.Post("/:id/image", handler.image.UploadTaskImage)
+	taskGroup.Get("/:id/image", handler.image.GetTaskImage)
+	taskGroup.Delete("/:id/image", handler.image.DeleteTaskImage)
+	taskGroup.Get("/:id/thumbnail", handler.image.GetTaskThumbnail)
+	taskGroup.Get("/:id/attachments", handler.attachment.GetAttachments)
+	taskGroup.Post("/:id/attachments", handler.attachment.CreateAttachment)
+	taskGroup.Get("/:id/attachments/:attachmentId", handler.attachment.GetAttachment)
+	taskGroup.Delete("/:id/attachments/:attachmentId", handler.attachment.DeleteAttachment)
+	taskGroup.Get("/:id/comments", handler.comment.GetComments)
+	taskGroup.Post("/:id/comments", handler.comment.CreateComment)
+	taskGroup.Put("/:id/comments/:commentId", handler.comment.UpdateComment)
+	taskGroup.Delete("/:id/comments/:commentId", handler.comment.DeleteComment)
+	taskGroup.Post("/:id/share", handler.share.CreateShareLink)
+	taskGroup.Get("/:id/shares", handler.share.GetShareLinks)
+	taskGroup.Delete("/:id/shares/:shareId", handler.share.DeleteShareLink)
+
+	// shared links are public, the token is all the access they need
+	sharedGroup := apiGroup.Group("/shared")
+	sharedGroup.Get("/:token", handler.share.GetSharedTask)
+	sharedGroup.Post("/:token/comments", handler.share.CreateSharedComment)
+
+	// tags and projects belong to the current workspace like its tasks
+	tagGroup := apiGroup.Group("/tags", handler.authenticate, handler.taskScope, handler.currentWorkspace, handler.taskAccess)
+	tagGroup.Post("", handler.tag.CreateTag)
+	tagGroup.Get("", handler.tag.GetTags)
+	tagGroup.Get("/:id", handler.tag.GetTag)
+	tagGroup.Put("/:id", handler.tag.UpdateTag)
+	tagGroup.Delete("/:id", handler.tag.DeleteTag)
+	tagGroup.Post("/:id/merge", handler.tag.MergeTag)
+
+	projectGroup := apiGroup.Group("/projects", handler.authenticate, handler.taskScope, handler.currentWorkspace, handler.taskAccess)
+	projectGroup.Post("", handler.project.CreateProject)
+	projectGroup.Get("", handler.project.GetProjects)
+	projectGroup.Get("/:id", handler.project.GetProject)
+	projectGroup.Put("/:id", handler.project.UpdateProject)
+	projectGroup.Delete("/:id", handler.project.DeleteProject)
+	projectGroup.Get("/:id/tasks", handler.project.GetProjectTasks)
+	projectGroup.Post("/:id/tasks", handler.project.CreateProjectTask)
 }
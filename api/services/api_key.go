@@ -0,0 +1,129 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("api key is invalid or expired")
+)
+
+// APIKeyPrefix starts every API key, which tells them apart from access
+// tokens in the Authorization header.
+const APIKeyPrefix = "todo_"
+
+// lastUsedPrecision throttles the last-used updates of a busy key to one
+// write per interval.
+const lastUsedPrecision = time.Minute
+
+type APIKeyService interface {
+	CreateAPIKey(userID int, req request.CreatedAPIKeyRequest) (response.CreatedAPIKey, error)
+	GetAPIKeys(userID int) ([]entities.APIKey, error)
+	DeleteAPIKey(userID int, id int) error
+	// Authenticate returns the unexpired key matching token and records its
+	// use.
+	Authenticate(token string) (entities.APIKey, error)
+}
+
+type apiKeyService struct {
+	repository base.BaseRepository[any]
+	log        logger.Logger
+}
+
+func NewAPIKeyService(repository base.BaseRepository[any]) APIKeyService {
+	return &apiKeyService{
+		repository: repository,
+		log:        logger.WithPrefix("service/api_key"),
+	}
+}
+
+func (s apiKeyService) CreateAPIKey(userID int, req request.CreatedAPIKeyRequest) (response.CreatedAPIKey, error) {
+	secret, err := randomToken(32)
+	if err != nil {
+		return response.CreatedAPIKey{}, err
+	}
+	token := APIKeyPrefix + secret
+
+	scopes := slices.Clone(req.Scopes)
+	if len(scopes) == 0 {
+		scopes = slices.Clone(enum.Scopes)
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	key := entities.APIKey{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    token[:len(APIKeyPrefix)+6],
+		TokenHash: hashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: time.Now(),
+	}
+	err = s.repository.Create(&key).Error()
+	if err != nil {
+		return response.CreatedAPIKey{}, err
+	}
+
+	return response.CreatedAPIKey{APIKey: key, Token: token}, nil
+}
+
+func (s apiKeyService) GetAPIKeys(userID int) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	err := s.repository.Where("user_id = ?", userID).Order("created_at, id").Find(&keys).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s apiKeyService) DeleteAPIKey(userID int, id int) error {
+	result := s.repository.Where("id = ? AND user_id = ?", id, userID).Delete(&entities.APIKey{})
+	err := result.Error()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (s apiKeyService) Authenticate(token string) (entities.APIKey, error) {
+	tn := time.Now()
+
+	var key entities.APIKey
+	err := s.repository.Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", hashToken(token), tn).First(&key).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.APIKey{}, ErrInvalidAPIKey
+		}
+		return entities.APIKey{}, err
+	}
+
+	if key.LastUsedAt == nil || tn.Sub(*key.LastUsedAt) >= lastUsedPrecision {
+		err = s.repository.Model(&entities.APIKey{}).Where("id = ?", key.ID).Update("last_used_at", tn).Error()
+		if err != nil {
+			// a missed timestamp is no reason to fail the request
+			s.log.Wrap("record use of api key %d failed: %v", key.ID, err).Error()
+		} else {
+			key.LastUsedAt = &tn
+		}
+	}
+
+	return key, nil
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func Test_apiKeyService_CreateAPIKey(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const insertQuery = `INSERT INTO "api_keys" \("user_id","name","prefix","token_hash","scopes","expires_at","last_used_at","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) RETURNING "id"`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		req request.CreatedAPIKeyRequest
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantScopes []enum.Scope
+		wantErr    bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(7, "ci", sqlmock.AnyArg(), sqlmock.AnyArg(), `["tasks:read"]`, nil, nil, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedAPIKeyRequest{Name: " ci ", Scopes: []enum.Scope{enum.ScopeTasksRead, enum.ScopeTasksRead}},
+			},
+			wantScopes: []enum.Scope{enum.ScopeTasksRead},
+			wantErr:    false,
+		},
+		{
+			name: "success with all scopes",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(7, "ci", sqlmock.AnyArg(), sqlmock.AnyArg(), `["tasks:read","tasks:write"]`, nil, nil, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedAPIKeyRequest{Name: "ci"},
+			},
+			wantScopes: []enum.Scope{enum.ScopeTasksRead, enum.ScopeTasksWrite},
+			wantErr:    false,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.CreatedAPIKeyRequest{Name: "ci"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := apiKeyService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.CreateAPIKey(7, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("apiKeyService.CreateAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(got.Token, APIKeyPrefix) || !strings.HasPrefix(got.Token, got.Prefix) {
+				t.Errorf("apiKeyService.CreateAPIKey() token = %v, prefix = %v", got.Token, got.Prefix)
+			}
+			if got.TokenHash != hashToken(got.Token) {
+				t.Errorf("apiKeyService.CreateAPIKey() token hash does not match the token")
+			}
+			if !reflect.DeepEqual(got.Scopes, tt.wantScopes) {
+				t.Errorf("apiKeyService.CreateAPIKey() scopes = %v, want %v", got.Scopes, tt.wantScopes)
+			}
+		})
+	}
+}
+
+func Test_apiKeyService_GetAPIKeys(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.APIKey
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					rows := sqlmock.NewRows([]string{"id", "user_id", "name", "prefix", "token_hash", "scopes", "created_at"}).
+						AddRow(1, 7, "ci", "todo_abcdef", "hash", `["tasks:read"]`, tn)
+					mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE user_id = \$1 ORDER BY created_at, id`).
+						WithArgs(7).
+						WillReturnRows(rows)
+				},
+			},
+			want: []entities.APIKey{{
+				ID:        1,
+				UserID:    7,
+				Name:      "ci",
+				Prefix:    "todo_abcdef",
+				TokenHash: "hash",
+				Scopes:    []enum.Scope{enum.ScopeTasksRead},
+				CreatedAt: tn,
+			}},
+			wantErr: false,
+		},
+		{
+			name: "find failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := apiKeyService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetAPIKeys(7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("apiKeyService.GetAPIKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apiKeyService.GetAPIKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_apiKeyService_DeleteAPIKey(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const deleteQuery = `DELETE FROM "api_keys" WHERE id = \$1 AND user_id = \$2`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(deleteQuery).
+						WithArgs(1, 7).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "key of another user",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(deleteQuery).
+						WithArgs(1, 7).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: ErrAPIKeyNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := apiKeyService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.DeleteAPIKey(7, 1)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("apiKeyService.DeleteAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_apiKeyService_Authenticate(t *testing.T) {
+	tn := time.Now()
+	recent := tn.Add(-10 * time.Second)
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		findQuery  = `SELECT \* FROM "api_keys" WHERE token_hash = \$1 AND \(expires_at IS NULL OR expires_at > \$2\) ORDER BY "api_keys"."id" LIMIT \$3`
+		touchQuery = `UPDATE "api_keys" SET "last_used_at"=\$1 WHERE id = \$2`
+	)
+	columns := []string{"id", "user_id", "name", "prefix", "token_hash", "scopes", "last_used_at", "created_at"}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name         string
+		fields       fields
+		wantUserID   int
+		wantLastUsed bool
+		wantErr      error
+	}{
+		{
+			name: "first use",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).
+						WithArgs(hashToken("todo_key"), sqlmock.AnyArg(), 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 7, "ci", "todo_abcdef", hashToken("todo_key"), `["tasks:read"]`, nil, tn))
+					mock.ExpectBegin()
+					mock.ExpectExec(touchQuery).
+						WithArgs(sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantUserID:   7,
+			wantLastUsed: true,
+			wantErr:      nil,
+		},
+		{
+			name: "recently used",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 7, "ci", "todo_abcdef", hashToken("todo_key"), `["tasks:read"]`, recent, tn))
+				},
+			},
+			wantUserID:   7,
+			wantLastUsed: true,
+			wantErr:      nil,
+		},
+		{
+			name: "recording use failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 7, "ci", "todo_abcdef", hashToken("todo_key"), `["tasks:read"]`, nil, tn))
+					mock.ExpectBegin()
+					mock.ExpectExec(touchQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			wantUserID:   7,
+			wantLastUsed: false,
+			wantErr:      nil,
+		},
+		{
+			name: "unknown or expired key",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WillReturnRows(sqlmock.NewRows(columns))
+				},
+			},
+			wantErr: ErrInvalidAPIKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := apiKeyService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.Authenticate("todo_key")
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("apiKeyService.Authenticate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.UserID != tt.wantUserID {
+				t.Errorf("apiKeyService.Authenticate() user = %v, want %v", got.UserID, tt.wantUserID)
+			}
+			if (got.LastUsedAt != nil) != tt.wantLastUsed {
+				t.Errorf("apiKeyService.Authenticate() last used = %v, want set %v", got.LastUsedAt, tt.wantLastUsed)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
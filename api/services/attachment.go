@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+	"todo/pkg/storage"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrAttachmentLimit    = errors.New("task has reached the attachment limit")
+	ErrAttachmentTooLarge = errors.New("attachments exceed the size limit of the task")
+)
+
+const (
+	DefaultAttachmentsPerTask     = 20
+	DefaultAttachmentBytesPerTask = 100 << 20
+)
+
+// Attachments sharing content are serialized per SHA-256 with a two-key
+// advisory lock, so a blob is never deleted while another upload is about
+// to reference it.
+const attachmentLockKey = 0x7461736d
+
+type AttachmentLimits struct {
+	MaxPerTask    int
+	MaxTotalBytes int64
+}
+
+type AttachmentService interface {
+	GetAttachments(workspaceID int, taskID int) ([]entities.Attachment, error)
+	CreateAttachment(workspaceID int, taskID int, upload request.UploadedAttachment) error
+	// GetAttachment returns the attachment and its content, which the
+	// caller must close.
+	GetAttachment(taskID int, id int) (entities.Attachment, io.ReadCloser, error)
+	DeleteAttachment(taskID int, id int) error
+}
+
+type attachmentService struct {
+	repository base.BaseRepository[any]
+	store      storage.BlobStore
+	limits     AttachmentLimits
+	log        logger.Logger
+}
+
+func NewAttachmentService(repository base.BaseRepository[any], store storage.BlobStore, limits AttachmentLimits) AttachmentService {
+	if limits.MaxPerTask <= 0 {
+		limits.MaxPerTask = DefaultAttachmentsPerTask
+	}
+	if limits.MaxTotalBytes <= 0 {
+		limits.MaxTotalBytes = DefaultAttachmentBytesPerTask
+	}
+
+	return &attachmentService{
+		repository: repository,
+		store:      store,
+		limits:     limits,
+		log:        logger.WithPrefix("service/attachment"),
+	}
+}
+
+func (s attachmentService) GetAttachments(workspaceID int, taskID int) ([]entities.Attachment, error) {
+	err := s.checkTask(workspaceID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []entities.Attachment{}
+	err = s.repository.Where("task_id = ?", taskID).Order("created_at, id").Find(&attachments).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// CreateAttachment spools the upload to a temporary file to hash it, then
+// stores the content only if no other attachment already has it.
+func (s attachmentService) CreateAttachment(workspaceID int, taskID int, upload request.UploadedAttachment) error {
+	if upload.Size > s.limits.MaxTotalBytes {
+		return ErrAttachmentTooLarge
+	}
+
+	file, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(file, hash), io.LimitReader(upload.Reader, s.limits.MaxTotalBytes+1))
+	if err != nil {
+		return err
+	}
+	if size > s.limits.MaxTotalBytes {
+		return ErrAttachmentTooLarge
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	contentType, err := attachmentContentType(upload.ContentType, file)
+	if err != nil {
+		return err
+	}
+
+	return database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		// locking the task serializes uploads to it, so two of them can't
+		// pass the limit checks together
+		var task entities.Task
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", taskID).First(&task).Error()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+
+		var usage struct {
+			Count int64
+			Total int64
+		}
+		err = repository.Model(&entities.Attachment{}).Select("count(*) AS count, coalesce(sum(size), 0) AS total").Where("task_id = ?", taskID).Scan(&usage).Error()
+		if err != nil {
+			return err
+		}
+		if usage.Count >= int64(s.limits.MaxPerTask) {
+			return ErrAttachmentLimit
+		}
+		if usage.Total+size > s.limits.MaxTotalBytes {
+			return ErrAttachmentTooLarge
+		}
+
+		key := "attachments/" + sum
+		err = lockAttachmentContent(repository, sum)
+		if err != nil {
+			return err
+		}
+		var references int64
+		err = repository.Model(&entities.Attachment{}).Where("sha256 = ?", sum).Count(&references).Error()
+		if err != nil {
+			return err
+		}
+		if references == 0 {
+			_, err = file.Seek(0, io.SeekStart)
+			if err != nil {
+				return err
+			}
+			err = s.store.Put(context.Background(), key, file, size, "application/octet-stream")
+			if err != nil {
+				return err
+			}
+		}
+
+		err = repository.Create(&entities.Attachment{
+			TaskID:      taskID,
+			Filename:    cleanFilename(upload.Filename),
+			ContentType: contentType,
+			Size:        size,
+			SHA256:      sum,
+			StorageKey:  key,
+			CreatedAt:   time.Now(),
+		}).Error()
+		if err != nil && references == 0 {
+			s.deleteBlob(key)
+		}
+
+		return err
+	})
+}
+
+func (s attachmentService) GetAttachment(taskID int, id int) (entities.Attachment, io.ReadCloser, error) {
+	attachment, err := s.findAttachment(s.repository, taskID, id)
+	if err != nil {
+		return entities.Attachment{}, nil, err
+	}
+
+	reader, _, err := s.store.Get(context.Background(), attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return entities.Attachment{}, nil, ErrAttachmentNotFound
+		}
+		return entities.Attachment{}, nil, err
+	}
+
+	return attachment, reader, nil
+}
+
+// DeleteAttachment removes the content along with its last attachment. The
+// content goes once the deletion has committed, so a rollback never leaves
+// an attachment without it.
+func (s attachmentService) DeleteAttachment(taskID int, id int) error {
+	var attachment entities.Attachment
+	var orphaned bool
+	err := s.repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+		var err error
+		attachment, err = s.findAttachment(repository, taskID, id)
+		if err != nil {
+			return err
+		}
+
+		err = lockAttachmentContent(repository, attachment.SHA256)
+		if err != nil {
+			return err
+		}
+		err = repository.Where("id = ?", id).Delete(&entities.Attachment{}).Error()
+		if err != nil {
+			return err
+		}
+
+		var references int64
+		err = repository.Model(&entities.Attachment{}).Where("sha256 = ?", attachment.SHA256).Count(&references).Error()
+		if err != nil {
+			return err
+		}
+		orphaned = references == 0
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// best effort: a leftover blob is only wasted space. The references
+	// are counted again, as an upload may have reused the content since.
+	if orphaned {
+		err = deleteAttachmentContent(s.repository, s.store, attachment.SHA256, attachment.StorageKey)
+		if err != nil {
+			s.log.Wrap("delete blob %s failed: %v", attachment.StorageKey, err).Error()
+		}
+	}
+
+	return nil
+}
+
+func (s attachmentService) checkTask(workspaceID int, taskID int) error {
+	var count int64
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Model(&entities.Task{}).Where("id = ?", taskID).Count(&count).Error()
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+func (s attachmentService) findAttachment(repository base.BaseRepository[any], taskID int, id int) (entities.Attachment, error) {
+	var attachment entities.Attachment
+	err := repository.Where("id = ? AND task_id = ?", id, taskID).First(&attachment).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Attachment{}, ErrAttachmentNotFound
+		}
+		return entities.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+// deleteBlob is best effort: a leftover blob is only wasted space.
+func (s attachmentService) deleteBlob(key string) {
+	err := s.store.Delete(context.Background(), key)
+	if err != nil {
+		s.log.Wrap("delete blob %s failed: %v", key, err).Error()
+	}
+}
+
+func lockAttachmentContent(repository base.BaseRepository[any], sum string) error {
+	return repository.Exec("SELECT pg_advisory_xact_lock(?, hashtext(?))", attachmentLockKey, sum).Error()
+}
+
+// deleteAttachmentContent deletes the content once attachments referencing
+// it have been deleted along with their tasks, unless another one does.
+func deleteAttachmentContent(repository base.BaseRepository[any], store storage.BlobStore, sum string, key string) error {
+	return repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+		err := lockAttachmentContent(repository, sum)
+		if err != nil {
+			return err
+		}
+
+		var references int64
+		err = repository.Model(&entities.Attachment{}).Where("sha256 = ?", sum).Count(&references).Error()
+		if err != nil {
+			return err
+		}
+		if references > 0 {
+			return nil
+		}
+
+		return store.Delete(context.Background(), key)
+	})
+}
+
+// contentType keeps a well-formed declared type and sniffs the content
+// otherwise.
+func attachmentContentType(declared string, file *os.File) (string, error) {
+	if mediaType, params, err := mime.ParseMediaType(declared); err == nil {
+		return mime.FormatMediaType(mediaType, params), nil
+	}
+
+	head := make([]byte, 512)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return http.DetectContentType(head[:n]), nil
+}
+
+// cleanFilename drops directories and control characters and caps the
+// name at 255 bytes.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "." || name == "/" || len(strings.TrimSpace(name)) == 0 {
+		return "file"
+	}
+
+	return name
+}
@@ -0,0 +1,503 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+	"todo/pkg/storage"
+	storagemock "todo/pkg/storage/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+)
+
+// helloSHA256 is the SHA-256 of "hello".
+const helloSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func Test_attachmentService_GetAttachments(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.Attachment
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					rows := sqlmock.NewRows([]string{"id", "task_id", "filename", "content_type", "size", "sha256", "storage_key", "created_at"}).
+						AddRow(2, 1, "spec.pdf", "application/pdf", 5, helloSHA256, "attachments/"+helloSHA256, tn)
+					mock.ExpectQuery(`SELECT \* FROM "attachments" WHERE task_id = \$1 ORDER BY created_at, id`).
+						WithArgs(1).
+						WillReturnRows(rows)
+				},
+			},
+			want: []entities.Attachment{{
+				ID:          2,
+				TaskID:      1,
+				Filename:    "spec.pdf",
+				ContentType: "application/pdf",
+				Size:        5,
+				SHA256:      helloSHA256,
+				StorageKey:  "attachments/" + helloSHA256,
+				CreatedAt:   tn,
+				URL:         "/api/tasks/1/attachments/2",
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+				},
+			},
+			want:    nil,
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := attachmentService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetAttachments(3, 1)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("attachmentService.GetAttachments() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("attachmentService.GetAttachments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_attachmentService_CreateAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		lockTaskQuery  = `SELECT "id" FROM "tasks" WHERE id = \$1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT \$2 FOR UPDATE`
+		usageQuery     = `SELECT count\(\*\) AS count, coalesce\(sum\(size\), 0\) AS total FROM "attachments" WHERE task_id = \$1`
+		lockContent    = `SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`
+		referenceQuery = `SELECT count\(\*\) FROM "attachments" WHERE sha256 = \$1`
+		insertQuery    = `INSERT INTO "attachments" \("task_id","filename","content_type","size","sha256","storage_key","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) RETURNING "id"`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		limits             AttachmentLimits
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		upload  request.UploadedAttachment
+		wantErr error
+	}{
+		{
+			name: "success stores new content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(usageQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count", "total"}).AddRow(1, 5))
+					mock.ExpectExec(lockContent).WithArgs(attachmentLockKey, helloSHA256).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(referenceQuery).WithArgs(helloSHA256).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mbs.EXPECT().Put(gomock.Any(), "attachments/"+helloSHA256, gomock.Any(), int64(5), "application/octet-stream").
+						DoAndReturn(func(_ interface{}, _ string, r io.Reader, _ int64, _ string) error {
+							content, _ := io.ReadAll(r)
+							if string(content) != "hello" {
+								t.Errorf("content = %s", content)
+							}
+							return nil
+						})
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, "notes.txt", "text/plain; charset=utf-8", 5, helloSHA256, "attachments/"+helloSHA256, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: `C:\logs\notes.txt`, Size: 5},
+			wantErr: nil,
+		},
+		{
+			name: "success reuses stored content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(usageQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "total"}).AddRow(0, 0))
+					mock.ExpectExec(lockContent).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, "a.log", "text/x-log", 5, helloSHA256, "attachments/"+helloSHA256, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 5, ContentType: "text/x-log"},
+			wantErr: nil,
+		},
+		{
+			name: "attachment limit reached",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(usageQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "total"}).AddRow(2, 2))
+					mock.ExpectRollback()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 5},
+			wantErr: ErrAttachmentLimit,
+		},
+		{
+			name: "total size exceeded",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(usageQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "total"}).AddRow(1, 6))
+					mock.ExpectRollback()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 5},
+			wantErr: ErrAttachmentTooLarge,
+		},
+		{
+			name: "file larger than the limit",
+			fields: fields{
+				repository:         base.NewBaseRepository[any](db),
+				store:              storagemock.NewMockBlobStore(ctrl),
+				limits:             AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 4},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {},
+			},
+			// the declared size can't be trusted, the read is capped too
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 1},
+			wantErr: ErrAttachmentTooLarge,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 5},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "insert failed removes new content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				limits:     AttachmentLimits{MaxPerTask: 2, MaxTotalBytes: 10},
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(lockTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(usageQuery).WillReturnRows(sqlmock.NewRows([]string{"count", "total"}).AddRow(0, 0))
+					mock.ExpectExec(lockContent).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mbs.EXPECT().Put(gomock.Any(), "attachments/"+helloSHA256, gomock.Any(), int64(5), gomock.Any())
+					mock.ExpectQuery(insertQuery).WillReturnError(errors.New("foo"))
+					mbs.EXPECT().Delete(gomock.Any(), "attachments/"+helloSHA256)
+					mock.ExpectRollback()
+				},
+			},
+			upload:  request.UploadedAttachment{Reader: strings.NewReader("hello"), Filename: "a.log", Size: 5},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := attachmentService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				limits:     tt.fields.limits,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.CreateAttachment(3, 1, tt.upload); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("attachmentService.CreateAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_attachmentService_GetAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const selectAttachmentQuery = `SELECT \* FROM "attachments" WHERE id = \$1 AND task_id = \$2 ORDER BY "attachments"."id" LIMIT \$3`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    string
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectQuery(selectAttachmentQuery).
+						WithArgs(2, 1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "filename", "storage_key"}).AddRow(2, 1, "spec.pdf", "attachments/"+helloSHA256))
+					mbs.EXPECT().Get(gomock.Any(), "attachments/"+helloSHA256).
+						Return(io.NopCloser(strings.NewReader("hello")), storage.Object{Size: 5}, nil)
+				},
+			},
+			want:    "spec.pdf",
+			wantErr: nil,
+		},
+		{
+			name: "attachment not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectQuery(selectAttachmentQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				},
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+		{
+			name: "content missing",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectQuery(selectAttachmentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "storage_key"}).AddRow(2, 1, "attachments/"+helloSHA256))
+					mbs.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, storage.Object{}, storage.ErrNotFound)
+				},
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := attachmentService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, reader, err := s.GetAttachment(1, 2)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("attachmentService.GetAttachment() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if reader != nil {
+				reader.Close()
+			}
+			if got.Filename != tt.want {
+				t.Errorf("attachmentService.GetAttachment() = %v, want %v", got.Filename, tt.want)
+			}
+		})
+	}
+}
+
+func Test_attachmentService_DeleteAttachment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		selectAttachmentQuery = `SELECT \* FROM "attachments" WHERE id = \$1 AND task_id = \$2 ORDER BY "attachments"."id" LIMIT \$3`
+		referenceQuery        = `SELECT count\(\*\) FROM "attachments" WHERE sha256 = \$1`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "success removes last copy of the content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectAttachmentQuery).
+						WithArgs(2, 1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "sha256", "storage_key"}).AddRow(2, 1, helloSHA256, "attachments/"+helloSHA256))
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`).
+						WithArgs(attachmentLockKey, helloSHA256).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`DELETE FROM "attachments" WHERE id = \$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(referenceQuery).WithArgs(helloSHA256).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+					// the content goes after the commit, in a transaction
+					// of its own
+					mock.ExpectBegin()
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`).
+						WithArgs(attachmentLockKey, helloSHA256).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(referenceQuery).WithArgs(helloSHA256).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mbs.EXPECT().Delete(gomock.Any(), "attachments/"+helloSHA256)
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "content reused after the commit",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectAttachmentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "sha256", "storage_key"}).AddRow(2, 1, helloSHA256, "attachments/"+helloSHA256))
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`DELETE FROM "attachments"`).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "commit failed keeps the content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectAttachmentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "sha256", "storage_key"}).AddRow(2, 1, helloSHA256, "attachments/"+helloSHA256))
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`DELETE FROM "attachments"`).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit().WillReturnError(errors.New("foo"))
+				},
+			},
+			wantErr: errors.New("foo"),
+		},
+		{
+			name: "success keeps shared content",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectAttachmentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "sha256", "storage_key"}).AddRow(2, 1, helloSHA256, "attachments/"+helloSHA256))
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`DELETE FROM "attachments"`).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(referenceQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "attachment not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(selectAttachmentQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrAttachmentNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := attachmentService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteAttachment(1, 2); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("attachmentService.DeleteAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
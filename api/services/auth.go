@@ -0,0 +1,217 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/auth"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrEmailTaken           = errors.New("email is already registered")
+	ErrInvalidCredentials   = errors.New("email or password is incorrect")
+	ErrInvalidRefreshToken  = errors.New("refresh token is invalid")
+	ErrRefreshTokenReplayed = errors.New("refresh token has already been used")
+)
+
+// dummyHash is compared against when the email is unknown, so a login for
+// an unknown email takes as long as one with a wrong password.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+type AuthService interface {
+	Register(req request.RegisterRequest) error
+	Login(req request.LoginRequest) (response.AuthTokens, error)
+	Refresh(refreshToken string) (response.AuthTokens, error)
+	Logout(refreshToken string) error
+}
+
+type authService struct {
+	repository base.BaseRepository[any]
+	tokens     auth.Tokens
+	cost       int
+	log        logger.Logger
+}
+
+func NewAuthService(repository base.BaseRepository[any], tokens auth.Tokens) AuthService {
+	return &authService{
+		repository: repository,
+		tokens:     tokens,
+		cost:       bcrypt.DefaultCost,
+		log:        logger.WithPrefix("service/auth"),
+	}
+}
+
+func (s authService) Register(req request.RegisterRequest) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.cost)
+	if err != nil {
+		return err
+	}
+
+	tn := time.Now()
+	user := entities.User{
+		Email:        normalizeEmail(req.Email),
+		PasswordHash: string(hash),
+		CreatedAt:    tn,
+		UpdatedAt:    tn,
+	}
+
+	err = s.repository.Create(&user).Error()
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrEmailTaken
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s authService) Login(req request.LoginRequest) (response.AuthTokens, error) {
+	var user entities.User
+	err := s.repository.Where("email = ?", normalizeEmail(req.Email)).First(&user).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			bcrypt.CompareHashAndPassword(dummyHash(), []byte(req.Password))
+			return response.AuthTokens{}, ErrInvalidCredentials
+		}
+		return response.AuthTokens{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if err != nil {
+		return response.AuthTokens{}, ErrInvalidCredentials
+	}
+
+	family, err := randomToken(16)
+	if err != nil {
+		return response.AuthTokens{}, err
+	}
+
+	return s.issueTokens(s.repository, user.ID, family, time.Now())
+}
+
+// Refresh exchanges a refresh token for a new pair. The presented token is
+// revoked; presenting it again revokes every token of its family.
+func (s authService) Refresh(refreshToken string) (response.AuthTokens, error) {
+	var tokens response.AuthTokens
+	replayed := false
+	err := s.repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+
+		var current entities.RefreshToken
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).Where("token_hash = ?", hashToken(refreshToken)).First(&current).Error()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		tn := time.Now()
+		if current.RevokedAt != nil {
+			err = revokeFamily(repository, current.FamilyID, tn)
+			if err != nil {
+				return err
+			}
+			// commit the revocation, the caller still gets an error
+			s.log.Wrap("refresh token of user %d replayed, family revoked", current.UserID).Warn()
+			replayed = true
+			return nil
+		}
+		if !tn.Before(current.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		err = repository.Model(&entities.RefreshToken{}).Where("id = ?", current.ID).Update("revoked_at", tn).Error()
+		if err != nil {
+			return err
+		}
+
+		tokens, err = s.issueTokens(repository, current.UserID, current.FamilyID, tn)
+		return err
+	})
+	if err != nil {
+		return response.AuthTokens{}, err
+	}
+	if replayed {
+		return response.AuthTokens{}, ErrRefreshTokenReplayed
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes the refresh token and every other token of its family.
+// Access tokens already issued stay valid until they expire.
+func (s authService) Logout(refreshToken string) error {
+	return s.repository.Exec(`UPDATE refresh_tokens SET revoked_at = ?
+		WHERE revoked_at IS NULL AND family_id = (SELECT family_id FROM refresh_tokens WHERE token_hash = ?)`,
+		time.Now(), hashToken(refreshToken)).Error()
+}
+
+func (s authService) issueTokens(repository base.BaseRepository[any], userID int, family string, tn time.Time) (response.AuthTokens, error) {
+	access, expiresAt, err := s.tokens.Issue(userID)
+	if err != nil {
+		return response.AuthTokens{}, err
+	}
+
+	refresh, err := randomToken(32)
+	if err != nil {
+		return response.AuthTokens{}, err
+	}
+
+	err = repository.Create(&entities.RefreshToken{
+		UserID:    userID,
+		FamilyID:  family,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: tn.Add(s.tokens.RefreshTTL()),
+		CreatedAt: tn,
+	}).Error()
+	if err != nil {
+		return response.AuthTokens{}, err
+	}
+
+	return response.AuthTokens{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(expiresAt.Sub(tn).Round(time.Second).Seconds()),
+		RefreshToken: refresh,
+	}, nil
+}
+
+func revokeFamily(repository base.BaseRepository[any], family string, tn time.Time) error {
+	return repository.Model(&entities.RefreshToken{}).Where("family_id = ? AND revoked_at IS NULL", family).Update("revoked_at", tn).Error()
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func randomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
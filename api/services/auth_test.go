@@ -0,0 +1,396 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	authmock "todo/pkg/auth/mock"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func Test_authService_Register(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const insertQuery = `INSERT INTO "users" \("email","password_hash","created_at","updated_at"\) VALUES \(\$1,\$2,\$3,\$4\) RETURNING "id"`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		req request.RegisterRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs("jane@example.com", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.RegisterRequest{Email: " Jane@Example.com", Password: "password"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "email taken",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).WillReturnError(&pgconn.PgError{Code: "23505"})
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.RegisterRequest{Email: "jane@example.com", Password: "password"},
+			},
+			wantErr: ErrEmailTaken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := authService{
+				repository: tt.fields.repository,
+				cost:       bcrypt.MinCost,
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.Register(tt.args.req)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("authService.Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_authService_Login(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		findQuery   = `SELECT \* FROM "users" WHERE email = \$1 ORDER BY "users"."id" LIMIT \$2`
+		insertQuery = `INSERT INTO "refresh_tokens" \("user_id","family_id","token_hash","expires_at","revoked_at","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6\) RETURNING "id"`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		tokens             *authmock.MockTokens
+		repositoryBehavior func(*authmock.MockTokens)
+	}
+	type args struct {
+		req request.LoginRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    response.AuthTokens
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectQuery(findQuery).
+						WithArgs("jane@example.com", 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash"}).AddRow(1, "jane@example.com", string(hash)))
+					mt.EXPECT().Issue(1).DoAndReturn(func(int) (string, time.Time, error) {
+						return "access", time.Now().Add(15 * time.Minute), nil
+					})
+					mt.EXPECT().RefreshTTL().Return(time.Hour)
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.LoginRequest{Email: "Jane@example.com", Password: "password"},
+			},
+			want:    response.AuthTokens{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900},
+			wantErr: nil,
+		},
+		{
+			name: "wrong password",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash"}).AddRow(1, "jane@example.com", string(hash)))
+				},
+			},
+			args: args{
+				req: request.LoginRequest{Email: "jane@example.com", Password: "wrong password"},
+			},
+			want:    response.AuthTokens{},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "unknown email",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash"}))
+				},
+			},
+			args: args{
+				req: request.LoginRequest{Email: "john@example.com", Password: "password"},
+			},
+			want:    response.AuthTokens{},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name: "find user failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectQuery(findQuery).WillReturnError(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: request.LoginRequest{Email: "jane@example.com", Password: "password"},
+			},
+			want:    response.AuthTokens{},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.tokens)
+			s := authService{
+				repository: tt.fields.repository,
+				tokens:     tt.fields.tokens,
+				cost:       bcrypt.MinCost,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.Login(tt.args.req)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("authService.Login() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && len(got.RefreshToken) == 0 {
+				t.Errorf("authService.Login() refresh token is empty")
+			}
+			got.RefreshToken = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("authService.Login() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_authService_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		findQuery   = `SELECT \* FROM "refresh_tokens" WHERE token_hash = \$1 ORDER BY "refresh_tokens"."id" LIMIT \$2 FOR UPDATE`
+		revokeQuery = `UPDATE "refresh_tokens" SET "revoked_at"=\$1 WHERE id = \$2`
+		familyQuery = `UPDATE "refresh_tokens" SET "revoked_at"=\$1 WHERE family_id = \$2 AND revoked_at IS NULL`
+		insertQuery = `INSERT INTO "refresh_tokens" (.+) RETURNING "id"`
+	)
+	columns := []string{"id", "user_id", "family_id", "token_hash", "expires_at", "revoked_at", "created_at"}
+	tn := time.Now()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		tokens             *authmock.MockTokens
+		repositoryBehavior func(*authmock.MockTokens)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).
+						WithArgs(hashToken("refresh"), 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 1, "family", hashToken("refresh"), tn.Add(time.Hour), nil, tn))
+					mock.ExpectExec(revokeQuery).
+						WithArgs(sqlmock.AnyArg(), 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mt.EXPECT().Issue(1).Return("access", tn.Add(15*time.Minute), nil)
+					mt.EXPECT().RefreshTTL().Return(time.Hour)
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, "family", sqlmock.AnyArg(), sqlmock.AnyArg(), nil, sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "unknown token",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).WillReturnRows(sqlmock.NewRows(columns))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "expired token",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 1, "family", hashToken("refresh"), tn.Add(-time.Hour), nil, tn))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "replayed token revokes the family",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				tokens:     authmock.NewMockTokens(ctrl),
+				repositoryBehavior: func(mt *authmock.MockTokens) {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 1, "family", hashToken("refresh"), tn.Add(time.Hour), tn, tn))
+					mock.ExpectExec(familyQuery).
+						WithArgs(sqlmock.AnyArg(), "family").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: ErrRefreshTokenReplayed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.tokens)
+			s := authService{
+				repository: tt.fields.repository,
+				tokens:     tt.fields.tokens,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.Refresh("refresh")
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("authService.Refresh() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && (got.AccessToken != "access" || len(got.RefreshToken) == 0 || got.RefreshToken == "refresh") {
+				t.Errorf("authService.Refresh() = %v, want a new pair", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_authService_Logout(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const logoutQuery = `UPDATE refresh_tokens SET revoked_at = \$1\s+WHERE revoked_at IS NULL AND family_id = \(SELECT family_id FROM refresh_tokens WHERE token_hash = \$2\)`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectExec(logoutQuery).
+						WithArgs(sqlmock.AnyArg(), hashToken("refresh")).
+						WillReturnResult(sqlmock.NewResult(0, 2))
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "revoke failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectExec(logoutQuery).WillReturnError(errors.New("foo"))
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := authService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			if err := s.Logout("refresh"); (err != nil) != tt.wantErr {
+				t.Errorf("authService.Logout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
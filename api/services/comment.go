@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentForbidden = errors.New("comment belongs to another author")
+)
+
+type CommentService interface {
+	GetComments(workspaceID int, taskID int) ([]entities.Comment, error)
+	CreateComment(workspaceID int, taskID int, authorID int, req request.CreatedCommentRequest) error
+	CreateGuestComment(workspaceID int, taskID int, req request.CreatedSharedCommentRequest) error
+	UpdateComment(taskID int, id int, authorID int, req request.UpdatedCommentRequest) error
+	DeleteComment(taskID int, id int, authorID int) error
+}
+
+type commentService struct {
+	repository base.BaseRepository[any]
+	log        logger.Logger
+}
+
+func NewCommentService(repository base.BaseRepository[any]) CommentService {
+	return &commentService{
+		repository: repository,
+		log:        logger.WithPrefix("service/comment"),
+	}
+}
+
+func (s commentService) GetComments(workspaceID int, taskID int) ([]entities.Comment, error) {
+	err := s.checkTask(workspaceID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []entities.Comment{}
+	err = s.repository.Where("task_id = ?", taskID).Order("created_at, id").Find(&comments).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// CreateComment comments as the user authorID, who is named by their email.
+func (s commentService) CreateComment(workspaceID int, taskID int, authorID int, req request.CreatedCommentRequest) error {
+	err := s.checkTask(workspaceID, taskID)
+	if err != nil {
+		return err
+	}
+
+	var author entities.User
+	err = s.repository.Select("email").First(&author, authorID).Error()
+	if err != nil {
+		return err
+	}
+
+	tn := time.Now()
+	comment := entities.Comment{
+		TaskID:    taskID,
+		AuthorID:  &authorID,
+		Author:    author.Email,
+		Body:      strings.TrimSpace(req.Body),
+		CreatedAt: tn,
+		UpdatedAt: tn,
+	}
+
+	return s.repository.Create(&comment).Error()
+}
+
+// CreateGuestComment comments as someone without an account. The comment has
+// no AuthorID, so nobody can edit or delete it, whatever name the guest gave.
+func (s commentService) CreateGuestComment(workspaceID int, taskID int, req request.CreatedSharedCommentRequest) error {
+	err := s.checkTask(workspaceID, taskID)
+	if err != nil {
+		return err
+	}
+
+	tn := time.Now()
+	comment := entities.Comment{
+		TaskID:    taskID,
+		Author:    strings.TrimSpace(req.Author),
+		Body:      strings.TrimSpace(req.Body),
+		Guest:     true,
+		CreatedAt: tn,
+		UpdatedAt: tn,
+	}
+
+	return s.repository.Create(&comment).Error()
+}
+
+// UpdateComment replaces the body of one of the author's own comments and
+// marks it as edited.
+func (s commentService) UpdateComment(taskID int, id int, authorID int, req request.UpdatedCommentRequest) error {
+	result := s.repository.Model(&entities.Comment{}).Where("id = ? AND task_id = ? AND author_id = ?", id, taskID, authorID).Updates(map[string]interface{}{
+		"body":       strings.TrimSpace(req.Body),
+		"edited":     true,
+		"updated_at": time.Now(),
+	})
+	err := result.Error()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return s.unmodifiedError(taskID, id)
+	}
+
+	return nil
+}
+
+func (s commentService) DeleteComment(taskID int, id int, authorID int) error {
+	result := s.repository.Where("id = ? AND task_id = ? AND author_id = ?", id, taskID, authorID).Delete(&entities.Comment{})
+	err := result.Error()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return s.unmodifiedError(taskID, id)
+	}
+
+	return nil
+}
+
+func (s commentService) checkTask(workspaceID int, taskID int) error {
+	var count int64
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Model(&entities.Task{}).Where("id = ?", taskID).Count(&count).Error()
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+// unmodifiedError tells apart a missing comment from someone else's once a
+// write restricted to the author has touched no rows.
+func (s commentService) unmodifiedError(taskID int, id int) error {
+	var count int64
+	err := s.repository.Model(&entities.Comment{}).Where("id = ? AND task_id = ?", id, taskID).Count(&count).Error()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCommentNotFound
+	}
+
+	return ErrCommentForbidden
+}
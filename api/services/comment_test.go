@@ -0,0 +1,448 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/base/mock"
+	"todo/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+)
+
+func Test_commentService_GetComments(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		taskID int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []entities.Comment
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					rows := sqlmock.NewRows([]string{"id", "task_id", "author", "body", "edited", "created_at", "updated_at"}).
+						AddRow(1, 1, "alice", "foo", true, tn, tn)
+					mock.ExpectQuery(`SELECT \* FROM "comments" WHERE task_id = \$1 ORDER BY created_at, id`).
+						WithArgs(1).
+						WillReturnRows(rows)
+				},
+			},
+			args: args{
+				taskID: 1,
+			},
+			want: []entities.Comment{{
+				ID:        1,
+				TaskID:    1,
+				Author:    "alice",
+				Body:      "foo",
+				Edited:    true,
+				CreatedAt: tn,
+				UpdatedAt: tn,
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				taskID: 1,
+			},
+			want:    nil,
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "find comments failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT \* FROM "comments"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			args: args{
+				taskID: 1,
+			},
+			want:    nil,
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := commentService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetComments(3, tt.args.taskID)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("commentService.GetComments() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commentService.GetComments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_commentService_CreateComment(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		taskID   int
+		authorID int
+		req      request.CreatedCommentRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT "email" FROM "users" WHERE "users"."id" = \$1 ORDER BY "users"."id" LIMIT \$2`).
+						WithArgs(7, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("alice@example.com"))
+					mock.ExpectBegin()
+					mock.ExpectQuery(`INSERT INTO "comments" \("task_id","author_id","author","body","edited","guest","created_at","updated_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) RETURNING "id"`).
+						WithArgs(1, 7, "alice@example.com", "foo", false, false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				taskID:   1,
+				authorID: 7,
+				req:      request.CreatedCommentRequest{Body: " foo "},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				taskID:   1,
+				authorID: 7,
+				req:      request.CreatedCommentRequest{Body: "foo"},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "find author failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT "email" FROM "users"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			args: args{
+				taskID:   1,
+				authorID: 7,
+				req:      request.CreatedCommentRequest{Body: "foo"},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := commentService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.CreateComment(3, tt.args.taskID, tt.args.authorID, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("commentService.CreateComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_commentService_CreateGuestComment(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	expectWorkspace(mock, 3)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "comments" \("task_id","author_id","author","body","edited","guest","created_at","updated_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) RETURNING "id"`).
+		WithArgs(1, nil, "guest", "foo", false, true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	s := commentService{
+		repository: base.NewBaseRepository[any](db),
+		log:        logger.WithPrefix("test"),
+	}
+	if err := s.CreateGuestComment(3, 1, request.CreatedSharedCommentRequest{Author: " guest ", Body: "foo"}); err != nil {
+		t.Errorf("commentService.CreateGuestComment() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_commentService_UpdateComment(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		taskID   int
+		id       int
+		authorID int
+		req      request.UpdatedCommentRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(`UPDATE "comments" SET "body"=\$1,"edited"=\$2,"updated_at"=\$3 WHERE id = \$4 AND task_id = \$5 AND author_id = \$6`).
+						WithArgs("bar", true, sqlmock.AnyArg(), 2, 1, 7).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 7,
+				req:      request.UpdatedCommentRequest{Body: "bar"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "comment of another author",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(`UPDATE "comments"`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "comments" WHERE id = \$1 AND task_id = \$2`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 8,
+				req:      request.UpdatedCommentRequest{Body: "bar"},
+			},
+			wantErr: ErrCommentForbidden,
+		},
+		{
+			name: "comment not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(`UPDATE "comments"`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "comments" WHERE id = \$1 AND task_id = \$2`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 7,
+				req:      request.UpdatedCommentRequest{Body: "bar"},
+			},
+			wantErr: ErrCommentNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := commentService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.UpdateComment(tt.args.taskID, tt.args.id, tt.args.authorID, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("commentService.UpdateComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_commentService_DeleteComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		repository         *mock.MockBaseRepository[any]
+		repositoryBehavior func(*mock.MockBaseRepository[any])
+	}
+	type args struct {
+		taskID   int
+		id       int
+		authorID int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("id = ? AND task_id = ? AND author_id = ?", 2, 1, 7).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(nil)
+					mbr.EXPECT().RowsAffected().Return(int64(1))
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 7,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "comment of another author",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("id = ? AND task_id = ? AND author_id = ?", 2, 1, 8).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(nil)
+					mbr.EXPECT().RowsAffected().Return(int64(0))
+					mbr.EXPECT().Model(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Where("id = ? AND task_id = ?", 2, 1).Return(mbr)
+					mbr.EXPECT().Count(gomock.Any()).DoAndReturn(func(count *int64) *mock.MockBaseRepository[any] {
+						*count = 1
+						return mbr
+					})
+					mbr.EXPECT().Error()
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 8,
+			},
+			wantErr: ErrCommentForbidden,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("id = ? AND task_id = ? AND author_id = ?", 2, 1, 7).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				taskID:   1,
+				id:       2,
+				authorID: 7,
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.repository)
+			s := commentService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteComment(tt.args.taskID, tt.args.id, tt.args.authorID); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("commentService.DeleteComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
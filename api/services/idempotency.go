@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"todo/api/entities"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrIdempotencyKeyReused     = errors.New("idempotency key has been used with another request")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is in progress")
+)
+
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyLease is how long a request may take before its key is
+// considered abandoned, e.g. by a crashed replica, and can be claimed again.
+const idempotencyLease = time.Minute
+
+type IdempotencyService interface {
+	// BeginRequest claims key for the request hashed to requestHash. Once
+	// the key has a response, it returns that instead.
+	BeginRequest(userID int, key string, requestHash string) (*entities.IdempotencyKey, error)
+	// CompleteRequest stores the response of a claimed key.
+	CompleteRequest(userID int, key string, requestHash string, statusCode int, contentType string, body []byte) error
+	// ReleaseRequest gives up a claimed key, so the request can be retried.
+	ReleaseRequest(userID int, key string, requestHash string) error
+	PurgeExpiredKeys(before time.Time) (int64, error)
+}
+
+type idempotencyService struct {
+	repository base.BaseRepository[any]
+	ttl        time.Duration
+	log        logger.Logger
+}
+
+// NewIdempotencyService keeps keys for ttl after their request began.
+func NewIdempotencyService(repository base.BaseRepository[any], ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return &idempotencyService{
+		repository: repository,
+		ttl:        ttl,
+		log:        logger.WithPrefix("service/idempotency"),
+	}
+}
+
+func (s idempotencyService) BeginRequest(userID int, key string, requestHash string) (*entities.IdempotencyKey, error) {
+	tn := time.Now()
+	record := entities.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   tn,
+		ExpiresAt:   tn.Add(s.ttl),
+	}
+	result := s.repository.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	err := result.Error()
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() > 0 {
+		return nil, nil
+	}
+
+	// the key is taken; it can be claimed again once it has expired or its
+	// request was abandoned
+	result = s.repository.Model(&entities.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND (expires_at <= ? OR (status_code = 0 AND created_at <= ?))", userID, key, tn, tn.Add(-idempotencyLease)).
+		Updates(map[string]interface{}{
+			"request_hash": requestHash,
+			"status_code":  0,
+			"content_type": "",
+			"response":     nil,
+			"created_at":   tn,
+			"expires_at":   tn.Add(s.ttl),
+		})
+	err = result.Error()
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() > 0 {
+		return nil, nil
+	}
+
+	var existing entities.IdempotencyKey
+	err = s.repository.Where("user_id = ? AND key = ?", userID, key).First(&existing).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// released in the meantime
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return &existing, nil
+}
+
+func (s idempotencyService) CompleteRequest(userID int, key string, requestHash string, statusCode int, contentType string, body []byte) error {
+	return s.repository.Model(&entities.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND request_hash = ? AND status_code = 0", userID, key, requestHash).
+		Updates(map[string]interface{}{
+			"status_code":  statusCode,
+			"content_type": contentType,
+			"response":     body,
+		}).Error()
+}
+
+func (s idempotencyService) ReleaseRequest(userID int, key string, requestHash string) error {
+	return s.repository.Where("user_id = ? AND key = ? AND request_hash = ? AND status_code = 0", userID, key, requestHash).Delete(&entities.IdempotencyKey{}).Error()
+}
+
+func (s idempotencyService) PurgeExpiredKeys(before time.Time) (int64, error) {
+	result := s.repository.Where("expires_at <= ?", before).Delete(&entities.IdempotencyKey{})
+	err := result.Error()
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func Test_idempotencyService_BeginRequest(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		insertQuery = `INSERT INTO "idempotency_keys" \("user_id","key","request_hash","status_code","content_type","response","created_at","expires_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8\) ON CONFLICT DO NOTHING`
+		claimQuery  = `UPDATE "idempotency_keys" SET "content_type"=\$1,"created_at"=\$2,"expires_at"=\$3,"request_hash"=\$4,"response"=\$5,"status_code"=\$6 WHERE user_id = \$7 AND key = \$8 AND \(expires_at <= \$9 OR \(status_code = 0 AND created_at <= \$10\)\)`
+		findQuery   = `SELECT \* FROM "idempotency_keys" WHERE user_id = \$1 AND key = \$2 ORDER BY "idempotency_keys"."user_id" LIMIT \$3`
+	)
+	columns := []string{"user_id", "key", "request_hash", "status_code", "content_type", "response", "created_at", "expires_at"}
+	taken := func() {
+		mock.ExpectBegin()
+		mock.ExpectExec(insertQuery).
+			WithArgs(7, "key", "hash", 0, "", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(claimQuery).
+			WithArgs("", sqlmock.AnyArg(), sqlmock.AnyArg(), "hash", sqlmock.AnyArg(), 0, 7, "key", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+	}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    *entities.IdempotencyKey
+		wantErr error
+	}{
+		{
+			name: "new key",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name: "expired or abandoned key",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectExec(claimQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			want:    nil,
+			wantErr: nil,
+		},
+		{
+			name: "completed key",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					taken()
+					mock.ExpectQuery(findQuery).
+						WithArgs(7, "key", 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(7, "key", "hash", 200, "application/json", []byte(`{"status":200}`), tn, tn))
+				},
+			},
+			want: &entities.IdempotencyKey{
+				UserID:      7,
+				Key:         "key",
+				RequestHash: "hash",
+				StatusCode:  200,
+				ContentType: "application/json",
+				Response:    []byte(`{"status":200}`),
+				CreatedAt:   tn,
+				ExpiresAt:   tn,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "key in progress",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					taken()
+					mock.ExpectQuery(findQuery).
+						WithArgs(7, "key", 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(7, "key", "hash", 0, "", nil, tn, tn))
+				},
+			},
+			want:    nil,
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "key released in the meantime",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					taken()
+					mock.ExpectQuery(findQuery).WillReturnRows(sqlmock.NewRows(columns))
+				},
+			},
+			want:    nil,
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "key of another request",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					taken()
+					mock.ExpectQuery(findQuery).
+						WithArgs(7, "key", 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(7, "key", "other hash", 200, "application/json", []byte(`{}`), tn, tn))
+				},
+			},
+			want:    nil,
+			wantErr: ErrIdempotencyKeyReused,
+		},
+		{
+			name: "insert failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(insertQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    nil,
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := idempotencyService{
+				repository: tt.fields.repository,
+				ttl:        DefaultIdempotencyTTL,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.BeginRequest(7, "key", "hash")
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("idempotencyService.BeginRequest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("idempotencyService.BeginRequest() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_idempotencyService_CompleteRequest(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "idempotency_keys" SET "content_type"=\$1,"response"=\$2,"status_code"=\$3 WHERE user_id = \$4 AND key = \$5 AND request_hash = \$6 AND status_code = 0`).
+		WithArgs("application/json", []byte(`{}`), 200, 7, "key", "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s := idempotencyService{
+		repository: base.NewBaseRepository[any](db),
+		ttl:        DefaultIdempotencyTTL,
+		log:        logger.WithPrefix("test"),
+	}
+	err := s.CompleteRequest(7, "key", "hash", 200, "application/json", []byte(`{}`))
+	if err != nil {
+		t.Errorf("idempotencyService.CompleteRequest() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_idempotencyService_ReleaseRequest(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "idempotency_keys" WHERE user_id = \$1 AND key = \$2 AND request_hash = \$3 AND status_code = 0`).
+		WithArgs(7, "key", "hash").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	s := idempotencyService{
+		repository: base.NewBaseRepository[any](db),
+		ttl:        DefaultIdempotencyTTL,
+		log:        logger.WithPrefix("test"),
+	}
+	err := s.ReleaseRequest(7, "key", "hash")
+	if err != nil {
+		t.Errorf("idempotencyService.ReleaseRequest() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func Test_idempotencyService_PurgeExpiredKeys(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM "idempotency_keys" WHERE expires_at <= \$1`).
+		WithArgs(tn).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	s := idempotencyService{
+		repository: base.NewBaseRepository[any](db),
+		ttl:        DefaultIdempotencyTTL,
+		log:        logger.WithPrefix("test"),
+	}
+	got, err := s.PurgeExpiredKeys(tn)
+	if err != nil || got != 3 {
+		t.Errorf("idempotencyService.PurgeExpiredKeys() = %v, %v, want 3", got, err)
+	}
+}
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/imaging"
+	"todo/pkg/logger"
+	"todo/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImageNotFound    = errors.New("task has no image")
+	ErrImageUnsupported = imaging.ErrUnsupported
+	ErrImageTooLarge    = imaging.ErrTooLarge
+	ErrImageInvalid     = imaging.ErrInvalid
+)
+
+// legacyImageBatch is how many base64 images MigrateLegacyImages moves at a
+// time.
+const legacyImageBatch = 100
+
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+type ImageService interface {
+	UploadTaskImage(workspaceID int, id int, version int, upload request.UploadedImage) error
+	// GetTaskImage returns the image content, which the caller must close.
+	GetTaskImage(workspaceID int, id int) (io.ReadCloser, storage.Object, error)
+	// GetTaskThumbnail returns the thumbnail content, which the caller must
+	// close.
+	GetTaskThumbnail(workspaceID int, id int) (io.ReadCloser, storage.Object, error)
+	DeleteTaskImage(workspaceID int, id int, version int) error
+	// MigrateLegacyImages moves base64 payloads from the old tasks.image
+	// column into blob storage and drops the column once all are moved.
+	MigrateLegacyImages() (int, error)
+}
+
+type imageService struct {
+	repository base.BaseRepository[any]
+	store      storage.BlobStore
+	processor  imaging.Processor
+	events     *TaskEvents
+	log        logger.Logger
+}
+
+func NewImageService(repository base.BaseRepository[any], store storage.BlobStore, processor imaging.Processor, events *TaskEvents) ImageService {
+	return &imageService{
+		repository: repository,
+		store:      store,
+		processor:  processor,
+		events:     events,
+		log:        logger.WithPrefix("service/image"),
+	}
+}
+
+func (s imageService) UploadTaskImage(workspaceID int, id int, version int, upload request.UploadedImage) error {
+	maxBytes := s.processor.Limits().MaxBytes
+	if upload.Size > maxBytes {
+		return ErrImageTooLarge
+	}
+
+	task, err := s.findTask(workspaceID, id)
+	if err != nil {
+		return err
+	}
+
+	// the declared size comes from the client, so the read is capped too
+	data, err := io.ReadAll(io.LimitReader(upload.Reader, maxBytes+1))
+	if err != nil {
+		return err
+	}
+	image, err := s.processor.Process(data)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.putImage(id, image)
+	if err != nil {
+		return err
+	}
+
+	err = s.setImageKeys(workspaceID, id, version, keys)
+	if err != nil {
+		s.deleteBlobs(keys)
+		return err
+	}
+	s.deleteBlobs(imageKeys{image: task.ImageKey, thumbnail: task.ThumbnailKey})
+
+	return nil
+}
+
+func (s imageService) GetTaskImage(workspaceID int, id int) (io.ReadCloser, storage.Object, error) {
+	task, err := s.findTask(workspaceID, id)
+	if err != nil {
+		return nil, storage.Object{}, err
+	}
+
+	return s.getBlob(task.ImageKey)
+}
+
+func (s imageService) GetTaskThumbnail(workspaceID int, id int) (io.ReadCloser, storage.Object, error) {
+	task, err := s.findTask(workspaceID, id)
+	if err != nil {
+		return nil, storage.Object{}, err
+	}
+
+	return s.getBlob(task.ThumbnailKey)
+}
+
+func (s imageService) DeleteTaskImage(workspaceID int, id int, version int) error {
+	task, err := s.findTask(workspaceID, id)
+	if err != nil {
+		return err
+	}
+	if len(task.ImageKey) == 0 {
+		return ErrImageNotFound
+	}
+
+	err = s.setImageKeys(workspaceID, id, version, imageKeys{})
+	if err != nil {
+		return err
+	}
+	s.deleteBlobs(imageKeys{image: task.ImageKey, thumbnail: task.ThumbnailKey})
+
+	return nil
+}
+
+type legacyImage struct {
+	ID       int
+	Image    string
+	ImageKey string
+}
+
+// MigrateLegacyImages reads and writes the tasks of every workspace.
+func (s imageService) MigrateLegacyImages() (int, error) {
+	var columns int64
+	err := s.repository.Raw(`SELECT count(*) FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'tasks' AND column_name = 'image'`).Scan(&columns).Error()
+	if err != nil {
+		return 0, err
+	}
+	if columns == 0 {
+		return 0, nil
+	}
+
+	var migrated, failed int
+	lastID := 0
+	for {
+		var images []legacyImage
+		err = database.AllWorkspaces(s.repository, func(repository base.BaseRepository[any]) error {
+			return repository.Raw(`SELECT id, image, image_key FROM tasks WHERE id > ? AND image <> '' ORDER BY id LIMIT ?`,
+				lastID, legacyImageBatch).Scan(&images).Error()
+		})
+		if err != nil {
+			return migrated, err
+		}
+		if len(images) == 0 {
+			break
+		}
+
+		for _, image := range images {
+			lastID = image.ID
+			err = s.migrateLegacyImage(image)
+			if err != nil {
+				s.log.Wrap("migrate image of task %d failed: %v", image.ID, err).Error()
+				failed++
+				continue
+			}
+			migrated++
+		}
+	}
+
+	if failed > 0 {
+		return migrated, fmt.Errorf("%d images could not be migrated", failed)
+	}
+
+	return migrated, s.repository.Exec(`ALTER TABLE tasks DROP COLUMN image`).Error()
+}
+
+// migrateLegacyImage uploads one base64 payload. A task that already got an
+// image through the upload route keeps it and only loses the stale payload.
+func (s imageService) migrateLegacyImage(legacy legacyImage) error {
+	if len(legacy.ImageKey) > 0 {
+		return database.AllWorkspaces(s.repository, func(repository base.BaseRepository[any]) error {
+			return repository.Exec(`UPDATE tasks SET image = '' WHERE id = ?`, legacy.ID).Error()
+		})
+	}
+
+	data, err := decodeLegacyImage(legacy.Image)
+	if err != nil {
+		return err
+	}
+	// legacy payloads get the same checks and stripping as uploads
+	image, err := s.processor.Process(data)
+	if err != nil {
+		return err
+	}
+
+	keys, err := s.putImage(legacy.ID, image)
+	if err != nil {
+		return err
+	}
+
+	err = database.AllWorkspaces(s.repository, func(repository base.BaseRepository[any]) error {
+		return repository.Exec(`UPDATE tasks SET image_key = ?, thumbnail_key = ?, image = '', version = version + 1 WHERE id = ?`,
+			keys.image, keys.thumbnail, legacy.ID).Error()
+	})
+	if err != nil {
+		s.deleteBlobs(keys)
+		return err
+	}
+
+	return nil
+}
+
+type imageKeys struct {
+	image     string
+	thumbnail string
+}
+
+// putImage stores the image and its thumbnail under fresh keys, so a
+// replaced image never overwrites the one still referenced.
+func (s imageService) putImage(taskID int, image imaging.Result) (imageKeys, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return imageKeys{}, err
+	}
+	name := fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(b))
+	keys := imageKeys{
+		image:     name + imageExtensions[image.ContentType],
+		thumbnail: name + "_thumb" + imageExtensions[image.ThumbnailContentType],
+	}
+
+	err = s.store.Put(context.Background(), keys.image, bytes.NewReader(image.Image), int64(len(image.Image)), image.ContentType)
+	if err != nil {
+		return imageKeys{}, err
+	}
+	err = s.store.Put(context.Background(), keys.thumbnail, bytes.NewReader(image.Thumbnail), int64(len(image.Thumbnail)), image.ThumbnailContentType)
+	if err != nil {
+		s.deleteBlobs(imageKeys{image: keys.image})
+		return imageKeys{}, err
+	}
+
+	return keys, nil
+}
+
+func (s imageService) getBlob(key string) (io.ReadCloser, storage.Object, error) {
+	if len(key) == 0 {
+		return nil, storage.Object{}, ErrImageNotFound
+	}
+
+	reader, object, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, storage.Object{}, ErrImageNotFound
+		}
+		return nil, storage.Object{}, err
+	}
+
+	return reader, object, nil
+}
+
+func (s imageService) findTask(workspaceID int, id int) (entities.Task, error) {
+	var task entities.Task
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Where("id = ?", id).First(&task).Error()
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Task{}, ErrTaskNotFound
+		}
+		return entities.Task{}, err
+	}
+
+	return task, nil
+}
+
+func (s imageService) setImageKeys(workspaceID int, id int, version int, keys imageKeys) error {
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		db := repository.Model(&entities.Task{}).Where("id = ?", id)
+		if version > 0 {
+			db = db.Where("version = ?", version)
+		}
+
+		result := db.Updates(map[string]interface{}{
+			"image_key":     keys.image,
+			"thumbnail_key": keys.thumbnail,
+			"updated_at":    time.Now(),
+			"version":       gorm.Expr("version + 1"),
+		})
+		err := result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			var count int64
+			err = repository.Model(&entities.Task{}).Where("id = ?", id).Count(&count).Error()
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrTaskNotFound
+			}
+			return ErrTaskVersionMismatch
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, id)
+	return nil
+}
+
+// deleteBlobs is best effort: the task no longer points at the keys, so a
+// leftover blob is only wasted space.
+func (s imageService) deleteBlobs(keys imageKeys) {
+	for _, key := range []string{keys.image, keys.thumbnail} {
+		if len(key) == 0 {
+			continue
+		}
+		err := s.store.Delete(context.Background(), key)
+		if err != nil {
+			s.log.Wrap("delete blob %s failed: %v", key, err).Error()
+		}
+	}
+}
+
+// decodeLegacyImage accepts plain base64 as well as data URLs such as
+// "data:image/png;base64,...".
+func decodeLegacyImage(image string) ([]byte, error) {
+	if strings.HasPrefix(image, "data:") {
+		header, payload, ok := strings.Cut(image, ",")
+		if !ok || !strings.HasSuffix(header, ";base64") {
+			return nil, errors.New("image is not a base64 data url")
+		}
+		image = payload
+	}
+
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(image), "="))
+}
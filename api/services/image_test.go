@@ -0,0 +1,649 @@
+package services
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/imaging"
+	imagingmock "todo/pkg/imaging/mock"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+	"todo/pkg/storage"
+	storagemock "todo/pkg/storage/mock"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+)
+
+const selectTaskQuery = `SELECT \* FROM "tasks" WHERE id = \$1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT \$2`
+
+func Test_imageService_UploadTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	processed := imaging.Result{
+		Image:                []byte("clean"),
+		ContentType:          "image/png",
+		Thumbnail:            []byte("th"),
+		ThumbnailContentType: "image/jpeg",
+	}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		processor          *imagingmock.MockProcessor
+		repositoryBehavior func(*storagemock.MockBlobStore, *imagingmock.MockProcessor)
+	}
+	type args struct {
+		id      int
+		version int
+		upload  request.UploadedImage
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success replaces old image",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 10})
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WithArgs(1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key", "thumbnail_key", "version"}).
+							AddRow(1, "tasks/1/old.png", "tasks/1/old_thumb.jpg", 2))
+					mock.ExpectCommit()
+					mp.EXPECT().Process([]byte("png")).Return(processed, nil)
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(5), "image/png").
+						DoAndReturn(func(_ interface{}, key string, _ io.Reader, _ int64, _ string) error {
+							if !strings.HasPrefix(key, "tasks/1/") || !strings.HasSuffix(key, ".png") {
+								t.Errorf("key = %s", key)
+							}
+							return nil
+						})
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(2), "image/jpeg").
+						DoAndReturn(func(_ interface{}, key string, _ io.Reader, _ int64, _ string) error {
+							if !strings.HasPrefix(key, "tasks/1/") || !strings.HasSuffix(key, "_thumb.jpg") {
+								t.Errorf("key = %s", key)
+							}
+							return nil
+						})
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "image_key"=\$1,"thumbnail_key"=\$2,"updated_at"=\$3,"version"=version \+ 1 WHERE id = \$4 AND version = \$5 AND "tasks"."deleted_at" IS NULL`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/old.png")
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/old_thumb.jpg")
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("png"), Size: 3},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "version mismatch removes new blobs",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					uploaded := map[string]bool{}
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 10})
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 3))
+					mock.ExpectCommit()
+					mp.EXPECT().Process(gomock.Any()).Return(processed, nil)
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ interface{}, key string, _ io.Reader, _ int64, _ string) error {
+							uploaded[key] = true
+							return nil
+						}).Times(2)
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks"`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+					mbs.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(func(_ interface{}, key string) error {
+						if !uploaded[key] {
+							t.Errorf("deleted %s, which was not uploaded", key)
+						}
+						return nil
+					}).Times(2)
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("png"), Size: 3},
+			},
+			wantErr: ErrTaskVersionMismatch,
+		},
+		{
+			name: "image too large",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 2})
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("png"), Size: 3},
+			},
+			wantErr: ErrImageTooLarge,
+		},
+		{
+			name: "image unsupported",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 10})
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 2))
+					mock.ExpectCommit()
+					mp.EXPECT().Process(gomock.Any()).Return(imaging.Result{}, imaging.ErrUnsupported)
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("bmp"), Size: 3},
+			},
+			wantErr: ErrImageUnsupported,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 10})
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("png"), Size: 3},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "put thumbnail failed removes image",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mp.EXPECT().Limits().Return(imaging.Limits{MaxBytes: 10})
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "version"}).AddRow(1, 2))
+					mock.ExpectCommit()
+					mp.EXPECT().Process(gomock.Any()).Return(processed, nil)
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(5), "image/png")
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(2), "image/jpeg").Return(errors.New("foo"))
+					mbs.EXPECT().Delete(gomock.Any(), gomock.Any())
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				upload:  request.UploadedImage{Reader: strings.NewReader("png"), Size: 3},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store, tt.fields.processor)
+			s := imageService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				processor:  tt.fields.processor,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.UploadTaskImage(3, tt.args.id, tt.args.version, tt.args.upload); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("imageService.UploadTaskImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_imageService_GetTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		id      int
+		want    storage.Object
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WithArgs(1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key"}).AddRow(1, "tasks/1/a.png"))
+					mock.ExpectCommit()
+					mbs.EXPECT().Get(gomock.Any(), "tasks/1/a.png").
+						Return(io.NopCloser(strings.NewReader("png")), storage.Object{ContentType: "image/png", Size: 3}, nil)
+				},
+			},
+			id:      1,
+			want:    storage.Object{ContentType: "image/png", Size: 3},
+			wantErr: nil,
+		},
+		{
+			name: "task has no image",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key"}).AddRow(1, ""))
+					mock.ExpectCommit()
+				},
+			},
+			id:      1,
+			wantErr: ErrImageNotFound,
+		},
+		{
+			name: "blob missing",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key"}).AddRow(1, "tasks/1/a.png"))
+					mock.ExpectCommit()
+					mbs.EXPECT().Get(gomock.Any(), "tasks/1/a.png").Return(nil, storage.Object{}, storage.ErrNotFound)
+				},
+			},
+			id:      1,
+			wantErr: ErrImageNotFound,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			id:      1,
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := imageService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				log:        logger.WithPrefix("test"),
+			}
+
+			reader, got, err := s.GetTaskImage(3, tt.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("imageService.GetTaskImage() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if reader != nil {
+				reader.Close()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imageService.GetTaskImage() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_imageService_GetTaskThumbnail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    storage.Object
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key", "thumbnail_key"}).AddRow(1, "tasks/1/a.png", "tasks/1/a_thumb.jpg"))
+					mock.ExpectCommit()
+					mbs.EXPECT().Get(gomock.Any(), "tasks/1/a_thumb.jpg").
+						Return(io.NopCloser(strings.NewReader("jpg")), storage.Object{ContentType: "image/jpeg", Size: 3}, nil)
+				},
+			},
+			want:    storage.Object{ContentType: "image/jpeg", Size: 3},
+			wantErr: nil,
+		},
+		{
+			name: "image has no thumbnail",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key", "thumbnail_key"}).AddRow(1, "tasks/1/a.png", ""))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: ErrImageNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := imageService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				log:        logger.WithPrefix("test"),
+			}
+
+			reader, got, err := s.GetTaskThumbnail(3, 1)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("imageService.GetTaskThumbnail() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if reader != nil {
+				reader.Close()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("imageService.GetTaskThumbnail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_imageService_DeleteTaskImage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		version int
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "success without version check",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key", "thumbnail_key"}).AddRow(1, "tasks/1/a.png", "tasks/1/a_thumb.jpg"))
+					mock.ExpectCommit()
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "image_key"=\$1,"thumbnail_key"=\$2,"updated_at"=\$3,"version"=version \+ 1 WHERE id = \$4 AND "tasks"."deleted_at" IS NULL`).
+						WithArgs("", "", sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/a.png").Return(errors.New("foo"))
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/a_thumb.jpg")
+				},
+			},
+			version:       0,
+			wantErr:       nil,
+			wantPublished: []int{1},
+		},
+		{
+			name: "task has no image",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(selectTaskQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image_key"}).AddRow(1, ""))
+					mock.ExpectCommit()
+				},
+			},
+			version: 1,
+			wantErr: ErrImageNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := imageService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteTaskImage(3, 1, tt.version); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("imageService.DeleteTaskImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("imageService.DeleteTaskImage() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func Test_imageService_MigrateLegacyImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		columnQuery = `SELECT count\(\*\) FROM information_schema.columns`
+		batchQuery  = `SELECT id, image, image_key FROM tasks WHERE id > \$1 AND image <> '' ORDER BY id LIMIT \$2`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		store              *storagemock.MockBlobStore
+		processor          *imagingmock.MockProcessor
+		repositoryBehavior func(*storagemock.MockBlobStore, *imagingmock.MockProcessor)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    int
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mock.ExpectQuery(columnQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(batchQuery).
+						WithArgs(0, legacyImageBatch).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image", "image_key"}).
+							AddRow(1, "data:image/gif;base64,R0lGODlh", "").
+							AddRow(2, "iVBORw0KGgo", "").
+							AddRow(3, "Zm9v", "tasks/3/new.png"))
+					mock.ExpectCommit()
+					mp.EXPECT().Process([]byte("GIF89a")).Return(imaging.Result{
+						Image:                []byte("gif"),
+						ContentType:          "image/gif",
+						Thumbnail:            []byte("thumb"),
+						ThumbnailContentType: "image/png",
+					}, nil)
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(3), "image/gif")
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(5), "image/png")
+					expectAllWorkspaces(mock)
+					mock.ExpectExec(`UPDATE tasks SET image_key = \$1, thumbnail_key = \$2, image = '', version = version \+ 1 WHERE id = \$3`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					mp.EXPECT().Process([]byte("\x89PNG\r\n\x1a\n")).Return(imaging.Result{
+						Image:                []byte("png"),
+						ContentType:          "image/png",
+						Thumbnail:            []byte("thumb"),
+						ThumbnailContentType: "image/jpeg",
+					}, nil)
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(3), "image/png")
+					mbs.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), int64(5), "image/jpeg")
+					expectAllWorkspaces(mock)
+					mock.ExpectExec(`UPDATE tasks SET image_key = \$1, thumbnail_key = \$2, image = '', version = version \+ 1 WHERE id = \$3`).
+						WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					expectAllWorkspaces(mock)
+					mock.ExpectExec(`UPDATE tasks SET image = '' WHERE id = \$1`).
+						WithArgs(3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(batchQuery).
+						WithArgs(3, legacyImageBatch).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image", "image_key"}))
+					mock.ExpectCommit()
+					mock.ExpectExec(`ALTER TABLE tasks DROP COLUMN image`).WillReturnResult(sqlmock.NewResult(0, 0))
+				},
+			},
+			want:    3,
+			wantErr: nil,
+		},
+		{
+			name: "already migrated",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mock.ExpectQuery(columnQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				},
+			},
+			want:    0,
+			wantErr: nil,
+		},
+		{
+			name: "invalid payload keeps column",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				store:      storagemock.NewMockBlobStore(ctrl),
+				processor:  imagingmock.NewMockProcessor(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore, mp *imagingmock.MockProcessor) {
+					mock.ExpectQuery(columnQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(batchQuery).
+						WithArgs(0, legacyImageBatch).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image", "image_key"}).AddRow(1, "not base64!", ""))
+					mock.ExpectCommit()
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(batchQuery).
+						WithArgs(1, legacyImageBatch).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "image", "image_key"}))
+					mock.ExpectCommit()
+				},
+			},
+			want:    0,
+			wantErr: errors.New("1 images could not be migrated"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store, tt.fields.processor)
+			s := imageService{
+				repository: tt.fields.repository,
+				store:      tt.fields.store,
+				processor:  tt.fields.processor,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.MigrateLegacyImages()
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("imageService.MigrateLegacyImages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("imageService.MigrateLegacyImages() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
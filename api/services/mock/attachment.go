@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./attachment.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	io "io"
+	reflect "reflect"
+	entities "todo/api/entities"
+	request "todo/api/models/request"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAttachmentService is a mock of AttachmentService interface.
+type MockAttachmentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAttachmentServiceMockRecorder
+}
+
+// MockAttachmentServiceMockRecorder is the mock recorder for MockAttachmentService.
+type MockAttachmentServiceMockRecorder struct {
+	mock *MockAttachmentService
+}
+
+// NewMockAttachmentService creates a new mock instance.
+func NewMockAttachmentService(ctrl *gomock.Controller) *MockAttachmentService {
+	mock := &MockAttachmentService{ctrl: ctrl}
+	mock.recorder = &MockAttachmentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAttachmentService) EXPECT() *MockAttachmentServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateAttachment mocks base method.
+func (m *MockAttachmentService) CreateAttachment(workspaceID, taskID int, upload request.UploadedAttachment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAttachment", workspaceID, taskID, upload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAttachment indicates an expected call of CreateAttachment.
+func (mr *MockAttachmentServiceMockRecorder) CreateAttachment(workspaceID, taskID, upload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAttachment", reflect.TypeOf((*MockAttachmentService)(nil).CreateAttachment), workspaceID, taskID, upload)
+}
+
+// DeleteAttachment mocks base method.
+func (m *MockAttachmentService) DeleteAttachment(taskID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAttachment", taskID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAttachment indicates an expected call of DeleteAttachment.
+func (mr *MockAttachmentServiceMockRecorder) DeleteAttachment(taskID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAttachment", reflect.TypeOf((*MockAttachmentService)(nil).DeleteAttachment), taskID, id)
+}
+
+// GetAttachment mocks base method.
+func (m *MockAttachmentService) GetAttachment(taskID, id int) (entities.Attachment, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachment", taskID, id)
+	ret0, _ := ret[0].(entities.Attachment)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAttachment indicates an expected call of GetAttachment.
+func (mr *MockAttachmentServiceMockRecorder) GetAttachment(taskID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachment", reflect.TypeOf((*MockAttachmentService)(nil).GetAttachment), taskID, id)
+}
+
+// GetAttachments mocks base method.
+func (m *MockAttachmentService) GetAttachments(workspaceID, taskID int) ([]entities.Attachment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAttachments", workspaceID, taskID)
+	ret0, _ := ret[0].([]entities.Attachment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAttachments indicates an expected call of GetAttachments.
+func (mr *MockAttachmentServiceMockRecorder) GetAttachments(workspaceID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAttachments", reflect.TypeOf((*MockAttachmentService)(nil).GetAttachments), workspaceID, taskID)
+}
@@ -0,0 +1,107 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./comment.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	entities "todo/api/entities"
+	request "todo/api/models/request"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCommentService is a mock of CommentService interface.
+type MockCommentService struct {
+	ctrl     *gomock.Controller
+	recorder *MockCommentServiceMockRecorder
+}
+
+// MockCommentServiceMockRecorder is the mock recorder for MockCommentService.
+type MockCommentServiceMockRecorder struct {
+	mock *MockCommentService
+}
+
+// NewMockCommentService creates a new mock instance.
+func NewMockCommentService(ctrl *gomock.Controller) *MockCommentService {
+	mock := &MockCommentService{ctrl: ctrl}
+	mock.recorder = &MockCommentServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCommentService) EXPECT() *MockCommentServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateComment mocks base method.
+func (m *MockCommentService) CreateComment(workspaceID, taskID, authorID int, req request.CreatedCommentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", workspaceID, taskID, authorID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateComment indicates an expected call of CreateComment.
+func (mr *MockCommentServiceMockRecorder) CreateComment(workspaceID, taskID, authorID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockCommentService)(nil).CreateComment), workspaceID, taskID, authorID, req)
+}
+
+// CreateGuestComment mocks base method.
+func (m *MockCommentService) CreateGuestComment(workspaceID, taskID int, req request.CreatedSharedCommentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGuestComment", workspaceID, taskID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateGuestComment indicates an expected call of CreateGuestComment.
+func (mr *MockCommentServiceMockRecorder) CreateGuestComment(workspaceID, taskID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGuestComment", reflect.TypeOf((*MockCommentService)(nil).CreateGuestComment), workspaceID, taskID, req)
+}
+
+// DeleteComment mocks base method.
+func (m *MockCommentService) DeleteComment(taskID, id, authorID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", taskID, id, authorID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockCommentServiceMockRecorder) DeleteComment(taskID, id, authorID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockCommentService)(nil).DeleteComment), taskID, id, authorID)
+}
+
+// GetComments mocks base method.
+func (m *MockCommentService) GetComments(workspaceID, taskID int) ([]entities.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComments", workspaceID, taskID)
+	ret0, _ := ret[0].([]entities.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComments indicates an expected call of GetComments.
+func (mr *MockCommentServiceMockRecorder) GetComments(workspaceID, taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComments", reflect.TypeOf((*MockCommentService)(nil).GetComments), workspaceID, taskID)
+}
+
+// UpdateComment mocks base method.
+func (m *MockCommentService) UpdateComment(taskID, id, authorID int, req request.UpdatedCommentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateComment", taskID, id, authorID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateComment indicates an expected call of UpdateComment.
+func (mr *MockCommentServiceMockRecorder) UpdateComment(taskID, id, authorID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockCommentService)(nil).UpdateComment), taskID, id, authorID, req)
+}
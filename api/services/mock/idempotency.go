@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./idempotency.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+	entities "todo/api/entities"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockIdempotencyService is a mock of IdempotencyService interface.
+type MockIdempotencyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyServiceMockRecorder
+}
+
+// MockIdempotencyServiceMockRecorder is the mock recorder for MockIdempotencyService.
+type MockIdempotencyServiceMockRecorder struct {
+	mock *MockIdempotencyService
+}
+
+// NewMockIdempotencyService creates a new mock instance.
+func NewMockIdempotencyService(ctrl *gomock.Controller) *MockIdempotencyService {
+	mock := &MockIdempotencyService{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotencyService) EXPECT() *MockIdempotencyServiceMockRecorder {
+	return m.recorder
+}
+
+// BeginRequest mocks base method.
+func (m *MockIdempotencyService) BeginRequest(userID int, key, requestHash string) (*entities.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRequest", userID, key, requestHash)
+	ret0, _ := ret[0].(*entities.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeginRequest indicates an expected call of BeginRequest.
+func (mr *MockIdempotencyServiceMockRecorder) BeginRequest(userID, key, requestHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRequest", reflect.TypeOf((*MockIdempotencyService)(nil).BeginRequest), userID, key, requestHash)
+}
+
+// CompleteRequest mocks base method.
+func (m *MockIdempotencyService) CompleteRequest(userID int, key, requestHash string, statusCode int, contentType string, body []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteRequest", userID, key, requestHash, statusCode, contentType, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteRequest indicates an expected call of CompleteRequest.
+func (mr *MockIdempotencyServiceMockRecorder) CompleteRequest(userID, key, requestHash, statusCode, contentType, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteRequest", reflect.TypeOf((*MockIdempotencyService)(nil).CompleteRequest), userID, key, requestHash, statusCode, contentType, body)
+}
+
+// PurgeExpiredKeys mocks base method.
+func (m *MockIdempotencyService) PurgeExpiredKeys(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredKeys", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredKeys indicates an expected call of PurgeExpiredKeys.
+func (mr *MockIdempotencyServiceMockRecorder) PurgeExpiredKeys(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredKeys", reflect.TypeOf((*MockIdempotencyService)(nil).PurgeExpiredKeys), before)
+}
+
+// ReleaseRequest mocks base method.
+func (m *MockIdempotencyService) ReleaseRequest(userID int, key, requestHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRequest", userID, key, requestHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRequest indicates an expected call of ReleaseRequest.
+func (mr *MockIdempotencyServiceMockRecorder) ReleaseRequest(userID, key, requestHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRequest", reflect.TypeOf((*MockIdempotencyService)(nil).ReleaseRequest), userID, key, requestHash)
+}
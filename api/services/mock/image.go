@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./image.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	io "io"
+	reflect "reflect"
+	request "todo/api/models/request"
+	storage "todo/pkg/storage"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockImageService is a mock of ImageService interface.
+type MockImageService struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageServiceMockRecorder
+}
+
+// MockImageServiceMockRecorder is the mock recorder for MockImageService.
+type MockImageServiceMockRecorder struct {
+	mock *MockImageService
+}
+
+// NewMockImageService creates a new mock instance.
+func NewMockImageService(ctrl *gomock.Controller) *MockImageService {
+	mock := &MockImageService{ctrl: ctrl}
+	mock.recorder = &MockImageServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageService) EXPECT() *MockImageServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteTaskImage mocks base method.
+func (m *MockImageService) DeleteTaskImage(workspaceID, id, version int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTaskImage", workspaceID, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaskImage indicates an expected call of DeleteTaskImage.
+func (mr *MockImageServiceMockRecorder) DeleteTaskImage(workspaceID, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaskImage", reflect.TypeOf((*MockImageService)(nil).DeleteTaskImage), workspaceID, id, version)
+}
+
+// GetTaskImage mocks base method.
+func (m *MockImageService) GetTaskImage(workspaceID, id int) (io.ReadCloser, storage.Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskImage", workspaceID, id)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(storage.Object)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTaskImage indicates an expected call of GetTaskImage.
+func (mr *MockImageServiceMockRecorder) GetTaskImage(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskImage", reflect.TypeOf((*MockImageService)(nil).GetTaskImage), workspaceID, id)
+}
+
+// GetTaskThumbnail mocks base method.
+func (m *MockImageService) GetTaskThumbnail(workspaceID, id int) (io.ReadCloser, storage.Object, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskThumbnail", workspaceID, id)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(storage.Object)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTaskThumbnail indicates an expected call of GetTaskThumbnail.
+func (mr *MockImageServiceMockRecorder) GetTaskThumbnail(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskThumbnail", reflect.TypeOf((*MockImageService)(nil).GetTaskThumbnail), workspaceID, id)
+}
+
+// MigrateLegacyImages mocks base method.
+func (m *MockImageService) MigrateLegacyImages() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MigrateLegacyImages")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MigrateLegacyImages indicates an expected call of MigrateLegacyImages.
+func (mr *MockImageServiceMockRecorder) MigrateLegacyImages() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MigrateLegacyImages", reflect.TypeOf((*MockImageService)(nil).MigrateLegacyImages))
+}
+
+// UploadTaskImage mocks base method.
+func (m *MockImageService) UploadTaskImage(workspaceID, id, version int, upload request.UploadedImage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadTaskImage", workspaceID, id, version, upload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadTaskImage indicates an expected call of UploadTaskImage.
+func (mr *MockImageServiceMockRecorder) UploadTaskImage(workspaceID, id, version, upload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadTaskImage", reflect.TypeOf((*MockImageService)(nil).UploadTaskImage), workspaceID, id, version, upload)
+}
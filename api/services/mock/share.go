@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./share.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	entities "todo/api/entities"
+	request "todo/api/models/request"
+	response "todo/api/models/response"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockShareService is a mock of ShareService interface.
+type MockShareService struct {
+	ctrl     *gomock.Controller
+	recorder *MockShareServiceMockRecorder
+}
+
+// MockShareServiceMockRecorder is the mock recorder for MockShareService.
+type MockShareServiceMockRecorder struct {
+	mock *MockShareService
+}
+
+// NewMockShareService creates a new mock instance.
+func NewMockShareService(ctrl *gomock.Controller) *MockShareService {
+	mock := &MockShareService{ctrl: ctrl}
+	mock.recorder = &MockShareServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockShareService) EXPECT() *MockShareServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateShareLink mocks base method.
+func (m *MockShareService) CreateShareLink(workspaceID, taskID, userID int, req request.CreatedShareLinkRequest) (response.CreatedShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateShareLink", workspaceID, taskID, userID, req)
+	ret0, _ := ret[0].(response.CreatedShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateShareLink indicates an expected call of CreateShareLink.
+func (mr *MockShareServiceMockRecorder) CreateShareLink(workspaceID, taskID, userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateShareLink", reflect.TypeOf((*MockShareService)(nil).CreateShareLink), workspaceID, taskID, userID, req)
+}
+
+// DeleteShareLink mocks base method.
+func (m *MockShareService) DeleteShareLink(taskID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShareLink", taskID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShareLink indicates an expected call of DeleteShareLink.
+func (mr *MockShareServiceMockRecorder) DeleteShareLink(taskID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShareLink", reflect.TypeOf((*MockShareService)(nil).DeleteShareLink), taskID, id)
+}
+
+// GetShareLinks mocks base method.
+func (m *MockShareService) GetShareLinks(taskID int) ([]entities.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShareLinks", taskID)
+	ret0, _ := ret[0].([]entities.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShareLinks indicates an expected call of GetShareLinks.
+func (mr *MockShareServiceMockRecorder) GetShareLinks(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShareLinks", reflect.TypeOf((*MockShareService)(nil).GetShareLinks), taskID)
+}
+
+// OpenShareLink mocks base method.
+func (m *MockShareService) OpenShareLink(token, password string) (entities.ShareLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenShareLink", token, password)
+	ret0, _ := ret[0].(entities.ShareLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenShareLink indicates an expected call of OpenShareLink.
+func (mr *MockShareServiceMockRecorder) OpenShareLink(token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenShareLink", reflect.TypeOf((*MockShareService)(nil).OpenShareLink), token, password)
+}
@@ -0,0 +1,122 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./tag.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	entities "todo/api/entities"
+	request "todo/api/models/request"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTagService is a mock of TagService interface.
+type MockTagService struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagServiceMockRecorder
+}
+
+// MockTagServiceMockRecorder is the mock recorder for MockTagService.
+type MockTagServiceMockRecorder struct {
+	mock *MockTagService
+}
+
+// NewMockTagService creates a new mock instance.
+func NewMockTagService(ctrl *gomock.Controller) *MockTagService {
+	mock := &MockTagService{ctrl: ctrl}
+	mock.recorder = &MockTagServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagService) EXPECT() *MockTagServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateTag mocks base method.
+func (m *MockTagService) CreateTag(workspaceID int, req request.CreatedTagRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTag", workspaceID, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTag indicates an expected call of CreateTag.
+func (mr *MockTagServiceMockRecorder) CreateTag(workspaceID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTag", reflect.TypeOf((*MockTagService)(nil).CreateTag), workspaceID, req)
+}
+
+// DeleteTag mocks base method.
+func (m *MockTagService) DeleteTag(workspaceID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTag", workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTag indicates an expected call of DeleteTag.
+func (mr *MockTagServiceMockRecorder) DeleteTag(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTag", reflect.TypeOf((*MockTagService)(nil).DeleteTag), workspaceID, id)
+}
+
+// GetTag mocks base method.
+func (m *MockTagService) GetTag(workspaceID, id int) (entities.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTag", workspaceID, id)
+	ret0, _ := ret[0].(entities.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTag indicates an expected call of GetTag.
+func (mr *MockTagServiceMockRecorder) GetTag(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTag", reflect.TypeOf((*MockTagService)(nil).GetTag), workspaceID, id)
+}
+
+// GetTags mocks base method.
+func (m *MockTagService) GetTags(workspaceID int) ([]entities.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", workspaceID)
+	ret0, _ := ret[0].([]entities.Tag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockTagServiceMockRecorder) GetTags(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockTagService)(nil).GetTags), workspaceID)
+}
+
+// MergeTag mocks base method.
+func (m *MockTagService) MergeTag(workspaceID, id int, req request.MergedTagRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeTag", workspaceID, id, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeTag indicates an expected call of MergeTag.
+func (mr *MockTagServiceMockRecorder) MergeTag(workspaceID, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeTag", reflect.TypeOf((*MockTagService)(nil).MergeTag), workspaceID, id, req)
+}
+
+// UpdateTag mocks base method.
+func (m *MockTagService) UpdateTag(workspaceID, id int, req request.UpdatedTagRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTag", workspaceID, id, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTag indicates an expected call of UpdateTag.
+func (mr *MockTagServiceMockRecorder) UpdateTag(workspaceID, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTag", reflect.TypeOf((*MockTagService)(nil).UpdateTag), workspaceID, id, req)
+}
@@ -1,13 +1,16 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./api/services/task.go
+// Source: ./task.go
 
 // Package mock is a generated GoMock package.
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 	entities "todo/api/entities"
 	request "todo/api/models/request"
+	response "todo/api/models/response"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,6 +38,48 @@ func (m *MockTaskService) EXPECT() *MockTaskServiceMockRecorder {
 	return m.recorder
 }
 
+// AddDependency mocks base method.
+func (m *MockTaskService) AddDependency(workspaceID, id int, req request.CreatedDependencyRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDependency", workspaceID, id, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDependency indicates an expected call of AddDependency.
+func (mr *MockTaskServiceMockRecorder) AddDependency(workspaceID, id, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDependency", reflect.TypeOf((*MockTaskService)(nil).AddDependency), workspaceID, id, req)
+}
+
+// AssignProject mocks base method.
+func (m *MockTaskService) AssignProject(workspaceID, id, version int, req request.AssignedProjectRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignProject", workspaceID, id, version, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignProject indicates an expected call of AssignProject.
+func (mr *MockTaskServiceMockRecorder) AssignProject(workspaceID, id, version, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignProject", reflect.TypeOf((*MockTaskService)(nil).AssignProject), workspaceID, id, version, req)
+}
+
+// CheckWorkspace mocks base method.
+func (m *MockTaskService) CheckWorkspace(id, workspaceID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckWorkspace", id, workspaceID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckWorkspace indicates an expected call of CheckWorkspace.
+func (mr *MockTaskServiceMockRecorder) CheckWorkspace(id, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckWorkspace", reflect.TypeOf((*MockTaskService)(nil).CheckWorkspace), id, workspaceID)
+}
+
 // CreateTask mocks base method.
 func (m *MockTaskService) CreateTask(req request.CreatedTaskRequest) error {
 	m.ctrl.T.Helper()
@@ -49,31 +94,221 @@ func (mr *MockTaskServiceMockRecorder) CreateTask(req interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockTaskService)(nil).CreateTask), req)
 }
 
-// GetTasks mocks base method.
-func (m *MockTaskService) GetTasks(query request.TaskListQuery) ([]entities.Task, error) {
+// DeleteTask mocks base method.
+func (m *MockTaskService) DeleteTask(workspaceID, id, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetTasks", query)
+	ret := m.ctrl.Call(m, "DeleteTask", workspaceID, id, version)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTask indicates an expected call of DeleteTask.
+func (mr *MockTaskServiceMockRecorder) DeleteTask(workspaceID, id, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskService)(nil).DeleteTask), workspaceID, id, version)
+}
+
+// GetChildren mocks base method.
+func (m *MockTaskService) GetChildren(workspaceID, id int) ([]entities.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChildren", workspaceID, id)
 	ret0, _ := ret[0].([]entities.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// GetChildren indicates an expected call of GetChildren.
+func (mr *MockTaskServiceMockRecorder) GetChildren(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChildren", reflect.TypeOf((*MockTaskService)(nil).GetChildren), workspaceID, id)
+}
+
+// GetDependencies mocks base method.
+func (m *MockTaskService) GetDependencies(workspaceID, id int) (response.TaskDependencies, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDependencies", workspaceID, id)
+	ret0, _ := ret[0].(response.TaskDependencies)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDependencies indicates an expected call of GetDependencies.
+func (mr *MockTaskServiceMockRecorder) GetDependencies(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDependencies", reflect.TypeOf((*MockTaskService)(nil).GetDependencies), workspaceID, id)
+}
+
+// GetTask mocks base method.
+func (m *MockTaskService) GetTask(workspaceID, id int) (entities.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTask", workspaceID, id)
+	ret0, _ := ret[0].(entities.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTask indicates an expected call of GetTask.
+func (mr *MockTaskServiceMockRecorder) GetTask(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockTaskService)(nil).GetTask), workspaceID, id)
+}
+
+// GetTaskTree mocks base method.
+func (m *MockTaskService) GetTaskTree(workspaceID, id int) (entities.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTaskTree", workspaceID, id)
+	ret0, _ := ret[0].(entities.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTaskTree indicates an expected call of GetTaskTree.
+func (mr *MockTaskServiceMockRecorder) GetTaskTree(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTaskTree", reflect.TypeOf((*MockTaskService)(nil).GetTaskTree), workspaceID, id)
+}
+
+// GetTasks mocks base method.
+func (m *MockTaskService) GetTasks(query request.TaskListQuery) ([]entities.Task, response.Pagination, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasks", query)
+	ret0, _ := ret[0].([]entities.Task)
+	ret1, _ := ret[1].(response.Pagination)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // GetTasks indicates an expected call of GetTasks.
 func (mr *MockTaskServiceMockRecorder) GetTasks(query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockTaskService)(nil).GetTasks), query)
 }
 
+// GetTrashedTasks mocks base method.
+func (m *MockTaskService) GetTrashedTasks(workspaceID int) ([]entities.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedTasks", workspaceID)
+	ret0, _ := ret[0].([]entities.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrashedTasks indicates an expected call of GetTrashedTasks.
+func (mr *MockTaskServiceMockRecorder) GetTrashedTasks(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedTasks", reflect.TypeOf((*MockTaskService)(nil).GetTrashedTasks), workspaceID)
+}
+
+// MoveTask mocks base method.
+func (m *MockTaskService) MoveTask(workspaceID, id, version int, req request.MovedTaskRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveTask", workspaceID, id, version, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveTask indicates an expected call of MoveTask.
+func (mr *MockTaskServiceMockRecorder) MoveTask(workspaceID, id, version, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveTask", reflect.TypeOf((*MockTaskService)(nil).MoveTask), workspaceID, id, version, req)
+}
+
+// PatchTask mocks base method.
+func (m *MockTaskService) PatchTask(workspaceID, id, version int, req request.PatchedTaskRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PatchTask", workspaceID, id, version, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PatchTask indicates an expected call of PatchTask.
+func (mr *MockTaskServiceMockRecorder) PatchTask(workspaceID, id, version, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PatchTask", reflect.TypeOf((*MockTaskService)(nil).PatchTask), workspaceID, id, version, req)
+}
+
+// PurgeExpiredTasks mocks base method.
+func (m *MockTaskService) PurgeExpiredTasks(before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeExpiredTasks", before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeExpiredTasks indicates an expected call of PurgeExpiredTasks.
+func (mr *MockTaskServiceMockRecorder) PurgeExpiredTasks(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeExpiredTasks", reflect.TypeOf((*MockTaskService)(nil).PurgeExpiredTasks), before)
+}
+
+// PurgeTask mocks base method.
+func (m *MockTaskService) PurgeTask(workspaceID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTask", workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeTask indicates an expected call of PurgeTask.
+func (mr *MockTaskServiceMockRecorder) PurgeTask(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTask", reflect.TypeOf((*MockTaskService)(nil).PurgeTask), workspaceID, id)
+}
+
+// RemoveDependency mocks base method.
+func (m *MockTaskService) RemoveDependency(workspaceID, id, blockedByID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveDependency", workspaceID, id, blockedByID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveDependency indicates an expected call of RemoveDependency.
+func (mr *MockTaskServiceMockRecorder) RemoveDependency(workspaceID, id, blockedByID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveDependency", reflect.TypeOf((*MockTaskService)(nil).RemoveDependency), workspaceID, id, blockedByID)
+}
+
+// RestoreTask mocks base method.
+func (m *MockTaskService) RestoreTask(workspaceID, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTask", workspaceID, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTask indicates an expected call of RestoreTask.
+func (mr *MockTaskServiceMockRecorder) RestoreTask(workspaceID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTask", reflect.TypeOf((*MockTaskService)(nil).RestoreTask), workspaceID, id)
+}
+
 // UpdateTask mocks base method.
-func (m *MockTaskService) UpdateTask(id int, req request.UpdatedTaskRequest) error {
+func (m *MockTaskService) UpdateTask(workspaceID, id, version int, req request.UpdatedTaskRequest) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateTask", id, req)
+	ret := m.ctrl.Call(m, "UpdateTask", workspaceID, id, version, req)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateTask indicates an expected call of UpdateTask.
-func (mr *MockTaskServiceMockRecorder) UpdateTask(id, req interface{}) *gomock.Call {
+func (mr *MockTaskServiceMockRecorder) UpdateTask(workspaceID, id, version, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskService)(nil).UpdateTask), workspaceID, id, version, req)
+}
+
+// WatchTasks mocks base method.
+func (m *MockTaskService) WatchTasks(ctx context.Context, query request.TaskListQuery, after int64) (<-chan response.TaskEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchTasks", ctx, query, after)
+	ret0, _ := ret[0].(<-chan response.TaskEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WatchTasks indicates an expected call of WatchTasks.
+func (mr *MockTaskServiceMockRecorder) WatchTasks(ctx, query, after interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskService)(nil).UpdateTask), id, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchTasks", reflect.TypeOf((*MockTaskService)(nil).WatchTasks), ctx, query, after)
 }
@@ -0,0 +1,171 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./workspace.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	entities "todo/api/entities"
+	enum "todo/api/enum"
+	request "todo/api/models/request"
+	response "todo/api/models/response"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWorkspaceService is a mock of WorkspaceService interface.
+type MockWorkspaceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWorkspaceServiceMockRecorder
+}
+
+// MockWorkspaceServiceMockRecorder is the mock recorder for MockWorkspaceService.
+type MockWorkspaceServiceMockRecorder struct {
+	mock *MockWorkspaceService
+}
+
+// NewMockWorkspaceService creates a new mock instance.
+func NewMockWorkspaceService(ctrl *gomock.Controller) *MockWorkspaceService {
+	mock := &MockWorkspaceService{ctrl: ctrl}
+	mock.recorder = &MockWorkspaceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWorkspaceService) EXPECT() *MockWorkspaceServiceMockRecorder {
+	return m.recorder
+}
+
+// AcceptInvitation mocks base method.
+func (m *MockWorkspaceService) AcceptInvitation(userID int, req request.AcceptedInvitationRequest) (entities.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcceptInvitation", userID, req)
+	ret0, _ := ret[0].(entities.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcceptInvitation indicates an expected call of AcceptInvitation.
+func (mr *MockWorkspaceServiceMockRecorder) AcceptInvitation(userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcceptInvitation", reflect.TypeOf((*MockWorkspaceService)(nil).AcceptInvitation), userID, req)
+}
+
+// CreateInvitation mocks base method.
+func (m *MockWorkspaceService) CreateInvitation(workspaceID, inviterID int, inviterRole enum.Role, req request.CreatedInvitationRequest) (response.CreatedInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateInvitation", workspaceID, inviterID, inviterRole, req)
+	ret0, _ := ret[0].(response.CreatedInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateInvitation indicates an expected call of CreateInvitation.
+func (mr *MockWorkspaceServiceMockRecorder) CreateInvitation(workspaceID, inviterID, inviterRole, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateInvitation", reflect.TypeOf((*MockWorkspaceService)(nil).CreateInvitation), workspaceID, inviterID, inviterRole, req)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockWorkspaceService) CreateWorkspace(userID int, req request.CreatedWorkspaceRequest) (entities.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", userID, req)
+	ret0, _ := ret[0].(entities.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockWorkspaceServiceMockRecorder) CreateWorkspace(userID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockWorkspaceService)(nil).CreateWorkspace), userID, req)
+}
+
+// DefaultWorkspace mocks base method.
+func (m *MockWorkspaceService) DefaultWorkspace(userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DefaultWorkspace", userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DefaultWorkspace indicates an expected call of DefaultWorkspace.
+func (mr *MockWorkspaceServiceMockRecorder) DefaultWorkspace(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DefaultWorkspace", reflect.TypeOf((*MockWorkspaceService)(nil).DefaultWorkspace), userID)
+}
+
+// GetMembers mocks base method.
+func (m *MockWorkspaceService) GetMembers(workspaceID int) ([]entities.Membership, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", workspaceID)
+	ret0, _ := ret[0].([]entities.Membership)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockWorkspaceServiceMockRecorder) GetMembers(workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockWorkspaceService)(nil).GetMembers), workspaceID)
+}
+
+// GetRole mocks base method.
+func (m *MockWorkspaceService) GetRole(workspaceID, userID int) (enum.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", workspaceID, userID)
+	ret0, _ := ret[0].(enum.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockWorkspaceServiceMockRecorder) GetRole(workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockWorkspaceService)(nil).GetRole), workspaceID, userID)
+}
+
+// GetWorkspaces mocks base method.
+func (m *MockWorkspaceService) GetWorkspaces(userID int) ([]entities.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaces", userID)
+	ret0, _ := ret[0].([]entities.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaces indicates an expected call of GetWorkspaces.
+func (mr *MockWorkspaceServiceMockRecorder) GetWorkspaces(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaces", reflect.TypeOf((*MockWorkspaceService)(nil).GetWorkspaces), userID)
+}
+
+// LeaveWorkspace mocks base method.
+func (m *MockWorkspaceService) LeaveWorkspace(workspaceID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaveWorkspace", workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LeaveWorkspace indicates an expected call of LeaveWorkspace.
+func (mr *MockWorkspaceServiceMockRecorder) LeaveWorkspace(workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaveWorkspace", reflect.TypeOf((*MockWorkspaceService)(nil).LeaveWorkspace), workspaceID, userID)
+}
+
+// RemoveMember mocks base method.
+func (m *MockWorkspaceService) RemoveMember(workspaceID int, role enum.Role, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", workspaceID, role, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockWorkspaceServiceMockRecorder) RemoveMember(workspaceID, role, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockWorkspaceService)(nil).RemoveMember), workspaceID, role, userID)
+}
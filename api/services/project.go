@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// ProjectService manages the projects of a workspace; projects of other
+// workspaces read as missing.
+type ProjectService interface {
+	CreateProject(workspaceID int, req request.CreatedProjectRequest) error
+	GetProjects(workspaceID int, includeArchived bool) ([]entities.Project, error)
+	GetProject(workspaceID int, id int) (entities.Project, error)
+	UpdateProject(workspaceID int, id int, req request.UpdatedProjectRequest) error
+	DeleteProject(workspaceID int, id int) error
+}
+
+type projectService struct {
+	repository base.BaseRepository[any]
+	events     *TaskEvents
+	log        logger.Logger
+}
+
+func NewProjectService(repository base.BaseRepository[any], events *TaskEvents) ProjectService {
+	return &projectService{
+		repository: repository,
+		events:     events,
+		log:        logger.WithPrefix("service/project"),
+	}
+}
+
+func (s projectService) CreateProject(workspaceID int, req request.CreatedProjectRequest) error {
+	tn := time.Now()
+	project := entities.Project{
+		WorkspaceID: &workspaceID,
+		Name:        strings.TrimSpace(req.Name),
+		Color:       req.Color,
+		CreatedAt:   tn,
+		UpdatedAt:   tn,
+	}
+
+	return s.repository.Create(&project).Error()
+}
+
+func (s projectService) GetProjects(workspaceID int, includeArchived bool) ([]entities.Project, error) {
+	var projects []entities.Project
+	db := s.repository.Where("workspace_id = ?", workspaceID).Order("name")
+	if !includeArchived {
+		db = db.Where("archived = ?", false)
+	}
+
+	err := db.Find(&projects).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+func (s projectService) GetProject(workspaceID int, id int) (entities.Project, error) {
+	var project entities.Project
+	err := s.repository.Where("id = ? AND workspace_id = ?", id, workspaceID).First(&project).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Project{}, ErrProjectNotFound
+		}
+		return entities.Project{}, err
+	}
+
+	return project, nil
+}
+
+// UpdateProject replaces the project's fields. Archiving a project hides its
+// tasks from the default task list without touching the tasks themselves,
+// so they are only announced as updated.
+func (s projectService) UpdateProject(workspaceID int, id int, req request.UpdatedProjectRequest) error {
+	var changed []int
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var project entities.Project
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).Select("archived").Where("id = ? AND workspace_id = ?", id, workspaceID).First(&project).Error()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrProjectNotFound
+			}
+			return err
+		}
+
+		err = repository.Model(&entities.Project{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"name":       strings.TrimSpace(req.Name),
+			"color":      req.Color,
+			"archived":   req.Archived,
+			"updated_at": time.Now(),
+		}).Error()
+		if err != nil {
+			return err
+		}
+
+		if project.Archived == req.Archived {
+			return nil
+		}
+		return repository.Model(&entities.Task{}).Select("id").Where("project_id = ?", id).Scan(&changed).Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, changed...)
+	return nil
+}
+
+// DeleteProject removes the project and leaves its tasks without one. The
+// tasks get a new version since their project_id changes.
+func (s projectService) DeleteProject(workspaceID int, id int) error {
+	var changed []int
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var count int64
+		err := repository.Model(&entities.Project{}).Where("id = ? AND workspace_id = ?", id, workspaceID).Count(&count).Error()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrProjectNotFound
+		}
+
+		err = repository.Raw("UPDATE tasks SET project_id = NULL, version = version + 1, updated_at = ? WHERE project_id = ? RETURNING id", time.Now(), id).Scan(&changed).Error()
+		if err != nil {
+			return err
+		}
+
+		result := repository.Where("id = ?", id).Delete(&entities.Project{})
+		err = result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrProjectNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, changed...)
+	return nil
+}
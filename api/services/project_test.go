@@ -0,0 +1,461 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/base/mock"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func Test_projectService_CreateProject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		repository         *mock.MockBaseRepository[any]
+		repositoryBehavior func(*mock.MockBaseRepository[any])
+	}
+	type args struct {
+		req request.CreatedProjectRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Create(gomock.Any()).DoAndReturn(func(value interface{}) *mock.MockBaseRepository[any] {
+						project := value.(*entities.Project)
+						if project.Name != "Home" {
+							t.Errorf("project name = %q, want %q", project.Name, "Home")
+						}
+						if project.WorkspaceID == nil || *project.WorkspaceID != 3 {
+							t.Errorf("project workspace = %v, want 3", project.WorkspaceID)
+						}
+						return mbr
+					})
+					mbr.EXPECT().Error()
+				},
+			},
+			args: args{
+				req: request.CreatedProjectRequest{Name: " Home ", Color: "#ff0000"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Create(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: request.CreatedProjectRequest{Name: "Home"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.repository)
+			s := projectService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.CreateProject(3, tt.args.req); (err != nil) != tt.wantErr {
+				t.Errorf("projectService.CreateProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_projectService_GetProjects(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		includeArchived bool
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []entities.Project
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					rows := sqlmock.NewRows([]string{"id", "name", "color", "archived", "created_at", "updated_at"}).
+						AddRow(1, "Home", "#ff0000", false, tn, tn)
+					mock.ExpectQuery(`SELECT \* FROM "projects" WHERE workspace_id = \$1 AND archived = \$2 ORDER BY name`).
+						WithArgs(3, false).
+						WillReturnRows(rows)
+				},
+			},
+			args: args{
+				includeArchived: false,
+			},
+			want: []entities.Project{{
+				ID:        1,
+				Name:      "Home",
+				Color:     "#ff0000",
+				CreatedAt: tn,
+				UpdatedAt: tn,
+			}},
+			wantErr: false,
+		},
+		{
+			name: "success including archived",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					rows := sqlmock.NewRows([]string{"id", "name", "archived"}).
+						AddRow(1, "Home", true)
+					mock.ExpectQuery(`SELECT \* FROM "projects" WHERE workspace_id = \$1 ORDER BY name`).
+						WithArgs(3).
+						WillReturnRows(rows)
+				},
+			},
+			args: args{
+				includeArchived: true,
+			},
+			want:    []entities.Project{{ID: 1, Name: "Home", Archived: true}},
+			wantErr: false,
+		},
+		{
+			name: "find projects failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "projects"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := projectService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetProjects(3, tt.args.includeArchived)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("projectService.GetProjects() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("projectService.GetProjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_projectService_GetProject(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    entities.Project
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(1, 3, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Home"))
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Project{ID: 1, Name: "Home"},
+			wantErr: nil,
+		},
+		{
+			name: "project not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).WillReturnError(gorm.ErrRecordNotFound)
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Project{},
+			wantErr: ErrProjectNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := projectService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetProject(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("projectService.GetProject() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("projectService.GetProject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_projectService_UpdateProject(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id  int
+		req request.UpdatedProjectRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "archived",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT "archived" FROM "projects" WHERE id = \$1 AND workspace_id = \$2 ORDER BY "projects"."id" LIMIT \$3 FOR UPDATE`).
+						WithArgs(1, 3, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"archived"}).AddRow(false))
+					mock.ExpectExec(`UPDATE "projects" SET "archived"=\$1,"color"=\$2,"name"=\$3,"updated_at"=\$4 WHERE id = \$5`).
+						WithArgs(true, "#00ff00", "Work", sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`SELECT "id" FROM "tasks" WHERE project_id = \$1 AND "tasks"."deleted_at" IS NULL`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedProjectRequest{Name: "Work", Color: "#00ff00", Archived: true},
+			},
+			wantErr:       nil,
+			wantPublished: []int{4, 5},
+		},
+		{
+			name: "renamed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT "archived" FROM "projects"`).
+						WillReturnRows(sqlmock.NewRows([]string{"archived"}).AddRow(false))
+					mock.ExpectExec(`UPDATE "projects"`).
+						WithArgs(false, "", "Work", sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedProjectRequest{Name: "Work"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "project not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT "archived" FROM "projects"`).WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedProjectRequest{Name: "Work"},
+			},
+			wantErr: ErrProjectNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := projectService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.UpdateProject(3, tt.args.id, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("projectService.UpdateProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("projectService.UpdateProject() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func Test_projectService_DeleteProject(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(1, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectQuery(`UPDATE tasks SET project_id = NULL, version = version \+ 1, updated_at = \$1 WHERE project_id = \$2 RETURNING id`).
+						WithArgs(sqlmock.AnyArg(), 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+					mock.ExpectExec(`DELETE FROM "projects" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr:       nil,
+			wantPublished: []int{4, 5},
+		},
+		{
+			name: "project not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrProjectNotFound,
+		},
+		{
+			name: "project deleted in the meantime",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectQuery(`UPDATE tasks SET project_id = NULL`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectExec(`DELETE FROM "projects" WHERE id = \$1`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrProjectNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := projectService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteProject(3, tt.args.id); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("projectService.DeleteProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("projectService.DeleteProject() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
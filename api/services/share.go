@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/auth"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrInvalidShareLink  = errors.New("share link is invalid or revoked")
+	ErrShareLinkExpired  = errors.New("share link has expired")
+	ErrSharePassword     = errors.New("share link password is missing or wrong")
+)
+
+const DefaultShareTTL = 7 * 24 * time.Hour
+
+// shareAudience keeps share tokens and access tokens, which are signed with
+// the same secret, from being taken for one another.
+const shareAudience = "share"
+
+type shareClaims struct {
+	Audience  string `json:"aud"`
+	Subject   string `json:"sub"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type ShareService interface {
+	CreateShareLink(workspaceID int, taskID int, userID int, req request.CreatedShareLinkRequest) (response.CreatedShareLink, error)
+	// GetShareLinks returns the unexpired links of the task.
+	GetShareLinks(taskID int) ([]entities.ShareLink, error)
+	DeleteShareLink(taskID int, id int) error
+	// OpenShareLink returns the link token was signed for, provided it is
+	// neither revoked nor expired and password matches if it has one.
+	OpenShareLink(token string, password string) (entities.ShareLink, error)
+}
+
+type shareService struct {
+	repository base.BaseRepository[any]
+	secret     []byte
+	cost       int
+	log        logger.Logger
+}
+
+func NewShareService(repository base.BaseRepository[any], secret []byte) ShareService {
+	return &shareService{
+		repository: repository,
+		secret:     secret,
+		cost:       bcrypt.DefaultCost,
+		log:        logger.WithPrefix("service/share"),
+	}
+}
+
+func (s shareService) CreateShareLink(workspaceID int, taskID int, userID int, req request.CreatedShareLinkRequest) (response.CreatedShareLink, error) {
+	var count int64
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Model(&entities.Task{}).Where("id = ?", taskID).Count(&count).Error()
+	})
+	if err != nil {
+		return response.CreatedShareLink{}, err
+	}
+	if count == 0 {
+		return response.CreatedShareLink{}, ErrTaskNotFound
+	}
+
+	tn := time.Now()
+	link := entities.ShareLink{
+		TaskID:      taskID,
+		WorkspaceID: &workspaceID,
+		Access:      req.Access,
+		CreatedByID: userID,
+		ExpiresAt:   tn.Add(DefaultShareTTL),
+		CreatedAt:   tn,
+	}
+	if len(link.Access) == 0 {
+		link.Access = enum.ShareAccessRead
+	}
+	if req.ExpiresAt != nil {
+		link.ExpiresAt = *req.ExpiresAt
+	}
+	// the token carries whole seconds only
+	link.ExpiresAt = link.ExpiresAt.Truncate(time.Second)
+
+	if len(req.Password) > 0 {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.cost)
+		if err != nil {
+			return response.CreatedShareLink{}, err
+		}
+		link.PasswordHash = string(hash)
+		link.Protected = true
+	}
+
+	err = s.repository.Create(&link).Error()
+	if err != nil {
+		return response.CreatedShareLink{}, err
+	}
+
+	token, err := auth.Sign(shareClaims{
+		Audience:  shareAudience,
+		Subject:   strconv.Itoa(link.ID),
+		ExpiresAt: link.ExpiresAt.Unix(),
+	}, s.secret)
+	if err != nil {
+		return response.CreatedShareLink{}, err
+	}
+
+	return response.CreatedShareLink{ShareLink: link, Token: token}, nil
+}
+
+func (s shareService) GetShareLinks(taskID int) ([]entities.ShareLink, error) {
+	links := []entities.ShareLink{}
+	err := s.repository.Where("task_id = ? AND expires_at > ?", taskID, time.Now()).Order("created_at, id").Find(&links).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range links {
+		links[i].Protected = len(links[i].PasswordHash) > 0
+	}
+
+	return links, nil
+}
+
+func (s shareService) DeleteShareLink(taskID int, id int) error {
+	result := s.repository.Where("id = ? AND task_id = ?", id, taskID).Delete(&entities.ShareLink{})
+	err := result.Error()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrShareLinkNotFound
+	}
+
+	return nil
+}
+
+func (s shareService) OpenShareLink(token string, password string) (entities.ShareLink, error) {
+	var claims shareClaims
+	if err := auth.Parse(token, s.secret, &claims); err != nil || claims.Audience != shareAudience {
+		return entities.ShareLink{}, ErrInvalidShareLink
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return entities.ShareLink{}, ErrShareLinkExpired
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil || id <= 0 {
+		return entities.ShareLink{}, ErrInvalidShareLink
+	}
+
+	var link entities.ShareLink
+	err = s.repository.Where("id = ?", id).First(&link).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.ShareLink{}, ErrInvalidShareLink
+		}
+		return entities.ShareLink{}, err
+	}
+	if link.WorkspaceID == nil {
+		return entities.ShareLink{}, ErrInvalidShareLink
+	}
+
+	if len(link.PasswordHash) > 0 {
+		link.Protected = true
+		err = bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password))
+		if err != nil {
+			return entities.ShareLink{}, ErrSharePassword
+		}
+	}
+
+	return link, nil
+}
@@ -0,0 +1,460 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/auth"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var shareSecret = []byte("secret")
+
+func Test_shareService_CreateShareLink(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		countQuery  = `SELECT count\(\*\) FROM "tasks" WHERE id = \$1 AND "tasks"."deleted_at" IS NULL`
+		insertQuery = `INSERT INTO "share_links" \("task_id","workspace_id","access","password_hash","created_by_id","expires_at","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) RETURNING "id"`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		req request.CreatedShareLinkRequest
+	}
+	tests := []struct {
+		name       string
+		fields     fields
+		args       args
+		wantAccess enum.ShareAccess
+		wantErr    error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(countQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, 3, "read", "", 7, sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedShareLinkRequest{},
+			},
+			wantAccess: enum.ShareAccessRead,
+			wantErr:    nil,
+		},
+		{
+			name: "success with password",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(countQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(1, 3, "comment", sqlmock.AnyArg(), 7, expiresAt.Truncate(time.Second), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedShareLinkRequest{Access: enum.ShareAccessComment, ExpiresAt: &expiresAt, Password: "password"},
+			},
+			wantAccess: enum.ShareAccessComment,
+			wantErr:    nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(countQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedShareLinkRequest{},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(countQuery).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.CreatedShareLinkRequest{},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := shareService{
+				repository: tt.fields.repository,
+				secret:     shareSecret,
+				cost:       bcrypt.MinCost,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.CreateShareLink(3, 1, 7, tt.args.req)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("shareService.CreateShareLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Access != tt.wantAccess {
+				t.Errorf("shareService.CreateShareLink() access = %v, want %v", got.Access, tt.wantAccess)
+			}
+			if got.Protected != (len(tt.args.req.Password) > 0) {
+				t.Errorf("shareService.CreateShareLink() protected = %v", got.Protected)
+			}
+
+			var claims shareClaims
+			if err := auth.Parse(got.Token, shareSecret, &claims); err != nil {
+				t.Fatalf("shareService.CreateShareLink() token is not signed: %v", err)
+			}
+			if claims.Subject != "3" || claims.ExpiresAt != got.ExpiresAt.Unix() {
+				t.Errorf("shareService.CreateShareLink() claims = %+v, expires at %v", claims, got.ExpiresAt)
+			}
+		})
+	}
+}
+
+func Test_shareService_GetShareLinks(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.ShareLink
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					rows := sqlmock.NewRows([]string{"id", "task_id", "access", "password_hash", "created_by_id", "expires_at", "created_at"}).
+						AddRow(1, 1, "read", "", 7, tn, tn).
+						AddRow(2, 1, "comment", "hash", 7, tn, tn)
+					mock.ExpectQuery(`SELECT \* FROM "share_links" WHERE task_id = \$1 AND expires_at > \$2 ORDER BY created_at, id`).
+						WithArgs(1, sqlmock.AnyArg()).
+						WillReturnRows(rows)
+				},
+			},
+			want: []entities.ShareLink{
+				{ID: 1, TaskID: 1, Access: enum.ShareAccessRead, CreatedByID: 7, ExpiresAt: tn, CreatedAt: tn},
+				{ID: 2, TaskID: 1, Access: enum.ShareAccessComment, PasswordHash: "hash", CreatedByID: 7, ExpiresAt: tn, CreatedAt: tn, Protected: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "find failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "share_links"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := shareService{
+				repository: tt.fields.repository,
+				secret:     shareSecret,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetShareLinks(1)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shareService.GetShareLinks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shareService.GetShareLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_shareService_DeleteShareLink(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const deleteQuery = `DELETE FROM "share_links" WHERE id = \$1 AND task_id = \$2`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(deleteQuery).
+						WithArgs(2, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "link of another task",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectExec(deleteQuery).
+						WithArgs(2, 1).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: ErrShareLinkNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := shareService{
+				repository: tt.fields.repository,
+				secret:     shareSecret,
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.DeleteShareLink(1, 2)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("shareService.DeleteShareLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_shareService_OpenShareLink(t *testing.T) {
+	tn := time.Now()
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const findQuery = `SELECT \* FROM "share_links" WHERE id = \$1 ORDER BY "share_links"."id" LIMIT \$2`
+
+	token := func(claims shareClaims, secret []byte) string {
+		token, err := auth.Sign(claims, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := shareClaims{Audience: shareAudience, Subject: "2", ExpiresAt: tn.Add(time.Hour).Unix()}
+	rows := func(passwordHash string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "task_id", "workspace_id", "access", "password_hash", "created_by_id", "expires_at", "created_at"}).
+			AddRow(2, 1, 3, "read", passwordHash, 7, tn.Add(time.Hour), tn)
+	}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		token    string
+		password string
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WithArgs(2, 1).WillReturnRows(rows(""))
+				},
+			},
+			args: args{
+				token: token(valid, shareSecret),
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success with password",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WithArgs(2, 1).WillReturnRows(rows(string(hash)))
+				},
+			},
+			args: args{
+				token:    token(valid, shareSecret),
+				password: "password",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "wrong password",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WithArgs(2, 1).WillReturnRows(rows(string(hash)))
+				},
+			},
+			args: args{
+				token:    token(valid, shareSecret),
+				password: "wrong password",
+			},
+			wantErr: ErrSharePassword,
+		},
+		{
+			name: "revoked",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WithArgs(2, 1).WillReturnRows(sqlmock.NewRows(nil))
+				},
+			},
+			args: args{
+				token: token(valid, shareSecret),
+			},
+			wantErr: ErrInvalidShareLink,
+		},
+		{
+			name: "link from before workspaces",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WithArgs(2, 1).WillReturnRows(sqlmock.NewRows([]string{"id", "task_id", "workspace_id", "access", "created_by_id", "expires_at", "created_at"}).
+						AddRow(2, 1, nil, "read", 7, tn.Add(time.Hour), tn))
+				},
+			},
+			args: args{
+				token: token(valid, shareSecret),
+			},
+			wantErr: ErrInvalidShareLink,
+		},
+		{
+			name: "expired",
+			fields: fields{
+				repository:         base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {},
+			},
+			args: args{
+				token: token(shareClaims{Audience: shareAudience, Subject: "2", ExpiresAt: tn.Add(-time.Hour).Unix()}, shareSecret),
+			},
+			wantErr: ErrShareLinkExpired,
+		},
+		{
+			name: "signed with another secret",
+			fields: fields{
+				repository:         base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {},
+			},
+			args: args{
+				token: token(valid, []byte("another secret")),
+			},
+			wantErr: ErrInvalidShareLink,
+		},
+		{
+			name: "access token",
+			fields: fields{
+				repository:         base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {},
+			},
+			args: args{
+				token: token(shareClaims{Subject: "2", ExpiresAt: valid.ExpiresAt}, shareSecret),
+			},
+			wantErr: ErrInvalidShareLink,
+		},
+		{
+			name: "find failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WillReturnError(errors.New("foo"))
+				},
+			},
+			args: args{
+				token: token(valid, shareSecret),
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := shareService{
+				repository: tt.fields.repository,
+				secret:     shareSecret,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.OpenShareLink(tt.args.token, tt.args.password)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("shareService.OpenShareLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.ID != 2 {
+				t.Errorf("shareService.OpenShareLink() = %v", got)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTagNotFound  = errors.New("tag not found")
+	ErrTagExists    = errors.New("tag already exists")
+	ErrTagMergeSelf = errors.New("tag cannot be merged into itself")
+)
+
+// uniqueViolation is the SQLSTATE Postgres reports when a unique index such
+// as the one on tags.name rejects a row.
+const uniqueViolation = "23505"
+
+// TagService manages the tags of a workspace; tags of other workspaces
+// read as missing.
+type TagService interface {
+	CreateTag(workspaceID int, req request.CreatedTagRequest) error
+	GetTags(workspaceID int) ([]entities.Tag, error)
+	GetTag(workspaceID int, id int) (entities.Tag, error)
+	UpdateTag(workspaceID int, id int, req request.UpdatedTagRequest) error
+	DeleteTag(workspaceID int, id int) error
+	MergeTag(workspaceID int, id int, req request.MergedTagRequest) error
+}
+
+type tagService struct {
+	repository base.BaseRepository[any]
+	events     *TaskEvents
+	log        logger.Logger
+}
+
+func NewTagService(repository base.BaseRepository[any], events *TaskEvents) TagService {
+	return &tagService{
+		repository: repository,
+		events:     events,
+		log:        logger.WithPrefix("service/tag"),
+	}
+}
+
+func (s tagService) CreateTag(workspaceID int, req request.CreatedTagRequest) error {
+	tn := time.Now()
+	tag := entities.Tag{
+		WorkspaceID: &workspaceID,
+		Name:        strings.TrimSpace(req.Name),
+		CreatedAt:   tn,
+		UpdatedAt:   tn,
+	}
+
+	err := s.repository.Create(&tag).Error()
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrTagExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s tagService) GetTags(workspaceID int) ([]entities.Tag, error) {
+	var tags []entities.Tag
+	err := s.repository.Where("workspace_id = ?", workspaceID).Order("name").Find(&tags).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+func (s tagService) GetTag(workspaceID int, id int) (entities.Tag, error) {
+	var tag entities.Tag
+	err := s.repository.Where("id = ? AND workspace_id = ?", id, workspaceID).First(&tag).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Tag{}, ErrTagNotFound
+		}
+		return entities.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+// UpdateTag renames a tag. Every task carrying it gets a new version in the
+// same transaction, since the tag is part of the task's representation.
+func (s tagService) UpdateTag(workspaceID int, id int, req request.UpdatedTagRequest) error {
+	var touched []int
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		result := repository.Model(&entities.Tag{}).Where("id = ? AND workspace_id = ?", id, workspaceID).Updates(map[string]interface{}{
+			"name":       strings.TrimSpace(req.Name),
+			"updated_at": time.Now(),
+		})
+		err := result.Error()
+		if err != nil {
+			if isUniqueViolation(err) {
+				return ErrTagExists
+			}
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTagNotFound
+		}
+
+		touched, err = touchTaggedTasks(repository, id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, touched...)
+	return nil
+}
+
+func (s tagService) DeleteTag(workspaceID int, id int) error {
+	var touched []int
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var count int64
+		err := repository.Model(&entities.Tag{}).Where("id = ? AND workspace_id = ?", id, workspaceID).Count(&count).Error()
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrTagNotFound
+		}
+
+		touched, err = touchTaggedTasks(repository, id)
+		if err != nil {
+			return err
+		}
+
+		err = repository.Exec("DELETE FROM task_tags WHERE tag_id = ?", id).Error()
+		if err != nil {
+			return err
+		}
+
+		result := repository.Where("id = ?", id).Delete(&entities.Tag{})
+		err = result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTagNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, touched...)
+	return nil
+}
+
+// MergeTag moves every task from the tag onto req.IntoID and removes the tag.
+func (s tagService) MergeTag(workspaceID int, id int, req request.MergedTagRequest) error {
+	if req.IntoID == id {
+		return ErrTagMergeSelf
+	}
+
+	var touched []int
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var count int64
+		err := repository.Model(&entities.Tag{}).Where("id IN ? AND workspace_id = ?", []int{id, req.IntoID}, workspaceID).Count(&count).Error()
+		if err != nil {
+			return err
+		}
+		if count < 2 {
+			return ErrTagNotFound
+		}
+
+		touched, err = touchTaggedTasks(repository, id)
+		if err != nil {
+			return err
+		}
+
+		err = repository.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ? ON CONFLICT DO NOTHING", req.IntoID, id).Error()
+		if err != nil {
+			return err
+		}
+
+		err = repository.Exec("DELETE FROM task_tags WHERE tag_id = ?", id).Error()
+		if err != nil {
+			return err
+		}
+
+		return repository.Where("id = ?", id).Delete(&entities.Tag{}).Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, touched...)
+	return nil
+}
+
+// touchTaggedTasks bumps the version of every task carrying the tag so their
+// ETags change along with it, and returns their IDs.
+func touchTaggedTasks(repository base.BaseRepository[any], tagID int) ([]int, error) {
+	var ids []int
+	err := repository.Raw("UPDATE tasks SET version = version + 1, updated_at = ? WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = ?) RETURNING id", time.Now(), tagID).Scan(&ids).Error()
+	return ids, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
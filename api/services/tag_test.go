@@ -0,0 +1,556 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/base/mock"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func Test_tagService_CreateTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		repository         *mock.MockBaseRepository[any]
+		repositoryBehavior func(*mock.MockBaseRepository[any])
+	}
+	type args struct {
+		req request.CreatedTagRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Create(gomock.Any()).DoAndReturn(func(value interface{}) *mock.MockBaseRepository[any] {
+						tag := value.(*entities.Tag)
+						if tag.Name != "bug" {
+							t.Errorf("tag name = %q, want %q", tag.Name, "bug")
+						}
+						if tag.WorkspaceID == nil || *tag.WorkspaceID != 3 {
+							t.Errorf("tag workspace = %v, want 3", tag.WorkspaceID)
+						}
+						return mbr
+					})
+					mbr.EXPECT().Error()
+				},
+			},
+			args: args{
+				req: request.CreatedTagRequest{Name: " bug "},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "tag exists",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Create(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(&pgconn.PgError{Code: "23505"})
+				},
+			},
+			args: args{
+				req: request.CreatedTagRequest{Name: "bug"},
+			},
+			wantErr: ErrTagExists,
+		},
+		{
+			name: "create failed",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Create(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				req: request.CreatedTagRequest{Name: "bug"},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.repository)
+			s := tagService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.CreateTag(3, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("tagService.CreateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_tagService_GetTags(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.Tag
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					rows := sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+						AddRow(1, "bug", tn, tn)
+					mock.ExpectQuery(`SELECT \* FROM "tags" WHERE workspace_id = \$1 ORDER BY name`).
+						WithArgs(3).
+						WillReturnRows(rows)
+				},
+			},
+			want: []entities.Tag{{
+				ID:        1,
+				Name:      "bug",
+				CreatedAt: tn,
+				UpdatedAt: tn,
+			}},
+			wantErr: false,
+		},
+		{
+			name: "find tags failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "tags" ORDER BY name`).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := tagService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetTags(3)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("tagService.GetTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagService.GetTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tagService_GetTag(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    entities.Tag
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "tags" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(1, 3, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "bug"))
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Tag{ID: 1, Name: "bug"},
+			wantErr: nil,
+		},
+		{
+			name: "tag not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "tags" WHERE id = \$1 AND workspace_id = \$2`).WillReturnError(gorm.ErrRecordNotFound)
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Tag{},
+			wantErr: ErrTagNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := tagService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetTag(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("tagService.GetTag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagService.GetTag() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tagService_UpdateTag(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id  int
+		req request.UpdatedTagRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tags" SET "name"=\$1,"updated_at"=\$2 WHERE id = \$3 AND workspace_id = \$4`).
+						WithArgs("defect", sqlmock.AnyArg(), 1, 3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`UPDATE tasks SET version = version \+ 1, updated_at = \$1 WHERE id IN \(SELECT task_id FROM task_tags WHERE tag_id = \$2\) RETURNING id`).
+						WithArgs(sqlmock.AnyArg(), 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5).AddRow(6))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedTagRequest{Name: "defect"},
+			},
+			wantErr:       nil,
+			wantPublished: []int{4, 5, 6},
+		},
+		{
+			name: "tag not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tags" SET "name"=\$1,"updated_at"=\$2 WHERE id = \$3 AND workspace_id = \$4`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedTagRequest{Name: "defect"},
+			},
+			wantErr: ErrTagNotFound,
+		},
+		{
+			name: "tag exists",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tags" SET "name"=\$1,"updated_at"=\$2 WHERE id = \$3 AND workspace_id = \$4`).
+						WillReturnError(&pgconn.PgError{Code: "23505"})
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.UpdatedTagRequest{Name: "defect"},
+			},
+			wantErr: ErrTagExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := tagService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.UpdateTag(3, tt.args.id, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("tagService.UpdateTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("tagService.UpdateTag() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func Test_tagService_DeleteTag(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tags" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(1, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectQuery(`UPDATE tasks SET version = version \+ 1`).
+						WithArgs(sqlmock.AnyArg(), 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5).AddRow(6))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE tag_id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 3))
+					mock.ExpectExec(`DELETE FROM "tags" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr:       nil,
+			wantPublished: []int{4, 5, 6},
+		},
+		{
+			name: "tag not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tags"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrTagNotFound,
+		},
+		{
+			name: "tag deleted in the meantime",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tags"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectQuery(`UPDATE tasks SET version = version \+ 1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE tag_id = \$1`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`DELETE FROM "tags" WHERE id = \$1`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrTagNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := tagService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteTag(3, tt.args.id); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("tagService.DeleteTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("tagService.DeleteTag() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func Test_tagService_MergeTag(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id  int
+		req request.MergedTagRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+		// wantPublished are the tasks announced as updated
+		wantPublished []int
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tags" WHERE id IN \(\$1,\$2\) AND workspace_id = \$3`).
+						WithArgs(1, 2, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+					mock.ExpectQuery(`UPDATE tasks SET version = version \+ 1`).
+						WithArgs(sqlmock.AnyArg(), 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5).AddRow(6))
+					mock.ExpectExec(`INSERT INTO task_tags \(task_id, tag_id\) SELECT task_id, \$1 FROM task_tags WHERE tag_id = \$2 ON CONFLICT DO NOTHING`).
+						WithArgs(2, 1).
+						WillReturnResult(sqlmock.NewResult(0, 3))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE tag_id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 3))
+					mock.ExpectExec(`DELETE FROM "tags" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.MergedTagRequest{IntoID: 2},
+			},
+			wantErr:       nil,
+			wantPublished: []int{4, 5, 6},
+		},
+		{
+			name: "merge into itself",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.MergedTagRequest{IntoID: 1},
+			},
+			wantErr: ErrTagMergeSelf,
+		},
+		{
+			name: "either tag not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tags" WHERE id IN \(\$1,\$2\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.MergedTagRequest{IntoID: 2},
+			},
+			wantErr: ErrTagNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := tagService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.MergeTag(3, tt.args.id, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("tagService.MergeTag() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventUpdated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("tagService.MergeTag() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
@@ -1,65 +1,527 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 	"todo/api/entities"
+	"todo/api/enum"
 	"todo/api/models/request"
+	"todo/api/models/response"
 	"todo/pkg/base"
+	"todo/pkg/database"
 	"todo/pkg/logger"
+	"todo/pkg/pagination"
+	"todo/pkg/storage"
+	"todo/pkg/workflow"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTaskNotFound        = errors.New("task not found")
+	ErrTaskVersionMismatch = errors.New("task has been modified")
+	ErrInvalidSchedule     = errors.New("start at must be before due at")
+	ErrParentNotFound      = errors.New("parent task not found")
+	ErrTaskCycle           = errors.New("task cannot be moved under itself or its descendants")
+	ErrTaskBlocked         = errors.New("task has incomplete blockers")
+	ErrBlockerNotFound     = errors.New("blocking task not found")
+	ErrDependencyNotFound  = errors.New("dependency not found")
+	ErrDependencyCycle     = errors.New("dependency would create a cycle")
+)
+
+type InvalidTransitionError struct {
+	From    enum.TaskStatus
+	To      enum.TaskStatus
+	Allowed []enum.TaskStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("status cannot change from %s to %s", e.From, e.To)
+}
+
+// checkViolation is the SQLSTATE Postgres reports when a CHECK constraint
+// such as chk_tasks_schedule rejects a row.
+const checkViolation = "23514"
+
+// Edits to the task hierarchy and the dependency graph are serialized with
+// transaction-level advisory locks; two concurrent edits could otherwise
+// each pass the cycle check and together form a loop.
+const (
+	hierarchyLockKey  = 0x7461736b
+	dependencyLockKey = 0x7461736c
 )
 
+// openBlockersQuery matches the blockers of tasks.id that haven't reached a
+// terminal status yet.
+const openBlockersQuery = `SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocked_by_id AND b.deleted_at IS NULL
+	WHERE d.task_id = tasks.id AND b.status NOT IN ?`
+
 type TaskService interface {
 	CreateTask(req request.CreatedTaskRequest) error
-	GetTasks(query request.TaskListQuery) ([]entities.Task, error)
-	UpdateTask(id int, req request.UpdatedTaskRequest) error
+	GetTasks(query request.TaskListQuery) ([]entities.Task, response.Pagination, error)
+	// WatchTasks streams the changes to the tasks listed by query, starting
+	// after the event with ID after, or with the next change when it is 0.
+	WatchTasks(ctx context.Context, query request.TaskListQuery, after int64) (<-chan response.TaskEvent, error)
+	GetTask(workspaceID int, id int) (entities.Task, error)
+	CheckWorkspace(id int, workspaceID int) error
+	GetChildren(workspaceID int, id int) ([]entities.Task, error)
+	GetTaskTree(workspaceID int, id int) (entities.Task, error)
+	MoveTask(workspaceID int, id int, version int, req request.MovedTaskRequest) error
+	GetDependencies(workspaceID int, id int) (response.TaskDependencies, error)
+	AddDependency(workspaceID int, id int, req request.CreatedDependencyRequest) error
+	RemoveDependency(workspaceID int, id int, blockedByID int) error
+	AssignProject(workspaceID int, id int, version int, req request.AssignedProjectRequest) error
+	UpdateTask(workspaceID int, id int, version int, req request.UpdatedTaskRequest) error
+	PatchTask(workspaceID int, id int, version int, req request.PatchedTaskRequest) error
+	DeleteTask(workspaceID int, id int, version int) error
+	GetTrashedTasks(workspaceID int) ([]entities.Task, error)
+	RestoreTask(workspaceID int, id int) error
+	PurgeTask(workspaceID int, id int) error
+	// PurgeExpiredTasks purges the tasks of every workspace trashed before
+	// before.
+	PurgeExpiredTasks(before time.Time) (int64, error)
 }
 
 type taskService struct {
 	repository base.BaseRepository[any]
+	store      storage.BlobStore
+	workflow   workflow.Workflow
+	lists      *taskListCache
+	events     *TaskEvents
 	log        logger.Logger
 }
 
-func NewTaskService(repository base.BaseRepository[any]) TaskService {
+// NewTaskService publishes changes to events and reads task lists from its
+// cache. Purged tasks take their blobs in store with them.
+func NewTaskService(repository base.BaseRepository[any], store storage.BlobStore, wf workflow.Workflow, events *TaskEvents) TaskService {
 	return &taskService{
 		repository: repository,
+		store:      store,
+		workflow:   wf,
+		lists:      events.taskLists(),
+		events:     events,
 		log:        logger.WithPrefix("service/task"),
 	}
 }
 
+// CreateTask writes under the row-level security of the workspace, so the
+// task can't land in another one.
 func (s taskService) CreateTask(req request.CreatedTaskRequest) error {
+	priority := req.Priority
+	if len(priority) == 0 {
+		priority = enum.TaskPriorityMedium
+	}
+
 	tn := time.Now()
 	task := entities.Task{
+		ParentID:    req.ParentID,
+		ProjectID:   req.ProjectID,
+		WorkspaceID: &req.WorkspaceID,
+		OwnerID:     &req.OwnerID,
 		Title:       req.Title,
 		Description: req.Description,
 		CreatedAt:   tn,
 		UpdatedAt:   tn,
-		Image:       req.Image,
 		Status:      req.Status,
+		Priority:    priority,
+		StartAt:     req.StartAt,
+		DueAt:       req.DueAt,
+		Version:     1,
 	}
+	if s.workflow.IsTerminal(req.Status) {
+		task.CompletedAt = &tn
+	}
+
+	err := database.InWorkspace(s.repository, req.WorkspaceID, func(repository base.BaseRepository[any]) error {
+		if req.ParentID != nil {
+			var count int64
+			err := repository.Model(&entities.Task{}).Where("id = ? AND workspace_id = ?", *req.ParentID, req.WorkspaceID).Count(&count).Error()
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				return ErrParentNotFound
+			}
+		}
+		if req.ProjectID != nil {
+			err := s.checkProject(repository, req.WorkspaceID, *req.ProjectID)
+			if err != nil {
+				return err
+			}
+		}
 
-	err := s.repository.Create(&task).Error()
+		err := repository.Create(&task).Error()
+		if err != nil {
+			return err
+		}
+		if len(req.Tags) == 0 {
+			return nil
+		}
+		return replaceTags(repository, req.WorkspaceID, task.ID, req.Tags)
+	})
 	if err != nil {
 		return err
 	}
+
+	s.events.publish(enum.TaskEventCreated, req.WorkspaceID, task.ID)
 	return nil
 }
 
-func (s taskService) GetTasks(query request.TaskListQuery) ([]entities.Task, error) {
+// GetTasks lists under the row-level security of the workspace, on top of
+// filtering on it. Lists are cached, comment counts are not; they change
+// with every comment and are counted afresh.
+func (s taskService) GetTasks(query request.TaskListQuery) ([]entities.Task, response.Pagination, error) {
+	key, list, ok := s.lists.get(query)
+	if !ok {
+		err := database.InWorkspace(s.repository, query.WorkspaceID, func(repository base.BaseRepository[any]) error {
+			var err error
+			list.Tasks, list.Pagination, err = s.listTasks(repository, query)
+			return err
+		})
+		if err != nil {
+			return nil, response.Pagination{}, err
+		}
+		s.lists.set(key, list)
+	}
+	tasks, page := list.Tasks, list.Pagination
+
+	err := s.countComments(tasks)
+	if err != nil {
+		return nil, response.Pagination{}, err
+	}
+
+	return tasks, page, nil
+}
+
+func (s taskService) listTasks(repository base.BaseRepository[any], query request.TaskListQuery) ([]entities.Task, response.Pagination, error) {
 	var tasks []entities.Task
-	db := s.repository
+	db, err := s.filterTasks(repository.Model(&entities.Task{}).Where("workspace_id = ?", query.WorkspaceID), query)
+	if err != nil {
+		return nil, response.Pagination{}, err
+	}
+
+	var total int64
+	err = db.Count(&total).Error()
+	if err != nil {
+		return nil, response.Pagination{}, err
+	}
+
+	column, order := sortColumn(query)
+	if len(query.Cursor) > 0 {
+		cursor, err := pagination.Decode(query.Cursor)
+		if err != nil {
+			return nil, response.Pagination{}, err
+		}
+
+		op := ">"
+		if order == enum.SortOrderDesc {
+			op = "<"
+		}
+
+		if column == sortByID {
+			db = db.Where(fmt.Sprintf("id %s ?", op), cursor.ID)
+		} else {
+			value, err := cursorValue(column, cursor.Value)
+			if err != nil {
+				return nil, response.Pagination{}, err
+			}
+			db = db.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortExpression(column), op), value, cursor.ID)
+		}
+	}
+
+	limit := query.Limit()
+	if query.Page > 1 {
+		db = db.Offset((query.Page - 1) * limit)
+	}
+
+	if column != sortByID {
+		db = db.Order(fmt.Sprintf("%s %s", sortExpression(column), order))
+	}
+	err = db.Order(fmt.Sprintf("id %s", order)).Limit(limit + 1).Preload("Tags").Find(&tasks).Error()
+	if err != nil {
+		return nil, response.Pagination{}, err
+	}
+
+	page := response.Pagination{
+		Page:     query.Page,
+		PageSize: limit,
+		Total:    total,
+	}
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+		last := tasks[limit-1]
+		page.NextCursor = pagination.Encode(pagination.Cursor{
+			Value:     sortValue(last, column),
+			ID:        last.ID,
+			SortBy:    string(query.SortBy),
+			SortOrder: string(order),
+		})
+	}
 
+	return tasks, page, nil
+}
+
+// filterTasks narrows db to the tasks matching the filters of query.
+func (s taskService) filterTasks(db base.BaseRepository[any], query request.TaskListQuery) (base.BaseRepository[any], error) {
 	if len(query.Title) > 0 {
 		db = db.Where("title LIKE ?", fmt.Sprintf("%s%%", query.Title))
 	}
 	if len(query.Description) > 0 {
 		db = db.Where("description LIKE ?", fmt.Sprintf("%s%%", query.Description))
 	}
-	if len(query.SortOrder) > 0 && len(query.SortBy) > 0 {
-		db = db.Order(fmt.Sprintf("%s %s", query.SortBy, query.SortOrder))
+
+	dueBefore, dueAfter, err := query.DueRange()
+	if err != nil {
+		return nil, err
+	}
+	if dueBefore != nil {
+		db = db.Where("due_at < ?", *dueBefore)
+	}
+	if dueAfter != nil {
+		db = db.Where("due_at > ?", *dueAfter)
+	}
+	if len(query.Priorities) > 0 {
+		db = db.Where("priority IN ?", query.Priorities)
+	}
+	if query.Overdue {
+		db = db.Where("due_at < ? AND status NOT IN ?", time.Now(), s.workflow.Terminal())
+	}
+	if query.Blocked != nil {
+		blocked := fmt.Sprintf("EXISTS (%s)", openBlockersQuery)
+		if !*query.Blocked {
+			blocked = "NOT " + blocked
+		}
+		db = db.Where(blocked, s.workflow.Terminal())
+	}
+	if tags := tagNames(query.Tags); len(tags) > 0 {
+		if query.TagMode == enum.TagModeAll {
+			db = db.Where("(SELECT count(*) FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id = tasks.id AND t.name IN ?) = ?", tags, len(tags))
+		} else {
+			db = db.Where("EXISTS (SELECT 1 FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id = tasks.id AND t.name IN ?)", tags)
+		}
+	}
+	if query.ProjectID != nil {
+		db = db.Where("project_id = ?", *query.ProjectID)
+	} else if !query.IncludeArchived {
+		db = db.Where("project_id IS NULL OR project_id NOT IN (SELECT id FROM projects WHERE archived)")
+	}
+
+	return db.Session(&gorm.Session{}), nil
+}
+
+// countComments sets the comment count of every task with one grouped query.
+func (s taskService) countComments(tasks []entities.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+
+	var counts []struct {
+		TaskID int
+		Count  int64
+	}
+	err := s.repository.Model(&entities.Comment{}).Select("task_id, count(*) AS count").Where("task_id IN ?", ids).Group("task_id").Scan(&counts).Error()
+	if err != nil {
+		return err
+	}
+
+	byTask := make(map[int]int64, len(counts))
+	for _, c := range counts {
+		byTask[c.TaskID] = c.Count
+	}
+	for i := range tasks {
+		count := byTask[tasks[i].ID]
+		tasks[i].CommentCount = &count
+	}
+
+	return nil
+}
+
+// sortByID is not exposed to clients; it's the fallback so keyset pagination
+// always has a stable column to seek on.
+const sortByID enum.TaskListSortBy = "id"
+
+func sortColumn(query request.TaskListQuery) (enum.TaskListSortBy, enum.SortOrder) {
+	column := sortByID
+	if len(query.SortBy) > 0 {
+		column = query.SortBy
+	}
+
+	order := enum.SortOrderAsc
+	if len(query.SortOrder) > 0 {
+		order = query.SortOrder
+	}
+
+	return column, order
+}
+
+// sortExpression maps nullable columns onto a total order so the keyset row
+// comparison never meets a NULL. Tasks without a due date sort last.
+func sortExpression(column enum.TaskListSortBy) string {
+	switch column {
+	case enum.TaskListSortByDueAt:
+		return "COALESCE(due_at, 'infinity')"
+	case enum.TaskListSortByPriority:
+		return priorityRankExpression
+	}
+	return string(column)
+}
+
+// priorityRankExpression sorts priorities by enum.TaskPriority.Rank instead
+// of alphabetically.
+var priorityRankExpression = func() string {
+	var b strings.Builder
+	b.WriteString("CASE priority")
+	for _, p := range enum.TaskPriorities {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", p, p.Rank())
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String()
+}()
+
+func sortValue(task entities.Task, column enum.TaskListSortBy) string {
+	switch column {
+	case enum.TaskListSortByTitle:
+		return task.Title
+	case enum.TaskListSortByStatus:
+		return string(task.Status)
+	case enum.TaskListSortByCreatedAt:
+		return task.CreatedAt.Format(time.RFC3339Nano)
+	case enum.TaskListSortByUpdatedAt:
+		return task.UpdatedAt.Format(time.RFC3339Nano)
+	case enum.TaskListSortByPriority:
+		return strconv.Itoa(task.Priority.Rank())
+	case enum.TaskListSortByDueAt:
+		if task.DueAt == nil {
+			return "infinity"
+		}
+		return task.DueAt.Format(time.RFC3339Nano)
+	}
+	return ""
+}
+
+func cursorValue(column enum.TaskListSortBy, value string) (interface{}, error) {
+	switch column {
+	case enum.TaskListSortByCreatedAt, enum.TaskListSortByUpdatedAt, enum.TaskListSortByDueAt:
+		if column == enum.TaskListSortByDueAt && value == "infinity" {
+			return value, nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		return t, nil
+	case enum.TaskListSortByPriority:
+		rank, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		return rank, nil
+	}
+	return value, nil
+}
+
+func (s taskService) GetTask(workspaceID int, id int) (entities.Task, error) {
+	var task entities.Task
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var err error
+		task, err = s.findTask(repository, id, "Tags")
+		if err != nil {
+			return err
+		}
+
+		var p struct {
+			Total     int64
+			Completed int64
+		}
+		err = repository.Raw(`WITH RECURSIVE descendants AS (
+			SELECT id, status FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT t.id, t.status FROM tasks t JOIN descendants d ON t.parent_id = d.id WHERE t.deleted_at IS NULL
+		)
+		SELECT count(*) FILTER (WHERE status <> ?) AS total, count(*) FILTER (WHERE status = ?) AS completed FROM descendants`,
+			id, enum.TaskStatusCancelled, enum.TaskStatusCompleted).Scan(&p).Error()
+		if err != nil {
+			return err
+		}
+		task.Progress = progress(p.Completed, p.Total)
+
+		return nil
+	})
+	if err != nil {
+		return entities.Task{}, err
 	}
 
-	err := db.Find(&tasks).Error()
+	return task, nil
+}
+
+// CheckWorkspace reports ErrTaskNotFound unless the task, trashed or not,
+// belongs to the workspace.
+func (s taskService) CheckWorkspace(id int, workspaceID int) error {
+	var count int64
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Unscoped().Model(&entities.Task{}).Where("id = ? AND workspace_id = ?", id, workspaceID).Count(&count).Error()
+	})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+
+	return nil
+}
+
+func (s taskService) findTask(repository base.BaseRepository[any], id int, preloads ...string) (entities.Task, error) {
+	var task entities.Task
+	db := repository.Where("id = ?", id)
+	for _, preload := range preloads {
+		db = db.Preload(preload)
+	}
+
+	err := db.First(&task).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entities.Task{}, ErrTaskNotFound
+		}
+		return entities.Task{}, err
+	}
+
+	return task, nil
+}
+
+// progress is the completed share of descendants in percent; cancelled
+// descendants don't count towards it.
+func progress(completed, total int64) *float64 {
+	if total == 0 {
+		return nil
+	}
+	percent := math.Round(float64(completed)/float64(total)*10000) / 100
+	return &percent
+}
+
+func (s taskService) GetChildren(workspaceID int, id int) ([]entities.Task, error) {
+	var tasks []entities.Task
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		_, err := s.findTask(repository, id)
+		if err != nil {
+			return err
+		}
+
+		return repository.Where("parent_id = ?", id).Order("id").Find(&tasks).Error()
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -67,26 +529,680 @@ func (s taskService) GetTasks(query request.TaskListQuery) ([]entities.Task, err
 	return tasks, nil
 }
 
-func (s taskService) UpdateTask(id int, req request.UpdatedTaskRequest) error {
+func (s taskService) GetTaskTree(workspaceID int, id int) (entities.Task, error) {
+	var task entities.Task
+	var descendants []entities.Task
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var err error
+		task, err = s.findTask(repository, id)
+		if err != nil {
+			return err
+		}
+
+		return repository.Raw(`WITH RECURSIVE tree AS (
+			SELECT * FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+			UNION
+			SELECT t.* FROM tasks t JOIN tree ON t.parent_id = tree.id WHERE t.deleted_at IS NULL
+		)
+		SELECT * FROM tree ORDER BY id`, id).Find(&descendants).Error()
+	})
+	if err != nil {
+		return entities.Task{}, err
+	}
+
+	children := make(map[int][]entities.Task)
+	for _, d := range descendants {
+		children[*d.ParentID] = append(children[*d.ParentID], d)
+	}
+	buildTree(&task, children)
+
+	return task, nil
+}
+
+// buildTree attaches children to task recursively and returns how many of
+// its descendants count towards progress and how many are completed.
+func buildTree(task *entities.Task, children map[int][]entities.Task) (int64, int64) {
+	var total, completed int64
+	for _, child := range children[task.ID] {
+		t, c := buildTree(&child, children)
+		total += t
+		completed += c
+		if child.Status != enum.TaskStatusCancelled {
+			total++
+		}
+		if child.Status == enum.TaskStatusCompleted {
+			completed++
+		}
+		task.Children = append(task.Children, child)
+	}
+	task.Progress = progress(completed, total)
+
+	return total, completed
+}
+
+// MoveTask puts the task and its subtree under a new parent, or makes it a
+// root task when the parent is nil.
+func (s taskService) MoveTask(workspaceID int, id int, version int, req request.MovedTaskRequest) error {
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		err := repository.Exec("SELECT pg_advisory_xact_lock(?)", hierarchyLockKey).Error()
+		if err != nil {
+			return err
+		}
+
+		if req.ParentID != nil {
+			if *req.ParentID == id {
+				return ErrTaskCycle
+			}
+
+			// the parent must live in the workspace of the task
+			var ancestors []int
+			err = repository.Raw(`WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM tasks WHERE id = ? AND deleted_at IS NULL
+					AND workspace_id = (SELECT workspace_id FROM tasks WHERE id = ?)
+				UNION
+				SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+			)
+			SELECT id FROM ancestors`, *req.ParentID, id).Scan(&ancestors).Error()
+			if err != nil {
+				return err
+			}
+			if len(ancestors) == 0 {
+				return ErrParentNotFound
+			}
+			for _, ancestor := range ancestors {
+				if ancestor == id {
+					return ErrTaskCycle
+				}
+			}
+		}
+
+		db := repository.Model(&entities.Task{}).Where("id = ?", id)
+		if version > 0 {
+			db = db.Where("version = ?", version)
+		}
+
+		result := db.Updates(map[string]interface{}{
+			"parent_id":  req.ParentID,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		})
+		err = result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return s.unmodifiedError(repository, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, id)
+	return nil
+}
+
+func (s taskService) GetDependencies(workspaceID int, id int) (response.TaskDependencies, error) {
+	dependencies := response.TaskDependencies{
+		BlockedBy: []entities.Task{},
+		Blocks:    []entities.Task{},
+	}
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		_, err := s.findTask(repository, id)
+		if err != nil {
+			return err
+		}
+
+		err = repository.Where("id IN (SELECT blocked_by_id FROM task_dependencies WHERE task_id = ?)", id).Order("id").Find(&dependencies.BlockedBy).Error()
+		if err != nil {
+			return err
+		}
+		return repository.Where("id IN (SELECT task_id FROM task_dependencies WHERE blocked_by_id = ?)", id).Order("id").Find(&dependencies.Blocks).Error()
+	})
+	if err != nil {
+		return response.TaskDependencies{}, err
+	}
+
+	return dependencies, nil
+}
+
+// AddDependency records that the task is blocked by req.BlockedByID. Adding
+// an edge that already exists is a no-op.
+func (s taskService) AddDependency(workspaceID int, id int, req request.CreatedDependencyRequest) error {
+	if req.BlockedByID == id {
+		return ErrDependencyCycle
+	}
+
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		err := repository.Exec("SELECT pg_advisory_xact_lock(?)", dependencyLockKey).Error()
+		if err != nil {
+			return err
+		}
+
+		// a blocker in another workspace reads as a missing one
+		var ids []int
+		err = repository.Model(&entities.Task{}).
+			Where("id IN ? AND workspace_id = (SELECT workspace_id FROM tasks WHERE id = ?)", []int{id, req.BlockedByID}, id).
+			Select("id").Scan(&ids).Error()
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(ids, id) {
+			return ErrTaskNotFound
+		}
+		if !slices.Contains(ids, req.BlockedByID) {
+			return ErrBlockerNotFound
+		}
+
+		// the new edge closes a loop if the blocker already waits on the
+		// task, directly or through other blockers
+		var count int64
+		err = repository.Raw(`WITH RECURSIVE blockers AS (
+			SELECT blocked_by_id AS id FROM task_dependencies WHERE task_id = ?
+			UNION
+			SELECT d.blocked_by_id FROM task_dependencies d JOIN blockers b ON d.task_id = b.id
+		)
+		SELECT count(*) FROM blockers WHERE id = ?`, req.BlockedByID, id).Scan(&count).Error()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDependencyCycle
+		}
+
+		return repository.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.TaskDependency{
+			TaskID:      id,
+			BlockedByID: req.BlockedByID,
+			CreatedAt:   time.Now(),
+		}).Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	// the task may be blocked now
+	s.events.publish(enum.TaskEventUpdated, workspaceID, id)
+	return nil
+}
+
+func (s taskService) RemoveDependency(workspaceID int, id int, blockedByID int) error {
+	result := s.repository.Where("task_id = ? AND blocked_by_id = ?", id, blockedByID).Delete(&entities.TaskDependency{})
+	err := result.Error()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDependencyNotFound
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, id)
+	return nil
+}
+
+// AssignProject moves the task into another project, or out of every project
+// when the project is nil. Subtasks keep their own project.
+func (s taskService) AssignProject(workspaceID int, id int, version int, req request.AssignedProjectRequest) error {
+	updated := map[string]interface{}{
+		"project_id": req.ProjectID,
+		"updated_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	}
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		if req.ProjectID != nil {
+			err := s.checkProject(repository, workspaceID, *req.ProjectID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return s.applyUpdate(repository, id, version, nil, updated)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventUpdated, workspaceID, id)
+	return nil
+}
+
+// checkProject reports ErrProjectNotFound unless the project belongs to the
+// workspace.
+func (s taskService) checkProject(repository base.BaseRepository[any], workspaceID int, id int) error {
+	var count int64
+	err := repository.Model(&entities.Project{}).Where("id = ? AND workspace_id = ?", id, workspaceID).Count(&count).Error()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrProjectNotFound
+	}
+
+	return nil
+}
+
+func (s taskService) UpdateTask(workspaceID int, id int, version int, req request.UpdatedTaskRequest) error {
+	priority := req.Priority
+	if len(priority) == 0 {
+		priority = enum.TaskPriorityMedium
+	}
+
+	updated := map[string]interface{}{
+		"title":       req.Title,
+		"description": req.Description,
+		"status":      req.Status,
+		"priority":    priority,
+		"start_at":    req.StartAt,
+		"due_at":      req.DueAt,
+		"updated_at":  time.Now(),
+	}
+
+	return s.updateTask(workspaceID, id, version, updated, updateOptions{cascade: req.Cascade, tags: req.Tags})
+}
+
+func (s taskService) PatchTask(workspaceID int, id int, version int, req request.PatchedTaskRequest) error {
 	updated := make(map[string]interface{})
-	if len(req.Title) > 0 {
-		updated["title"] = req.Title
+	if req.Title.Set {
+		updated["title"] = req.Title.Value
+	}
+	if req.Description.Set {
+		updated["description"] = req.Description.Value
+	}
+	if req.Status.Set {
+		updated["status"] = req.Status.Value
 	}
-	if len(req.Description) > 0 {
-		updated["description"] = req.Description
+	if req.Priority.Set {
+		updated["priority"] = req.Priority.Value
 	}
-	if len(req.Image) > 0 {
-		updated["image"] = req.Image
+	if req.StartAt.Set {
+		updated["start_at"] = nullableTime(req.StartAt)
 	}
-	if len(req.Status) > 0 {
-		updated["status"] = req.Status
+	if req.DueAt.Set {
+		updated["due_at"] = nullableTime(req.DueAt)
+	}
+
+	opts := updateOptions{cascade: req.Cascade}
+	if req.Tags.Set {
+		opts.tags = []string{}
+		if !req.Tags.Null {
+			opts.tags = req.Tags.Value
+		}
 	}
 
 	updated["updated_at"] = time.Now()
-	err := s.repository.Model(&entities.Task{}).Where("id = ?", id).Updates(updated).Error()
+	return s.updateTask(workspaceID, id, version, updated, opts)
+}
+
+type updateOptions struct {
+	// cascade completes open descendants along with the task.
+	cascade bool
+	// tags replaces the task's tags when it is not nil.
+	tags []string
+}
+
+// updateTask bumps the version in the same statement that checks it, so two
+// concurrent writers holding the same version cannot both succeed. A version
+// of 0 skips the check (If-Match: *). Tag changes and cascaded completion are
+// written in the same transaction as the task.
+func (s taskService) updateTask(workspaceID int, id int, version int, updated map[string]interface{}, opts updateOptions) error {
+	status, ok := updated["status"].(enum.TaskStatus)
+	updated["version"] = gorm.Expr("version + 1")
+
+	changed := []int{id}
+	cascade := opts.cascade && status == enum.TaskStatusCompleted
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var current *entities.Task
+		if ok {
+			task, err := s.findTask(repository, id)
+			if err != nil {
+				return err
+			}
+			if !s.workflow.CanTransition(task.Status, status) {
+				return &InvalidTransitionError{
+					From:    task.Status,
+					To:      status,
+					Allowed: s.workflow.Next(task.Status),
+				}
+			}
+			if status == enum.TaskStatusCompleted && task.Status != enum.TaskStatusCompleted {
+				blocked, err := s.hasOpenBlockers(repository, id)
+				if err != nil {
+					return err
+				}
+				if blocked {
+					return ErrTaskBlocked
+				}
+			}
+
+			if s.workflow.IsTerminal(status) {
+				if !s.workflow.IsTerminal(task.Status) {
+					updated["completed_at"] = time.Now()
+				}
+			} else {
+				updated["completed_at"] = nil
+			}
+			current = &task
+		}
+
+		err := s.applyUpdate(repository, id, version, current, updated)
+		if err != nil {
+			return err
+		}
+		if opts.tags != nil {
+			err = replaceTags(repository, workspaceID, id, opts.tags)
+			if err != nil {
+				return err
+			}
+		}
+		if cascade {
+			descendants, err := s.completeDescendants(repository, id)
+			changed = append(changed, descendants...)
+			return err
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
+	s.events.publish(enum.TaskEventUpdated, workspaceID, changed...)
 	return nil
 }
+
+// replaceTags sets the task's tags to names, creating the tags that don't
+// exist in the workspace yet.
+func replaceTags(repository base.BaseRepository[any], workspaceID int, taskID int, names []string) error {
+	names = tagNames(names)
+	if len(names) > 0 {
+		tn := time.Now()
+		tags := make([]entities.Tag, 0, len(names))
+		for _, name := range names {
+			tags = append(tags, entities.Tag{WorkspaceID: &workspaceID, Name: name, CreatedAt: tn, UpdatedAt: tn})
+		}
+
+		err := repository.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error()
+		if err != nil {
+			return err
+		}
+	}
+
+	err := repository.Exec("DELETE FROM task_tags WHERE task_id = ?", taskID).Error()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	return repository.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT ?, id FROM tags WHERE workspace_id = ? AND name IN ?", taskID, workspaceID, names).Error()
+}
+
+// tagNames trims tag names and drops blanks and duplicates.
+func tagNames(names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len(name) > 0 && !slices.Contains(result, name) {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func (s taskService) applyUpdate(repository base.BaseRepository[any], id int, version int, current *entities.Task, updated map[string]interface{}) error {
+	db := repository.Model(&entities.Task{}).Where("id = ?", id)
+	if version > 0 {
+		db = db.Where("version = ?", version)
+	}
+	if current != nil {
+		// the transition was checked against this status, so it must not
+		// have moved on between the read and the write
+		db = db.Where("status = ?", current.Status)
+	}
+
+	result := db.Updates(updated)
+	err := result.Error()
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == checkViolation {
+			return ErrInvalidSchedule
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return s.unmodifiedError(repository, id)
+	}
+
+	return nil
+}
+
+// completeDescendants skips the workflow graph on purpose: a cascade closes
+// every open subtask regardless of where it currently is. Blockers still
+// apply, so it fails with ErrTaskBlocked when a subtask waits on an open
+// task outside the cascade. It returns the subtasks it completed.
+func (s taskService) completeDescendants(repository base.BaseRepository[any], id int) ([]int, error) {
+	const descendants = `WITH RECURSIVE descendants AS (
+		SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+		UNION
+		SELECT t.id FROM tasks t JOIN descendants d ON t.parent_id = d.id WHERE t.deleted_at IS NULL
+	)`
+
+	// blockers among the descendants are completed along with them
+	var blocked int64
+	err := repository.Raw(fmt.Sprintf(`%s
+	SELECT count(*) FROM tasks WHERE id IN (SELECT id FROM descendants) AND status NOT IN ?
+		AND EXISTS (%s AND b.id NOT IN (SELECT id FROM descendants))`, descendants, openBlockersQuery),
+		id, s.workflow.Terminal(), s.workflow.Terminal()).Scan(&blocked).Error()
+	if err != nil {
+		return nil, err
+	}
+	if blocked > 0 {
+		return nil, ErrTaskBlocked
+	}
+
+	tn := time.Now()
+	var ids []int
+	err = repository.Raw(descendants+`
+	UPDATE tasks SET status = ?, completed_at = ?, updated_at = ?, version = version + 1
+	WHERE id IN (SELECT id FROM descendants) AND status NOT IN ?
+	RETURNING id`,
+		id, enum.TaskStatusCompleted, tn, tn, s.workflow.Terminal()).Scan(&ids).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func (s taskService) hasOpenBlockers(repository base.BaseRepository[any], id int) (bool, error) {
+	var count int64
+	err := repository.Model(&entities.Task{}).Where(fmt.Sprintf("id = ? AND EXISTS (%s)", openBlockersQuery), id, s.workflow.Terminal()).Count(&count).Error()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func nullableTime(value request.Optional[time.Time]) *time.Time {
+	if value.Null {
+		return nil
+	}
+	return &value.Value
+}
+
+func (s taskService) DeleteTask(workspaceID int, id int, version int) error {
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		db := repository.Where("id = ?", id)
+		if version > 0 {
+			db = db.Where("version = ?", version)
+		}
+
+		result := db.Delete(&entities.Task{})
+		err := result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return s.unmodifiedError(repository, id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventDeleted, workspaceID, id)
+	return nil
+}
+
+// unmodifiedError tells apart a missing task from a stale version once a
+// conditional write has touched no rows.
+func (s taskService) unmodifiedError(repository base.BaseRepository[any], id int) error {
+	var count int64
+	err := repository.Model(&entities.Task{}).Where("id = ?", id).Count(&count).Error()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrTaskNotFound
+	}
+
+	return ErrTaskVersionMismatch
+}
+
+func (s taskService) GetTrashedTasks(workspaceID int) ([]entities.Task, error) {
+	var tasks []entities.Task
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		return repository.Unscoped().Where("workspace_id = ? AND deleted_at IS NOT NULL", workspaceID).Order("deleted_at desc").Find(&tasks).Error()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (s taskService) RestoreTask(workspaceID int, id int) error {
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		result := repository.Unscoped().Model(&entities.Task{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+		err := result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrTaskNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.events.publish(enum.TaskEventCreated, workspaceID, id)
+	return nil
+}
+
+// PurgeTask publishes nothing; the task was announced as deleted when it
+// was trashed. Its image and attachments are deleted once it is gone.
+func (s taskService) PurgeTask(workspaceID int, id int) error {
+	var blobs purgedBlobs
+	err := database.InWorkspace(s.repository, workspaceID, func(repository base.BaseRepository[any]) error {
+		var err error
+		blobs, err = s.purgeTasks(repository, "id = ? AND deleted_at IS NOT NULL", id)
+		if err != nil {
+			return err
+		}
+		if blobs.tasks == 0 {
+			return ErrTaskNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.deleteBlobs(blobs)
+	return nil
+}
+
+func (s taskService) PurgeExpiredTasks(before time.Time) (int64, error) {
+	var blobs purgedBlobs
+	err := database.AllWorkspaces(s.repository, func(repository base.BaseRepository[any]) error {
+		var err error
+		blobs, err = s.purgeTasks(repository, "deleted_at < ?", before)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.deleteBlobs(blobs)
+	return blobs.tasks, nil
+}
+
+// purgedBlobs are the blobs of purged tasks, to delete once the purge is
+// committed.
+type purgedBlobs struct {
+	tasks    int64
+	images   []string
+	contents []attachmentContent
+}
+
+type attachmentContent struct {
+	SHA256     string
+	StorageKey string
+}
+
+// purgeTasks deletes the trashed tasks matching the condition. Their
+// attachments go with them, so their content is read first.
+func (s taskService) purgeTasks(repository base.BaseRepository[any], query string, args ...interface{}) (purgedBlobs, error) {
+	var blobs purgedBlobs
+	err := repository.Model(&entities.Attachment{}).Select("DISTINCT sha256, storage_key").
+		Where("task_id IN (SELECT id FROM tasks WHERE "+query+")", args...).Scan(&blobs.contents).Error()
+	if err != nil {
+		return purgedBlobs{}, err
+	}
+
+	var tasks []entities.Task
+	result := repository.Unscoped().Clauses(clause.Returning{Columns: []clause.Column{{Name: "image_key"}, {Name: "thumbnail_key"}}}).
+		Where(query, args...).Delete(&tasks)
+	err = result.Error()
+	if err != nil {
+		return purgedBlobs{}, err
+	}
+
+	blobs.tasks = result.RowsAffected()
+	for _, task := range tasks {
+		for _, key := range []string{task.ImageKey, task.ThumbnailKey} {
+			if len(key) > 0 {
+				blobs.images = append(blobs.images, key)
+			}
+		}
+	}
+
+	return blobs, nil
+}
+
+// deleteBlobs is best effort: a leftover blob is only wasted space. Content
+// of attachments is shared, so it is only deleted when no attachment of
+// another task references it any more.
+func (s taskService) deleteBlobs(blobs purgedBlobs) {
+	for _, key := range blobs.images {
+		err := s.store.Delete(context.Background(), key)
+		if err != nil {
+			s.log.Wrap("delete blob %s failed: %v", key, err).Error()
+		}
+	}
+
+	for _, content := range blobs.contents {
+		err := deleteAttachmentContent(s.repository, s.store, content.SHA256, content.StorageKey)
+		if err != nil {
+			s.log.Wrap("delete blob %s failed: %v", content.StorageKey, err).Error()
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/cache"
+	"todo/pkg/logger"
+)
+
+const (
+	DefaultTaskListTTL = time.Minute
+	// MaxTaskListTTL bounds how long a list can be served after the write
+	// that changed it, should its invalidation fail.
+	MaxTaskListTTL = 5 * time.Minute
+)
+
+const (
+	taskListKey            = "tasks:list:%d:%s:%s:%s"
+	workspaceGenerationKey = "tasks:generation:%d"
+	// sharedGenerationKey is part of the key of every list, so moving it
+	// drops the lists of every workspace.
+	sharedGenerationKey = "tasks:generation"
+)
+
+// taskListCache keeps task lists under generations. Every write to the
+// tasks of a workspace moves it to a new generation, so lists cached before
+// the write are never read again and simply expire. A generation is a
+// random value rather than a counter so it can't repeat after Redis lost
+// it.
+//
+// An invalidation that fails leaves the old generation in place. The
+// replica it failed on stops reading lists until it could move every
+// workspace on, but the other replicas can't tell and keep serving the
+// lists cached before the write until they expire. Lists therefore live no
+// longer than MaxTaskListTTL.
+//
+// A nil taskListCache caches nothing.
+type taskListCache struct {
+	cache cache.Cache
+	ttl   time.Duration
+	// dirty is set when an invalidation failed. Nothing is read from the
+	// cache until the shared generation, part of every key, has moved on.
+	dirty atomic.Bool
+	log   logger.Logger
+}
+
+type cachedTaskList struct {
+	Tasks      []entities.Task     `json:"tasks"`
+	Pagination response.Pagination `json:"pagination"`
+}
+
+// taskListQuery is the normalized form of a list query; queries that list
+// the same tasks hash to the same key.
+type taskListQuery struct {
+	Title           string              `json:"title,omitempty"`
+	Description     string              `json:"description,omitempty"`
+	SortBy          enum.TaskListSortBy `json:"sort_by"`
+	SortOrder       enum.SortOrder      `json:"sort_order"`
+	Page            int                 `json:"page,omitempty"`
+	PageSize        int                 `json:"page_size"`
+	Cursor          string              `json:"cursor,omitempty"`
+	DueBefore       *time.Time          `json:"due_before,omitempty"`
+	DueAfter        *time.Time          `json:"due_after,omitempty"`
+	Priorities      []enum.TaskPriority `json:"priorities,omitempty"`
+	Blocked         *bool               `json:"blocked,omitempty"`
+	Tags            []string            `json:"tags,omitempty"`
+	TagMode         enum.TagMode        `json:"tag_mode,omitempty"`
+	ProjectID       *int                `json:"project_id,omitempty"`
+	IncludeArchived bool                `json:"include_archived,omitempty"`
+}
+
+func newTaskListCache(c cache.Cache, ttl time.Duration) *taskListCache {
+	if c == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = DefaultTaskListTTL
+	}
+	if ttl > MaxTaskListTTL {
+		ttl = MaxTaskListTTL
+	}
+
+	return &taskListCache{
+		cache: c,
+		ttl:   ttl,
+		log:   logger.WithPrefix("service/task_cache"),
+	}
+}
+
+// get looks query up and returns the key to store its list under on a
+// miss. The key is empty when the list must not be stored.
+func (c *taskListCache) get(query request.TaskListQuery) (string, cachedTaskList, bool) {
+	// overdue depends on the time of the query, not on the tasks alone
+	if c == nil || query.Overdue {
+		return "", cachedTaskList{}, false
+	}
+	ctx := context.Background()
+
+	if c.dirty.Load() {
+		if err := c.cache.Set(ctx, sharedGenerationKey, newGeneration(), 0); err != nil {
+			return "", cachedTaskList{}, false
+		}
+		c.dirty.Store(false)
+	}
+
+	keys := []string{fmt.Sprintf(workspaceGenerationKey, query.WorkspaceID), sharedGenerationKey}
+	generations, err := c.cache.MGet(ctx, keys...)
+	if err != nil {
+		c.warn("read generations", err)
+		return "", cachedTaskList{}, false
+	}
+
+	missing := false
+	for i, generation := range generations {
+		if generation == nil {
+			missing = true
+			if _, err := c.cache.SetNX(ctx, keys[i], newGeneration(), 0); err != nil {
+				c.warn("start generation", err)
+			}
+		}
+	}
+	if missing {
+		return "", cachedTaskList{}, false
+	}
+
+	key := fmt.Sprintf(taskListKey, query.WorkspaceID, generations[0], generations[1], hashTaskListQuery(query))
+	data, err := c.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrMiss) {
+			c.warn("read task list", err)
+		}
+		return key, cachedTaskList{}, false
+	}
+
+	var list cachedTaskList
+	if err := json.Unmarshal(data, &list); err != nil {
+		c.warn("decode task list", err)
+		return key, cachedTaskList{}, false
+	}
+
+	return key, list, true
+}
+
+func (c *taskListCache) set(key string, list cachedTaskList) {
+	if c == nil || len(key) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(list)
+	if err != nil {
+		c.warn("encode task list", err)
+		return
+	}
+
+	if err := c.cache.Set(context.Background(), key, data, c.ttl); err != nil {
+		c.warn("store task list", err)
+	}
+}
+
+// invalidate moves the workspace to a new generation.
+func (c *taskListCache) invalidate(workspaceID int) {
+	if c == nil {
+		return
+	}
+
+	key := fmt.Sprintf(workspaceGenerationKey, workspaceID)
+	if err := c.cache.Set(context.Background(), key, newGeneration(), 0); err != nil {
+		c.dirty.Store(true)
+		c.warn("invalidate task lists", err)
+	}
+}
+
+func (c *taskListCache) warn(action string, err error) {
+	// an outage was logged when it began
+	if errors.Is(err, cache.ErrUnavailable) {
+		return
+	}
+	c.log.Wrap("%s failed: %v", action, err).Warn()
+}
+
+func hashTaskListQuery(query request.TaskListQuery) string {
+	column, order := sortColumn(query)
+	dueBefore, dueAfter, _ := query.DueRange()
+	normalized := taskListQuery{
+		Title:       query.Title,
+		Description: query.Description,
+		SortBy:      column,
+		SortOrder:   order,
+		Page:        query.Page,
+		PageSize:    query.Limit(),
+		Cursor:      query.Cursor,
+		DueBefore:   utc(dueBefore),
+		DueAfter:    utc(dueAfter),
+		Priorities:  slices.Clone(query.Priorities),
+		Blocked:     query.Blocked,
+		ProjectID:   query.ProjectID,
+	}
+	slices.Sort(normalized.Priorities)
+	normalized.Priorities = slices.Compact(normalized.Priorities)
+	if tags := tagNames(query.Tags); len(tags) > 0 {
+		slices.Sort(tags)
+		normalized.Tags = tags
+		normalized.TagMode = query.TagMode
+		if len(normalized.TagMode) == 0 {
+			normalized.TagMode = enum.TagModeAny
+		}
+	}
+	// archived projects only matter when no project is named
+	if query.ProjectID == nil {
+		normalized.IncludeArchived = query.IncludeArchived
+	}
+
+	data, _ := json.Marshal(normalized)
+	return hashToken(string(data))
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func newGeneration() []byte {
+	generation, err := randomToken(8)
+	if err != nil {
+		// a clock reading is still unlikely to repeat
+		return []byte(time.Now().Format(time.RFC3339Nano))
+	}
+	return []byte(generation)
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/base"
+	"todo/pkg/cache"
+	"todo/pkg/logger"
+	"todo/pkg/workflow"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func Test_taskService_GetTasks_cached(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	events := NewTaskEvents(nil, cache.NewRedisCache(client), time.Minute)
+	s := taskService{
+		repository: base.NewBaseRepository[any](db),
+		workflow:   workflow.Default(),
+		lists:      events.taskLists(),
+		events:     events,
+		log:        logger.WithPrefix("test"),
+	}
+
+	query := request.TaskListQuery{Title: "foo", WorkspaceID: 7}
+	expectList := func() {
+		expectWorkspace(mock, 7)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks"`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(`SELECT (.+) FROM "tasks"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "title", "status", "created_at", "updated_at"}).AddRow(1, "foo", "TODO", tn, tn))
+		mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+		mock.ExpectCommit()
+	}
+
+	steps := []struct {
+		name         string
+		before       func()
+		query        request.TaskListQuery
+		fromDatabase bool
+	}{
+		{
+			name:         "generations are started",
+			query:        query,
+			fromDatabase: true,
+		},
+		{
+			name:         "list is stored",
+			query:        query,
+			fromDatabase: true,
+		},
+		{
+			name:         "list is read back",
+			query:        query,
+			fromDatabase: false,
+		},
+		{
+			name:         "same list asked differently",
+			query:        request.TaskListQuery{Title: "foo", SortBy: "id", SortOrder: "asc", PageSize: 20, Tags: []string{" "}, IncludeArchived: false, WorkspaceID: 7},
+			fromDatabase: false,
+		},
+		{
+			name:         "another list",
+			query:        request.TaskListQuery{Title: "foo", SortOrder: "desc", WorkspaceID: 7},
+			fromDatabase: true,
+		},
+		{
+			name:         "write to another workspace",
+			before:       func() { events.publish(enum.TaskEventUpdated, 8, 1) },
+			query:        query,
+			fromDatabase: false,
+		},
+		{
+			name:         "write to the workspace",
+			before:       func() { events.publish(enum.TaskEventUpdated, 7, 1) },
+			query:        query,
+			fromDatabase: true,
+		},
+		{
+			name:         "read after the write",
+			query:        query,
+			fromDatabase: false,
+		},
+		{
+			name:         "write changing no task",
+			before:       func() { events.publish(enum.TaskEventUpdated, 7) },
+			query:        query,
+			fromDatabase: false,
+		},
+		{
+			name: "invalidation failed",
+			before: func() {
+				server.SetError("READONLY")
+				events.publish(enum.TaskEventUpdated, 7, 1)
+				server.SetError("")
+			},
+			query:        query,
+			fromDatabase: true,
+		},
+		{
+			name:         "overdue depends on the time",
+			query:        request.TaskListQuery{Title: "foo", Overdue: true, WorkspaceID: 7},
+			fromDatabase: true,
+		},
+		{
+			name:         "redis is down",
+			before:       server.Close,
+			query:        query,
+			fromDatabase: true,
+		},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+			if tt.fromDatabase {
+				expectList()
+			}
+			mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments"`).
+				WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}).AddRow(1, 2))
+
+			got, page, err := s.GetTasks(tt.query)
+			if err != nil {
+				t.Fatalf("taskService.GetTasks() error = %v", err)
+			}
+			if len(got) != 1 || got[0].ID != 1 || got[0].Title != "foo" || got[0].CommentCount == nil || *got[0].CommentCount != 2 {
+				t.Errorf("taskService.GetTasks() = %+v", got)
+			}
+			if page != (response.Pagination{PageSize: 20, Total: 1}) {
+				t.Errorf("taskService.GetTasks() page = %+v", page)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+// failingCache fails every Set while fail is true, as Redis does for a
+// replica that lost its connection.
+type failingCache struct {
+	cache.Cache
+	fail bool
+}
+
+func (c *failingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.fail {
+		return errors.New("foo")
+	}
+	return c.Cache.Set(ctx, key, value, ttl)
+}
+
+func Test_taskListCache_failedInvalidation(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	// two replicas sharing Redis, the invalidation fails on the first
+	failing := &failingCache{Cache: cache.NewRedisCache(client)}
+	a := newTaskListCache(failing, time.Minute)
+	b := newTaskListCache(cache.NewRedisCache(client), time.Minute)
+
+	query := request.TaskListQuery{Title: "foo", WorkspaceID: 7}
+	list := cachedTaskList{Pagination: response.Pagination{PageSize: 20, Total: 1}}
+
+	steps := []struct {
+		name    string
+		before  func()
+		replica *taskListCache
+		hit     bool
+	}{
+		{
+			name:    "generations are started",
+			replica: b,
+			hit:     false,
+		},
+		{
+			name:    "list is stored",
+			replica: b,
+			hit:     false,
+		},
+		{
+			name:    "list is read back",
+			replica: b,
+			hit:     true,
+		},
+		{
+			name: "invalidation failed on another replica",
+			before: func() {
+				failing.fail = true
+				a.invalidate(7)
+			},
+			replica: b,
+			hit:     true,
+		},
+		{
+			name:    "replica that failed skips the cache",
+			replica: a,
+			hit:     false,
+		},
+		{
+			name:    "stale list expires",
+			before:  func() { server.FastForward(time.Minute) },
+			replica: b,
+			hit:     false,
+		},
+		{
+			name:    "list is read back after it expired",
+			replica: b,
+			hit:     true,
+		},
+		{
+			name:    "replica that failed moves every workspace on",
+			before:  func() { failing.fail = false },
+			replica: a,
+			hit:     false,
+		},
+		{
+			name:    "other replicas follow",
+			replica: b,
+			hit:     false,
+		},
+	}
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.before != nil {
+				tt.before()
+			}
+
+			key, _, hit := tt.replica.get(query)
+			if hit != tt.hit {
+				t.Fatalf("taskListCache.get() hit = %v, want %v", hit, tt.hit)
+			}
+			// only the second replica stores lists, so a hit is the list
+			// it stored before the write
+			if !hit && tt.replica == b {
+				b.set(key, list)
+			}
+		})
+	}
+}
+
+func Test_newTaskListCache(t *testing.T) {
+	c := cache.NewRedisCache(redis.NewClient(&redis.Options{}))
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{name: "default", ttl: 0, want: DefaultTaskListTTL},
+		{name: "short", ttl: time.Second, want: time.Second},
+		{name: "too long", ttl: time.Hour, want: MaxTaskListTTL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newTaskListCache(c, tt.ttl).ttl; got != tt.want {
+				t.Errorf("newTaskListCache() ttl = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_hashTaskListQuery(t *testing.T) {
+	projectID := 1
+	tests := []struct {
+		name  string
+		a     request.TaskListQuery
+		b     request.TaskListQuery
+		equal bool
+	}{
+		{
+			name:  "defaults",
+			a:     request.TaskListQuery{},
+			b:     request.TaskListQuery{SortBy: "id", SortOrder: "asc", PageSize: 20},
+			equal: true,
+		},
+		{
+			name:  "order of filters",
+			a:     request.TaskListQuery{Priorities: []enum.TaskPriority{"HIGH", "LOW"}, Tags: []string{"b", "a"}},
+			b:     request.TaskListQuery{Priorities: []enum.TaskPriority{"LOW", "HIGH", "LOW"}, Tags: []string{" a", "b"}, TagMode: "any"},
+			equal: true,
+		},
+		{
+			name:  "due dates in other zones",
+			a:     request.TaskListQuery{DueBefore: "2024-01-01T07:00:00+07:00"},
+			b:     request.TaskListQuery{DueBefore: "2024-01-01T00:00:00Z"},
+			equal: true,
+		},
+		{
+			name:  "archived projects of a named project",
+			a:     request.TaskListQuery{ProjectID: &projectID},
+			b:     request.TaskListQuery{ProjectID: &projectID, IncludeArchived: true},
+			equal: true,
+		},
+		{
+			name:  "tag mode",
+			a:     request.TaskListQuery{Tags: []string{"a"}},
+			b:     request.TaskListQuery{Tags: []string{"a"}, TagMode: "all"},
+			equal: false,
+		},
+		{
+			name:  "archived projects",
+			a:     request.TaskListQuery{},
+			b:     request.TaskListQuery{IncludeArchived: true},
+			equal: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashTaskListQuery(tt.a) == hashTaskListQuery(tt.b); got != tt.equal {
+				t.Errorf("hashTaskListQuery() equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
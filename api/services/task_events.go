@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/base"
+	"todo/pkg/cache"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+)
+
+var ErrTaskStreamUnavailable = errors.New("task stream is unavailable")
+
+// taskEventsTopic is the topic task changes are published on.
+const taskEventsTopic = "tasks"
+
+// TaskEvents publishes which tasks changed and drops the cached lists they
+// are in. One is shared by every service that writes tasks. Streams load
+// the tasks themselves, so what they send passes the row-level security
+// and the filters of their workspace.
+//
+// A nil TaskEvents publishes and caches nothing.
+type TaskEvents struct {
+	broker pubsub.Broker
+	lists  *taskListCache
+	log    logger.Logger
+}
+
+type taskEvent struct {
+	Type        enum.TaskEvent `json:"type"`
+	TaskID      int            `json:"task_id"`
+	WorkspaceID int            `json:"workspace_id"`
+}
+
+// NewTaskEvents publishes to broker and caches task lists in c for
+// listTTL; a nil broker turns the task stream off and a nil c caching.
+func NewTaskEvents(broker pubsub.Broker, c cache.Cache, listTTL time.Duration) *TaskEvents {
+	if broker == nil && c == nil {
+		return nil
+	}
+
+	return &TaskEvents{
+		broker: broker,
+		lists:  newTaskListCache(c, listTTL),
+		log:    logger.WithPrefix("service/task_events"),
+	}
+}
+
+// taskLists are the cached task lists, nil when nothing is cached.
+func (e *TaskEvents) taskLists() *taskListCache {
+	if e == nil {
+		return nil
+	}
+	return e.lists
+}
+
+// publish announces changes to tasks of the workspace and drops its cached
+// lists. It is best effort: the write has happened already, and streams
+// that miss the event are told to list the tasks again.
+func (e *TaskEvents) publish(event enum.TaskEvent, workspaceID int, ids ...int) {
+	if e == nil || len(ids) == 0 {
+		return
+	}
+
+	e.lists.invalidate(workspaceID)
+	if e.broker == nil {
+		return
+	}
+
+	for _, id := range ids {
+		data, err := json.Marshal(taskEvent{Type: event, TaskID: id, WorkspaceID: workspaceID})
+		if err != nil {
+			e.log.Wrap("encode task event failed: %v", err).Error()
+			return
+		}
+		err = e.broker.Publish(context.Background(), taskEventsTopic, data)
+		if err != nil {
+			e.log.Wrap("publish task event failed: %v", err).Warn()
+		}
+	}
+}
+
+// WatchTasks sends created and updated tasks while they match the filters
+// of query, and every deleted one, since it no longer matches anything.
+// Restored tasks are sent as created. The channel is closed when ctx is
+// done, when the stream falls behind or when loading a task failed; the
+// client then resumes from the last event it received.
+func (s taskService) WatchTasks(ctx context.Context, query request.TaskListQuery, after int64) (<-chan response.TaskEvent, error) {
+	if s.events == nil || s.events.broker == nil {
+		return nil, ErrTaskStreamUnavailable
+	}
+
+	sub := s.events.broker.Subscribe(taskEventsTopic, after)
+	events := make(chan response.TaskEvent)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+
+		send := func(event response.TaskEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		if sub.Missed && !send(response.TaskEvent{ID: sub.Latest, Type: enum.TaskEventReset}) {
+			return
+		}
+
+		for {
+			var msg pubsub.Message
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-sub.C:
+				if !ok {
+					return
+				}
+				msg = m
+			}
+
+			var e taskEvent
+			err := json.Unmarshal(msg.Data, &e)
+			if err != nil {
+				s.log.Wrap("decode task event failed: %v", err).Warn()
+				continue
+			}
+			if e.WorkspaceID != query.WorkspaceID {
+				continue
+			}
+
+			event := response.TaskEvent{ID: msg.ID, Type: e.Type, TaskID: e.TaskID}
+			if e.Type != enum.TaskEventDeleted {
+				task, ok, err := s.matchTask(query, e.TaskID)
+				if err != nil {
+					s.log.Wrap("load changed task failed: %v", err).Error()
+					return
+				}
+				if !ok {
+					continue
+				}
+				event.Task = &task
+			}
+
+			if !send(event) {
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// matchTask loads the task if it is listed by query.
+func (s taskService) matchTask(query request.TaskListQuery, id int) (entities.Task, bool, error) {
+	var tasks []entities.Task
+	err := database.InWorkspace(s.repository, query.WorkspaceID, func(repository base.BaseRepository[any]) error {
+		db, err := s.filterTasks(repository.Model(&entities.Task{}).Where("workspace_id = ? AND id = ?", query.WorkspaceID, id), query)
+		if err != nil {
+			return err
+		}
+		return db.Preload("Tags").Find(&tasks).Error()
+	})
+	if err != nil || len(tasks) == 0 {
+		return entities.Task{}, false, err
+	}
+
+	err = s.countComments(tasks)
+	if err != nil {
+		return entities.Task{}, false, err
+	}
+
+	return tasks[0], true, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func receiveTaskEvent(t *testing.T, events <-chan response.TaskEvent) (response.TaskEvent, bool) {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("no task event received")
+	}
+	return response.TaskEvent{}, false
+}
+
+// publishedTaskIDs drains the events published on sub so far and returns
+// the IDs of their tasks, failing on events of another type.
+func publishedTaskIDs(t *testing.T, sub *pubsub.Subscription, event enum.TaskEvent) []int {
+	t.Helper()
+
+	var ids []int
+	for len(sub.C) > 0 {
+		var got taskEvent
+		if err := json.Unmarshal((<-sub.C).Data, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Type != event {
+			t.Errorf("published a %s event for task %d, want %s", got.Type, got.TaskID, event)
+		}
+		ids = append(ids, got.TaskID)
+	}
+	return ids
+}
+
+func TestTaskEvents_publish(t *testing.T) {
+	broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+	sub := broker.Subscribe(taskEventsTopic, 0)
+	defer sub.Close()
+
+	events := NewTaskEvents(broker, nil, 0)
+	events.publish(enum.TaskEventUpdated, 3, 1, 2)
+
+	if len(sub.C) != 2 {
+		t.Fatalf("published %d events, want 2", len(sub.C))
+	}
+	for _, id := range []int{1, 2} {
+		var got taskEvent
+		if err := json.Unmarshal((<-sub.C).Data, &got); err != nil {
+			t.Fatal(err)
+		}
+		want := taskEvent{Type: enum.TaskEventUpdated, TaskID: id, WorkspaceID: 3}
+		if got != want {
+			t.Errorf("TaskEvents.publish() = %v, want %v", got, want)
+		}
+	}
+
+	// without a broker nothing is published
+	events = NewTaskEvents(nil, nil, 0)
+	events.publish(enum.TaskEventUpdated, 3, 1)
+}
+
+func Test_taskService_WatchTasks(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+	s := taskService{
+		repository: base.NewBaseRepository[any](db),
+		events:     NewTaskEvents(broker, nil, 0),
+		log:        logger.WithPrefix("test"),
+	}
+
+	query := request.TaskListQuery{Title: "foo", WorkspaceID: 3}
+	events, err := s.WatchTasks(ctx, query, 0)
+	if err != nil {
+		t.Fatalf("taskService.WatchTasks() error = %v", err)
+	}
+
+	matchQuery := `SELECT \* FROM "tasks" WHERE \(workspace_id = \$1 AND id = \$2\) AND title LIKE \$3 AND \(project_id IS NULL OR project_id NOT IN \(SELECT id FROM projects WHERE archived\)\) AND "tasks"."deleted_at" IS NULL`
+	// the task that no longer matches is skipped
+	expectWorkspace(mock, 3)
+	mock.ExpectQuery(matchQuery).
+		WithArgs(3, 1, "foo%").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	expectWorkspace(mock, 3)
+	mock.ExpectQuery(matchQuery).
+		WithArgs(3, 2, "foo%").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "status", "created_at", "updated_at"}).AddRow(2, "foo", "TODO", tn, tn))
+	mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}).AddRow(2, 4))
+
+	s.events.publish(enum.TaskEventCreated, 4, 9)
+	s.events.publish(enum.TaskEventUpdated, 3, 1)
+	s.events.publish(enum.TaskEventCreated, 3, 2)
+	s.events.publish(enum.TaskEventDeleted, 3, 5)
+
+	created, _ := receiveTaskEvent(t, events)
+	if created.Type != enum.TaskEventCreated || created.TaskID != 2 || created.Task == nil || created.Task.Title != "foo" || *created.Task.CommentCount != 4 {
+		t.Fatalf("first event = %+v, want task 2 created", created)
+	}
+	deleted, _ := receiveTaskEvent(t, events)
+	want := response.TaskEvent{ID: created.ID + 1, Type: enum.TaskEventDeleted, TaskID: 5}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Fatalf("second event = %+v, want %+v", deleted, want)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+
+	// resuming replays the events after the one given
+	resumed, err := s.WatchTasks(ctx, query, created.ID)
+	if err != nil {
+		t.Fatalf("taskService.WatchTasks() error = %v", err)
+	}
+	if event, _ := receiveTaskEvent(t, resumed); !reflect.DeepEqual(event, want) {
+		t.Fatalf("replayed event = %+v, want %+v", event, want)
+	}
+
+	// events that are no longer kept are reported as a reset
+	reset, err := s.WatchTasks(ctx, query, 1)
+	if err != nil {
+		t.Fatalf("taskService.WatchTasks() error = %v", err)
+	}
+	if event, _ := receiveTaskEvent(t, reset); !reflect.DeepEqual(event, response.TaskEvent{ID: deleted.ID, Type: enum.TaskEventReset}) {
+		t.Fatalf("event after a miss = %+v, want a reset", event)
+	}
+
+	cancel()
+	if _, ok := receiveTaskEvent(t, events); ok {
+		t.Fatal("stream not closed after the context was done")
+	}
+}
+
+func Test_taskService_WatchTasks_unavailable(t *testing.T) {
+	s := taskService{log: logger.WithPrefix("test")}
+
+	_, err := s.WatchTasks(context.Background(), request.TaskListQuery{}, 0)
+	if err != ErrTaskStreamUnavailable {
+		t.Errorf("taskService.WatchTasks() error = %v, want %v", err, ErrTaskStreamUnavailable)
+	}
+}
@@ -4,17 +4,24 @@ import (
 	"database/sql"
 	"errors"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 	"todo/api/entities"
 	"todo/api/enum"
 	"todo/api/models/request"
+	"todo/api/models/response"
 	"todo/pkg/base"
 	"todo/pkg/base/mock"
+	"todo/pkg/database"
 	"todo/pkg/logger"
+	"todo/pkg/pagination"
+	storagemock "todo/pkg/storage/mock"
+	"todo/pkg/workflow"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/golang/mock/gomock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -34,13 +41,31 @@ func DbMock(t *testing.T) (*sql.DB, *gorm.DB, sqlmock.Sqlmock) {
 	return sqldb, gormdb, mock
 }
 
+// expectWorkspace expects the transaction database.InWorkspace opens.
+func expectWorkspace(mock sqlmock.Sqlmock, workspaceID int) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+		WithArgs(database.WorkspaceSetting, strconv.Itoa(workspaceID)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+// expectAllWorkspaces expects the transaction database.AllWorkspaces opens.
+func expectAllWorkspaces(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+		WithArgs(database.AllWorkspacesSetting, "on").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
 func Test_taskService_CreateTask(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const insertQuery = `INSERT INTO "tasks" (.+) RETURNING "id"`
 
 	type fields struct {
-		repository         *mock.MockBaseRepository[any]
-		repositoryBehavior func(*mock.MockBaseRepository[any])
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
 	}
 	type args struct {
 		req request.CreatedTaskRequest
@@ -54,36 +79,136 @@ func Test_taskService_CreateTask(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				repository: mock.NewMockBaseRepository[any](ctrl),
-				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
-					mbr.EXPECT().Create(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Error()
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
 				},
 			},
 			args: args{
 				req: request.CreatedTaskRequest{
+					OwnerID:     7,
+					WorkspaceID: 3,
 					Title:       "foo",
 					Description: "foo",
-					Image:       "foo",
 					Status:      "COMPLETED",
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "success with parent",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND workspace_id = \$2\)`).
+						WithArgs(2, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedTaskRequest{
+					OwnerID:     7,
+					WorkspaceID: 3,
+					ParentID:    func() *int { id := 2; return &id }(),
+					Title:       "foo",
+					Status:      "TODO",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parent not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND workspace_id = \$2\)`).
+						WithArgs(2, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.CreatedTaskRequest{
+					OwnerID:     7,
+					WorkspaceID: 3,
+					ParentID:    func() *int { id := 2; return &id }(),
+					Title:       "foo",
+					Status:      "TODO",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "project not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 5)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(3, 5).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.CreatedTaskRequest{
+					WorkspaceID: 5,
+					ProjectID:   func() *int { id := 3; return &id }(),
+					Title:       "foo",
+					Status:      "TODO",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "success with tags",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(insertQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`INSERT INTO "tags" (.+) ON CONFLICT DO NOTHING RETURNING "id"`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE task_id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`INSERT INTO task_tags \(task_id, tag_id\) SELECT \$1, id FROM tags WHERE workspace_id = \$2 AND name IN \(\$3\)`).
+						WithArgs(1, 3, "bug").
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedTaskRequest{
+					WorkspaceID: 3,
+					Title:       "foo",
+					Status:      "TODO",
+					Tags:        []string{"bug"},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "create failed",
 			fields: fields{
-				repository: mock.NewMockBaseRepository[any](ctrl),
-				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
-					mbr.EXPECT().Create(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Error().Return(errors.New("foo"))
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(insertQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
 				},
 			},
 			args: args{
 				req: request.CreatedTaskRequest{
+					WorkspaceID: 3,
 					Title:       "foo",
 					Description: "foo",
-					Image:       "foo",
 					Status:      "COMPLETED",
 				},
 			},
@@ -92,15 +217,19 @@ func Test_taskService_CreateTask(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tt.fields.repositoryBehavior(tt.fields.repository)
+			tt.fields.repositoryBehavior()
 			s := taskService{
 				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
 				log:        logger.WithPrefix("test"),
 			}
 
 			if err := s.CreateTask(tt.args.req); (err != nil) != tt.wantErr {
 				t.Errorf("taskService.CreateTask() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
 		})
 	}
 }
@@ -109,13 +238,16 @@ func Test_taskService_GetTasks(t *testing.T) {
 	var (
 		tn    = time.Now()
 		tasks = []entities.Task{{
-			ID:          1,
-			Title:       "foo",
-			Description: "foo",
-			Image:       "foo",
-			Status:      enum.TaskStatusCompleted,
-			CreatedAt:   tn,
-			UpdatedAt:   tn,
+			ID:           1,
+			Title:        "foo",
+			Description:  "foo",
+			ImageKey:     "foo",
+			ImageURL:     "/api/tasks/1/image",
+			Status:       enum.TaskStatusCompleted,
+			CreatedAt:    tn,
+			UpdatedAt:    tn,
+			Tags:         []entities.Tag{},
+			CommentCount: func() *int64 { c := int64(0); return &c }(),
 		}}
 	)
 
@@ -130,21 +262,29 @@ func Test_taskService_GetTasks(t *testing.T) {
 		query request.TaskListQuery
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		want    []entities.Task
-		wantErr bool
+		name     string
+		fields   fields
+		args     args
+		want     []entities.Task
+		wantPage response.Pagination
+		wantErr  bool
 	}{
 		{
 			name: "success",
 			fields: fields{
 				repository: base.NewBaseRepository[any](db),
 				repositoryBehavior: func() {
-					users := sqlmock.NewRows([]string{"id", "title", "description", "image", "status", "created_at", "updated_at"}).
+					expectWorkspace(mock, 7)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND title LIKE (.+) AND description LIKE (.+)`).
+						WithArgs(7, "foo%", "foo%").
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
 						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
-					expectedSQL := `SELECT (.+) FROM "tasks" WHERE title LIKE (.+) AND description LIKE (.+)`
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND title LIKE (.+) AND description LIKE (.+) ORDER BY title asc,id asc LIMIT (.+)`
 					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
 				},
 			},
 			args: args{
@@ -153,20 +293,328 @@ func Test_taskService_GetTasks(t *testing.T) {
 					Description: "foo",
 					SortBy:      "title",
 					SortOrder:   "asc",
+					WorkspaceID: 7,
 				},
 			},
-			want:    tasks,
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with next cursor",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn).
+						AddRow(2, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND \(project_id IS NULL (.+)\) AND \(title, id\) < \((.+)\) (.+) ORDER BY title desc,id desc LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					SortBy:    "title",
+					SortOrder: "desc",
+					PageSize:  1,
+					Cursor:    pagination.Encode(pagination.Cursor{Value: "zoo", ID: 9, SortBy: "title", SortOrder: "desc"}),
+				},
+			},
+			want: tasks,
+			wantPage: response.Pagination{
+				PageSize:   1,
+				Total:      2,
+				NextCursor: pagination.Encode(pagination.Cursor{Value: "foo", ID: 1, SortBy: "title", SortOrder: "desc"}),
+			},
 			wantErr: false,
 		},
+		{
+			name: "success with blocked filter",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND \(NOT EXISTS \(SELECT 1 FROM task_dependencies (.+) AND b.status NOT IN \(\$2,\$3\)\)\)`).
+						WithArgs(0, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND \(NOT EXISTS (.+)\) (.+) ORDER BY id asc LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					Blocked: func() *bool { b := false; return &b }(),
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with project",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND project_id = \$2 AND "tasks"."deleted_at" IS NULL`).
+						WithArgs(0, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND project_id = \$2 (.+) ORDER BY id asc LIMIT (.+)`).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					ProjectID: func() *int { id := 3; return &id }(),
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success including archived projects",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND "tasks"."deleted_at" IS NULL`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND "tasks"."deleted_at" IS NULL ORDER BY id asc LIMIT (.+)`).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					IncludeArchived: true,
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with all tags",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND \(\(SELECT count\(\*\) FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id = tasks.id AND t.name IN \(\$2,\$3\)\) = \$4\)`).
+						WithArgs(0, "backend", "bug", 2).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND \(\(SELECT count(.+)\) = (.+)\) (.+) ORDER BY id asc LIMIT (.+)`).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags" WHERE "task_tags"."task_id" = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}).AddRow(1, 3))
+					mock.ExpectQuery(`SELECT \* FROM "tags" WHERE "tags"."id" = \$1`).
+						WithArgs(3).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "bug"))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}).AddRow(1, 2))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					Tags:    []string{"backend", " bug ", "backend"},
+					TagMode: enum.TagModeAll,
+				},
+			},
+			want: []entities.Task{{
+				ID:           1,
+				Title:        "foo",
+				Description:  "foo",
+				ImageKey:     "foo",
+				ImageURL:     "/api/tasks/1/image",
+				Status:       enum.TaskStatusCompleted,
+				CreatedAt:    tn,
+				UpdatedAt:    tn,
+				Tags:         []entities.Tag{{ID: 3, Name: "bug"}},
+				CommentCount: func() *int64 { c := int64(2); return &c }(),
+			}},
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with any tag",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND \(EXISTS \(SELECT 1 FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id = tasks.id AND t.name IN \(\$2\)\)`).
+						WithArgs(0, "bug").
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND \(EXISTS (.+)\) (.+) ORDER BY id asc LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					Tags: []string{"bug"},
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with page",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND \(project_id IS NULL OR project_id NOT IN \(SELECT id FROM projects WHERE archived\)\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" (.+) ORDER BY id asc LIMIT (.+) OFFSET (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					Page:     2,
+					PageSize: 10,
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{Page: 2, PageSize: 10, Total: 11},
+			wantErr:  false,
+		},
+		{
+			name: "success with due filters",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND due_at < (.+) AND due_at > (.+) AND \(due_at < (.+) AND status NOT IN (.+)\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND (.+) AND \(COALESCE\(due_at, 'infinity'\), id\) > \((.+)\) (.+) ORDER BY COALESCE\(due_at, 'infinity'\) asc,id asc LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					SortBy:    "due_at",
+					DueBefore: "2024-02-01T00:00:00Z",
+					DueAfter:  "2024-01-01T00:00:00Z",
+					Overdue:   true,
+					Cursor:    pagination.Encode(pagination.Cursor{Value: "infinity", ID: 9}),
+				},
+			},
+			want:     tasks,
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "success with priority filter and sort",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE workspace_id = \$1 AND priority IN \(\$2,\$3\)`).
+						WithArgs(0, enum.TaskPriorityHigh, enum.TaskPriorityUrgent).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					users := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "priority", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", "URGENT", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND priority IN (.+) AND \(CASE priority WHEN 'LOW' THEN 1 WHEN 'MEDIUM' THEN 2 WHEN 'HIGH' THEN 3 WHEN 'URGENT' THEN 4 ELSE 0 END, id\) < \((.+)\) (.+) ORDER BY CASE priority (.+) END desc,id desc LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectCommit()
+					mock.ExpectQuery(`SELECT task_id, count\(\*\) AS count FROM "comments" WHERE task_id IN \(\$1\) GROUP BY "task_id"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "count"}))
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					SortBy:     "priority",
+					SortOrder:  "desc",
+					Priorities: []enum.TaskPriority{enum.TaskPriorityHigh, enum.TaskPriorityUrgent},
+					Cursor:     pagination.Encode(pagination.Cursor{Value: "4", ID: 9}),
+				},
+			},
+			want: []entities.Task{{
+				ID:           1,
+				Title:        "foo",
+				Description:  "foo",
+				ImageKey:     "foo",
+				ImageURL:     "/api/tasks/1/image",
+				Status:       enum.TaskStatusCompleted,
+				Priority:     enum.TaskPriorityUrgent,
+				CreatedAt:    tn,
+				UpdatedAt:    tn,
+				Tags:         []entities.Tag{},
+				CommentCount: func() *int64 { c := int64(0); return &c }(),
+			}},
+			wantPage: response.Pagination{PageSize: 20, Total: 1},
+			wantErr:  false,
+		},
+		{
+			name: "count tasks failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks"`).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				query: request.TaskListQuery{
+					Title: "foo",
+				},
+			},
+			want:     nil,
+			wantPage: response.Pagination{},
+			wantErr:  true,
+		},
 		{
 			name: "find tasks failed",
 			fields: fields{
 				repository: base.NewBaseRepository[any](db),
 				repositoryBehavior: func() {
+					expectWorkspace(mock, 0)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks"`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 					users := sqlmock.NewRows([]string{"asd"}).
 						AddRow(1)
-					expectedSQL := `SELECT (.+) FROM "tasks" WHERE title LIKE (.+) AND description LIKE (.+)`
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = \$1 AND title LIKE (.+) AND description LIKE (.+)`
 					mock.ExpectQuery(expectedSQL).WillReturnRows(users)
+					mock.ExpectRollback()
 				},
 			},
 			args: args{
@@ -177,8 +625,9 @@ func Test_taskService_GetTasks(t *testing.T) {
 					SortOrder:   "asc",
 				},
 			},
-			want:    nil,
-			wantErr: true,
+			want:     nil,
+			wantPage: response.Pagination{},
+			wantErr:  true,
 		},
 	}
 	for _, tt := range tests {
@@ -186,10 +635,11 @@ func Test_taskService_GetTasks(t *testing.T) {
 			tt.fields.repositoryBehavior()
 			s := taskService{
 				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
 				log:        logger.WithPrefix("test"),
 			}
 
-			got, err := s.GetTasks(tt.args.query)
+			got, page, err := s.GetTasks(tt.args.query)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("taskService.GetTasks() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -197,82 +647,2018 @@ func Test_taskService_GetTasks(t *testing.T) {
 			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("taskService.GetTasks() = %v, want %v", got, tt.want)
 			}
+			if !reflect.DeepEqual(page, tt.wantPage) {
+				t.Errorf("taskService.GetTasks() pagination = %v, want %v", page, tt.wantPage)
+			}
 		})
 	}
 }
 
-func Test_taskService_UpdateTask(t *testing.T) {
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
+func Test_taskService_GetTask(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
 
 	type fields struct {
-		repository         *mock.MockBaseRepository[any]
-		repositoryBehavior func(*mock.MockBaseRepository[any])
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
 	}
 	type args struct {
-		id  int
-		req request.UpdatedTaskRequest
+		id int
 	}
 	tests := []struct {
 		name    string
 		fields  fields
 		args    args
-		wantErr bool
+		want    entities.Task
+		wantErr error
 	}{
 		{
 			name: "success",
 			fields: fields{
-				repository: mock.NewMockBaseRepository[any](ctrl),
-				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
-					mbr.EXPECT().Model(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Where(gomock.Any(), gomock.Any()).Return(mbr)
-					mbr.EXPECT().Updates(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Error().Return(nil)
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					rows := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE id = (.+) ORDER BY "tasks"."id" LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(rows)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectQuery(`WITH RECURSIVE descendants AS`).
+						WithArgs(1, enum.TaskStatusCancelled, enum.TaskStatusCompleted).
+						WillReturnRows(sqlmock.NewRows([]string{"total", "completed"}).AddRow(0, 0))
+					mock.ExpectCommit()
 				},
 			},
 			args: args{
 				id: 1,
-				req: request.UpdatedTaskRequest{
-					Title:       "foo",
-					Description: "foo",
-					Image:       "foo",
-					Status:      enum.TaskStatusCompleted,
-				},
 			},
-			wantErr: false,
+			want: entities.Task{
+				ID:          1,
+				Title:       "foo",
+				Description: "foo",
+				ImageKey:    "foo",
+				ImageURL:    "/api/tasks/1/image",
+				Status:      enum.TaskStatusCompleted,
+				CreatedAt:   tn,
+				UpdatedAt:   tn,
+				Tags:        []entities.Tag{},
+			},
+			wantErr: nil,
 		},
 		{
-			name: "success",
+			name: "success with progress",
 			fields: fields{
-				repository: mock.NewMockBaseRepository[any](ctrl),
-				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
-					mbr.EXPECT().Model(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Where(gomock.Any(), gomock.Any()).Return(mbr)
-					mbr.EXPECT().Updates(gomock.Any()).Return(mbr)
-					mbr.EXPECT().Error().Return(errors.New("foo"))
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					rows := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at"}).
+						AddRow(1, "foo", "foo", "foo", "IN_PROGRESS", tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE id = (.+) ORDER BY "tasks"."id" LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnRows(rows)
+					mock.ExpectQuery(`SELECT \* FROM "task_tags"`).WillReturnRows(sqlmock.NewRows([]string{"task_id", "tag_id"}))
+					mock.ExpectQuery(`WITH RECURSIVE descendants AS`).
+						WithArgs(1, enum.TaskStatusCancelled, enum.TaskStatusCompleted).
+						WillReturnRows(sqlmock.NewRows([]string{"total", "completed"}).AddRow(3, 1))
+					mock.ExpectCommit()
 				},
 			},
 			args: args{
 				id: 1,
-				req: request.UpdatedTaskRequest{
-					Title:       "foo",
-					Description: "foo",
-					Image:       "foo",
-					Status:      enum.TaskStatusCompleted,
-				},
 			},
-			wantErr: true,
+			want: entities.Task{
+				ID:          1,
+				Title:       "foo",
+				Description: "foo",
+				ImageKey:    "foo",
+				ImageURL:    "/api/tasks/1/image",
+				Status:      enum.TaskStatusInProgress,
+				CreatedAt:   tn,
+				UpdatedAt:   tn,
+				Tags:        []entities.Tag{},
+				Progress:    func() *float64 { p := 33.33; return &p }(),
+			},
+			wantErr: nil,
 		},
-	}
-	for _, tt := range tests {
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE id = (.+) ORDER BY "tasks"."id" LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Task{},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "find task failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE id = (.+) ORDER BY "tasks"."id" LIMIT (.+)`
+					mock.ExpectQuery(expectedSQL).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Task{},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetTask(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.GetTask() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskService.GetTask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskService_UpdateTask(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		currentQuery = `SELECT \* FROM "tasks" WHERE id = \$1 AND "tasks"."deleted_at" IS NULL ORDER BY "tasks"."id" LIMIT \$2`
+		blockerQuery = `SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND EXISTS \(SELECT 1 FROM task_dependencies`
+		countQuery   = `SELECT count\(\*\) FROM "tasks" WHERE id = \$1`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id      int
+		version int
+		req     request.UpdatedTaskRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WithArgs(1, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WithArgs(1, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks" SET (.+) WHERE id = \$\d+ AND version = \$\d+ AND status = \$\d+`).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:       "foo",
+					Description: "foo",
+					Status:      enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success without version check",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks" SET (.+) WHERE id = \$\d+ AND status = \$\d+`).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 0,
+				req: request.UpdatedTaskRequest{
+					Title:       "foo",
+					Description: "foo",
+					Status:      enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks"`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:       "foo",
+					Description: "foo",
+					Status:      enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks"`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:       "foo",
+					Description: "foo",
+					Status:      enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: ErrTaskVersionMismatch,
+		},
+		{
+			name: "blocked",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:  "foo",
+					Status: enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: ErrTaskBlocked,
+		},
+		{
+			name: "invalid transition",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "CANCELLED"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:  "foo",
+					Status: enum.TaskStatusInProgress,
+				},
+			},
+			wantErr: &InvalidTransitionError{
+				From:    enum.TaskStatusCancelled,
+				To:      enum.TaskStatusInProgress,
+				Allowed: []enum.TaskStatus{enum.TaskStatusTodo},
+			},
+		},
+		{
+			name: "current task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:  "foo",
+					Status: enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "update failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(currentQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(blockerQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks"`).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req: request.UpdatedTaskRequest{
+					Title:       "foo",
+					Description: "foo",
+					Status:      enum.TaskStatusCompleted,
+				},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.UpdateTask(3, tt.args.id, tt.args.version, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.UpdateTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_DeleteTask(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		deleteQuery = `UPDATE "tasks" SET "deleted_at"=\$1 WHERE id = \$2 AND version = \$3 AND "tasks"."deleted_at" IS NULL`
+		countQuery  = `SELECT count\(\*\) FROM "tasks" WHERE id = \$1`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id      int
+		version int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(deleteQuery).
+						WithArgs(sqlmock.AnyArg(), 1, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+			},
+			wantErr: ErrTaskVersionMismatch,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(deleteQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.DeleteTask(3, tt.args.id, tt.args.version); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.DeleteTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_GetTrashedTasks(t *testing.T) {
+	var (
+		tn    = time.Now()
+		tasks = []entities.Task{{
+			ID:          1,
+			Title:       "foo",
+			Description: "foo",
+			ImageKey:    "foo",
+			ImageURL:    "/api/tasks/1/image",
+			Status:      enum.TaskStatusCompleted,
+			CreatedAt:   tn,
+			UpdatedAt:   tn,
+			DeletedAt:   gorm.DeletedAt{Time: tn, Valid: true},
+		}}
+	)
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.Task
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 7)
+					rows := sqlmock.NewRows([]string{"id", "title", "description", "image_key", "status", "created_at", "updated_at", "deleted_at"}).
+						AddRow(1, "foo", "foo", "foo", "COMPLETED", tn, tn, tn)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = (.+) AND deleted_at IS NOT NULL ORDER BY deleted_at desc`
+					mock.ExpectQuery(expectedSQL).WithArgs(7).WillReturnRows(rows)
+					mock.ExpectCommit()
+				},
+			},
+			want:    tasks,
+			wantErr: false,
+		},
+		{
+			name: "find tasks failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 7)
+					expectedSQL := `SELECT (.+) FROM "tasks" WHERE workspace_id = (.+) AND deleted_at IS NOT NULL ORDER BY deleted_at desc`
+					mock.ExpectQuery(expectedSQL).WithArgs(7).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetTrashedTasks(7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("taskService.GetTrashedTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskService.GetTrashedTasks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskService_RestoreTask(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const restoreQuery = `UPDATE "tasks" SET "deleted_at"=\$1,"updated_at"=\$2 WHERE id = \$3 AND deleted_at IS NOT NULL`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(restoreQuery).
+						WithArgs(nil, sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not in trash",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(restoreQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "restore failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(restoreQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.RestoreTask(3, tt.args.id); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.RestoreTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_PurgeTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		contentsQuery = `SELECT DISTINCT sha256, storage_key FROM "attachments" WHERE task_id IN \(SELECT id FROM tasks WHERE id = \$1 AND deleted_at IS NOT NULL\)`
+		purgeQuery    = `DELETE FROM "tasks" WHERE id = \$1 AND deleted_at IS NOT NULL RETURNING "image_key","thumbnail_key"`
+		lockContent   = `SELECT pg_advisory_xact_lock\(\$1, hashtext\(\$2\)\)`
+		countContent  = `SELECT count\(\*\) FROM "attachments" WHERE sha256 = \$1`
+	)
+
+	type fields struct {
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(contentsQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}).
+							AddRow("a", "attachments/a").
+							AddRow("b", "attachments/b"))
+					mock.ExpectQuery(purgeQuery).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"image_key", "thumbnail_key"}).AddRow("tasks/1/c.png", "tasks/1/c_thumb.jpg"))
+					mock.ExpectCommit()
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/c.png").Return(nil)
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/c_thumb.jpg").Return(errors.New("foo"))
+					mock.ExpectBegin()
+					mock.ExpectExec(lockContent).WithArgs(attachmentLockKey, "a").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countContent).WithArgs("a").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mbs.EXPECT().Delete(gomock.Any(), "attachments/a").Return(nil)
+					mock.ExpectCommit()
+					// another task still has the content
+					mock.ExpectBegin()
+					mock.ExpectExec(lockContent).WithArgs(attachmentLockKey, "b").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(countContent).WithArgs("b").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task without blobs",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(contentsQuery).WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}))
+					mock.ExpectQuery(purgeQuery).WillReturnRows(sqlmock.NewRows([]string{"image_key", "thumbnail_key"}).AddRow("", ""))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not in trash",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(contentsQuery).WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}))
+					mock.ExpectQuery(purgeQuery).WillReturnRows(sqlmock.NewRows([]string{"image_key", "thumbnail_key"}))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "purge failed",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(contentsQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}).AddRow("a", "attachments/a"))
+					mock.ExpectQuery(purgeQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := taskService{
+				repository: base.NewBaseRepository[any](db),
+				store:      tt.fields.store,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.PurgeTask(3, tt.args.id); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.PurgeTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_PurgeExpiredTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	before := time.Now()
+
+	type fields struct {
+		store              *storagemock.MockBlobStore
+		repositoryBehavior func(*storagemock.MockBlobStore)
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(`SELECT DISTINCT sha256, storage_key FROM "attachments" WHERE task_id IN \(SELECT id FROM tasks WHERE deleted_at < \$1\)`).
+						WithArgs(before).
+						WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}).AddRow("a", "attachments/a"))
+					mock.ExpectQuery(`DELETE FROM "tasks" WHERE deleted_at < \$1 RETURNING "image_key","thumbnail_key"`).
+						WithArgs(before).
+						WillReturnRows(sqlmock.NewRows([]string{"image_key", "thumbnail_key"}).
+							AddRow("tasks/1/c.png", "tasks/1/c_thumb.jpg").
+							AddRow("", "").
+							AddRow("", ""))
+					mock.ExpectCommit()
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/c.png").Return(nil)
+					mbs.EXPECT().Delete(gomock.Any(), "tasks/1/c_thumb.jpg").Return(nil)
+					mock.ExpectBegin()
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(attachmentLockKey, "a").WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "attachments" WHERE sha256 = \$1`).WithArgs("a").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mbs.EXPECT().Delete(gomock.Any(), "attachments/a").Return(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    3,
+			wantErr: false,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				store: storagemock.NewMockBlobStore(ctrl),
+				repositoryBehavior: func(mbs *storagemock.MockBlobStore) {
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(`SELECT DISTINCT sha256, storage_key FROM "attachments"`).
+						WillReturnRows(sqlmock.NewRows([]string{"sha256", "storage_key"}))
+					mock.ExpectQuery(`DELETE FROM "tasks" WHERE deleted_at < (.+)`).
+						WithArgs(before).
+						WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior(tt.fields.store)
+			s := taskService{
+				repository: base.NewBaseRepository[any](db),
+				store:      tt.fields.store,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.PurgeExpiredTasks(before)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("taskService.PurgeExpiredTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("taskService.PurgeExpiredTasks() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_PatchTask(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id      int
+		version int
+		req     request.PatchedTaskRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success clears only present fields",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "description"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4 AND "tasks"."deleted_at" IS NULL`).
+						WithArgs("", sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Description: request.Optional[string]{Set: true, Null: true},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "success replaces tags",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "updated_at"=\$1,"version"=version \+ 1 WHERE id = \$2 AND version = \$3`).
+						WithArgs(sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`INSERT INTO "tags" (.+) ON CONFLICT DO NOTHING RETURNING "id"`).
+						WithArgs(3, "bug", sqlmock.AnyArg(), sqlmock.AnyArg(), 3, "ui", sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE task_id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`INSERT INTO task_tags \(task_id, tag_id\) SELECT \$1, id FROM tags WHERE workspace_id = \$2 AND name IN \(\$3,\$4\)`).
+						WithArgs(1, 3, "bug", "ui").
+						WillReturnResult(sqlmock.NewResult(0, 2))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Tags: request.Optional[[]string]{Set: true, Value: []string{"bug", "ui", "bug"}},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "null tags clears them",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "updated_at"=\$1,"version"=version \+ 1 WHERE id = \$2 AND version = \$3`).
+						WithArgs(sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(`DELETE FROM task_tags WHERE task_id = \$1`).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Tags: request.Optional[[]string]{Set: true, Null: true},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "title"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WithArgs("foo", sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Title: request.Optional[string]{Set: true, Value: "foo"},
+				},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "title"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WithArgs("foo", sqlmock.AnyArg(), 1, 2).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Title: request.Optional[string]{Set: true, Value: "foo"},
+				},
+			},
+			wantErr: ErrTaskVersionMismatch,
+		},
+		{
+			name: "schedule violated",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "due_at"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WillReturnError(&pgconn.PgError{Code: "23514"})
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					DueAt: request.Optional[time.Time]{Set: true, Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+			},
+			wantErr: ErrInvalidSchedule,
+		},
+		{
+			name: "cascade completes descendants",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND EXISTS \(SELECT 1 FROM task_dependencies`).
+						WithArgs(1, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks" SET "completed_at"=\$1,"status"=\$2,"updated_at"=\$3,"version"=version \+ 1 WHERE id = \$4 AND version = \$5 AND status = \$6`).
+						WithArgs(sqlmock.AnyArg(), enum.TaskStatusCompleted, sqlmock.AnyArg(), 1, 2, enum.TaskStatusInProgress).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`WITH RECURSIVE descendants AS (.+) SELECT count\(\*\) FROM tasks (.+) AND EXISTS \(SELECT 1 FROM task_dependencies (.+) AND b.id NOT IN \(SELECT id FROM descendants\)\)`).
+						WithArgs(1, enum.TaskStatusCompleted, enum.TaskStatusCancelled, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectQuery(`WITH RECURSIVE descendants AS (.+) UPDATE tasks SET status = \$2, (.+) RETURNING id`).
+						WithArgs(1, enum.TaskStatusCompleted, sqlmock.AnyArg(), sqlmock.AnyArg(), enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3).AddRow(4))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Status:  request.Optional[enum.TaskStatus]{Set: true, Value: enum.TaskStatusCompleted},
+					Cascade: true,
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "cascade with a blocked descendant",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND EXISTS \(SELECT 1 FROM task_dependencies`).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks" SET "completed_at"=\$1,"status"=\$2,"updated_at"=\$3,"version"=version \+ 1 WHERE id = \$4 AND version = \$5 AND status = \$6`).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`WITH RECURSIVE descendants AS (.+) SELECT count\(\*\) FROM tasks`).
+						WithArgs(1, enum.TaskStatusCompleted, enum.TaskStatusCancelled, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Status:  request.Optional[enum.TaskStatus]{Set: true, Value: enum.TaskStatusCompleted},
+					Cascade: true,
+				},
+			},
+			wantErr: ErrTaskBlocked,
+		},
+		{
+			name: "update failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE \(id = \$1 AND EXISTS \(SELECT 1 FROM task_dependencies`).
+						WithArgs(1, enum.TaskStatusCompleted, enum.TaskStatusCancelled).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`UPDATE "tasks" SET "completed_at"=\$1,"status"=\$2,"updated_at"=\$3,"version"=version \+ 1 WHERE id = \$4 AND version = \$5 AND status = \$6`).
+						WithArgs(sqlmock.AnyArg(), enum.TaskStatusCompleted, sqlmock.AnyArg(), 1, 2, enum.TaskStatusInProgress).
+						WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 2,
+				req: request.PatchedTaskRequest{
+					Status: request.Optional[enum.TaskStatus]{Set: true, Value: enum.TaskStatusCompleted},
+				},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.PatchTask(3, tt.args.id, tt.args.version, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.PatchTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_CheckWorkspace(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const countQuery = `SELECT count\(\*\) FROM "tasks" WHERE id = \$1 AND workspace_id = \$2$`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "owner",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 7)
+					mock.ExpectQuery(countQuery).
+						WithArgs(1, 7).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task of another user",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 7)
+					mock.ExpectQuery(countQuery).
+						WithArgs(1, 7).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "count failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 7)
+					mock.ExpectQuery(countQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.CheckWorkspace(1, 7)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.CheckWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_taskService_GetChildren(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    []entities.Task
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE parent_id = \$1 AND "tasks"."deleted_at" IS NULL ORDER BY id`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "title", "status", "created_at", "updated_at"}).
+							AddRow(2, 1, "foo", "TODO", tn, tn))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want: []entities.Task{{
+				ID:        2,
+				ParentID:  func() *int { id := 1; return &id }(),
+				Title:     "foo",
+				Status:    enum.TaskStatusTodo,
+				CreatedAt: tn,
+				UpdatedAt: tn,
+			}},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    nil,
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetChildren(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.GetChildren() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskService.GetChildren() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskService_GetTaskTree(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    entities.Task
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, "IN_PROGRESS"))
+					mock.ExpectQuery(`WITH RECURSIVE tree AS`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "parent_id", "status"}).
+							AddRow(2, 1, "IN_PROGRESS").
+							AddRow(3, 1, "CANCELLED").
+							AddRow(4, 2, "COMPLETED").
+							AddRow(5, 2, "TODO"))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want: func() entities.Task {
+				intPtr := func(i int) *int { return &i }
+				floatPtr := func(f float64) *float64 { return &f }
+				return entities.Task{
+					ID:       1,
+					Status:   enum.TaskStatusInProgress,
+					Progress: floatPtr(33.33),
+					Children: []entities.Task{
+						{
+							ID:       2,
+							ParentID: intPtr(1),
+							Status:   enum.TaskStatusInProgress,
+							Progress: floatPtr(50),
+							Children: []entities.Task{
+								{ID: 4, ParentID: intPtr(2), Status: enum.TaskStatusCompleted},
+								{ID: 5, ParentID: intPtr(2), Status: enum.TaskStatusTodo},
+							},
+						},
+						{ID: 3, ParentID: intPtr(1), Status: enum.TaskStatusCancelled},
+					},
+				}
+			}(),
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    entities.Task{},
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetTaskTree(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.GetTaskTree() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskService.GetTaskTree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskService_MoveTask(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	parentID := 2
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id      int
+		version int
+		req     request.MovedTaskRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+						WithArgs(parentID, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(3))
+					mock.ExpectExec(`UPDATE "tasks" SET "parent_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WithArgs(parentID, sqlmock.AnyArg(), 1, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.MovedTaskRequest{ParentID: &parentID},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "move to root",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`UPDATE "tasks" SET "parent_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WithArgs(nil, sqlmock.AnyArg(), 1, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.MovedTaskRequest{},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "cycle",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+						WithArgs(parentID, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.MovedTaskRequest{ParentID: &parentID},
+			},
+			wantErr: ErrTaskCycle,
+		},
+		{
+			name: "move under itself",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      2,
+				version: 1,
+				req:     request.MovedTaskRequest{ParentID: &parentID},
+			},
+			wantErr: ErrTaskCycle,
+		},
+		{
+			name: "parent not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+						WithArgs(parentID, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.MovedTaskRequest{ParentID: &parentID},
+			},
+			wantErr: ErrParentNotFound,
+		},
+		{
+			name: "version mismatch",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectExec(`UPDATE "tasks" SET "parent_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.MovedTaskRequest{},
+			},
+			wantErr: ErrTaskVersionMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.MoveTask(3, tt.args.id, tt.args.version, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.MoveTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_GetDependencies(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    response.TaskDependencies
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id IN \(SELECT blocked_by_id FROM task_dependencies WHERE task_id = \$1\)`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "title", "status", "created_at", "updated_at"}).
+							AddRow(2, "foo", "TODO", tn, tn))
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id IN \(SELECT task_id FROM task_dependencies WHERE blocked_by_id = \$1\)`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want: response.TaskDependencies{
+				BlockedBy: []entities.Task{{
+					ID:        2,
+					Title:     "foo",
+					Status:    enum.TaskStatusTodo,
+					CreatedAt: tn,
+					UpdatedAt: tn,
+				}},
+				Blocks: []entities.Task{},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT (.+) FROM "tasks" WHERE id = \$1`).
+						WillReturnError(gorm.ErrRecordNotFound)
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id: 1,
+			},
+			want:    response.TaskDependencies{},
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetDependencies(3, tt.args.id)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.GetDependencies() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("taskService.GetDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_taskService_AddDependency(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id  int
+		req request.CreatedDependencyRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT "id" FROM "tasks" WHERE \(id IN \(\$1,\$2\) AND workspace_id = \(SELECT workspace_id FROM tasks WHERE id = \$3\)\)`).
+						WithArgs(1, 2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+					mock.ExpectQuery(`WITH RECURSIVE blockers AS`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectExec(`INSERT INTO "task_dependencies" (.+) ON CONFLICT DO NOTHING`).
+						WithArgs(1, 2, sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.CreatedDependencyRequest{BlockedByID: 2},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "self dependency",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.CreatedDependencyRequest{BlockedByID: 1},
+			},
+			wantErr: ErrDependencyCycle,
+		},
+		{
+			name: "cycle",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT "id" FROM "tasks" WHERE \(id IN \(\$1,\$2\) AND workspace_id = \(SELECT workspace_id FROM tasks WHERE id = \$3\)\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+					mock.ExpectQuery(`WITH RECURSIVE blockers AS`).
+						WithArgs(2, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.CreatedDependencyRequest{BlockedByID: 2},
+			},
+			wantErr: ErrDependencyCycle,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT "id" FROM "tasks" WHERE \(id IN \(\$1,\$2\) AND workspace_id = \(SELECT workspace_id FROM tasks WHERE id = \$3\)\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.CreatedDependencyRequest{BlockedByID: 2},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+		{
+			name: "blocker not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT "id" FROM "tasks" WHERE \(id IN \(\$1,\$2\) AND workspace_id = \(SELECT workspace_id FROM tasks WHERE id = \$3\)\)`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.CreatedDependencyRequest{BlockedByID: 2},
+			},
+			wantErr: ErrBlockerNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.AddDependency(3, tt.args.id, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.AddDependency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_taskService_RemoveDependency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type fields struct {
+		repository         *mock.MockBaseRepository[any]
+		repositoryBehavior func(*mock.MockBaseRepository[any])
+	}
+	type args struct {
+		id          int
+		blockedByID int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("task_id = ? AND blocked_by_id = ?", 1, 2).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(nil)
+					mbr.EXPECT().RowsAffected().Return(int64(1))
+				},
+			},
+			args: args{
+				id:          1,
+				blockedByID: 2,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "dependency not found",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("task_id = ? AND blocked_by_id = ?", 1, 2).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(nil)
+					mbr.EXPECT().RowsAffected().Return(int64(0))
+				},
+			},
+			args: args{
+				id:          1,
+				blockedByID: 2,
+			},
+			wantErr: ErrDependencyNotFound,
+		},
+		{
+			name: "delete failed",
+			fields: fields{
+				repository: mock.NewMockBaseRepository[any](ctrl),
+				repositoryBehavior: func(mbr *mock.MockBaseRepository[any]) {
+					mbr.EXPECT().Where("task_id = ? AND blocked_by_id = ?", 1, 2).Return(mbr)
+					mbr.EXPECT().Delete(gomock.Any()).Return(mbr)
+					mbr.EXPECT().Error().Return(errors.New("foo"))
+				},
+			},
+			args: args{
+				id:          1,
+				blockedByID: 2,
+			},
+			wantErr: errors.New("foo"),
+		},
+	}
+	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.fields.repositoryBehavior(tt.fields.repository)
 			s := taskService{
 				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
 				log:        logger.WithPrefix("test"),
 			}
-			if err := s.UpdateTask(tt.args.id, tt.args.req); (err != nil) != tt.wantErr {
-				t.Errorf("taskService.UpdateTask() error = %v, wantErr %v", err, tt.wantErr)
+			if err := s.RemoveDependency(3, tt.args.id, tt.args.blockedByID); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.RemoveDependency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_taskService_AssignProject(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	projectID := 3
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		id      int
+		version int
+		req     request.AssignedProjectRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(projectID, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+					mock.ExpectExec(`UPDATE "tasks" SET "project_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WithArgs(projectID, sqlmock.AnyArg(), 1, 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.AssignedProjectRequest{ProjectID: &projectID},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "remove from project",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "project_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3`).
+						WithArgs(nil, sqlmock.AnyArg(), 1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				id:  1,
+				req: request.AssignedProjectRequest{},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "project not found or of another workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "projects" WHERE id = \$1 AND workspace_id = \$2`).
+						WithArgs(projectID, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.AssignedProjectRequest{ProjectID: &projectID},
+			},
+			wantErr: ErrProjectNotFound,
+		},
+		{
+			name: "task not found",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					expectWorkspace(mock, 3)
+					mock.ExpectExec(`UPDATE "tasks" SET "project_id"=\$1,"updated_at"=\$2,"version"=version \+ 1 WHERE id = \$3 AND version = \$4`).
+						WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectQuery(`SELECT count\(\*\) FROM "tasks" WHERE id = \$1`).
+						WithArgs(1).
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				id:      1,
+				version: 1,
+				req:     request.AssignedProjectRequest{},
+			},
+			wantErr: ErrTaskNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := taskService{
+				repository: tt.fields.repository,
+				workflow:   workflow.Default(),
+				log:        logger.WithPrefix("test"),
+			}
+			if err := s.AssignProject(3, tt.args.id, tt.args.version, tt.args.req); !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("taskService.AssignProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("there were unfulfilled expectations: %s", err)
 			}
 		})
 	}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/api/models/response"
+	"todo/pkg/base"
+	"todo/pkg/database"
+	"todo/pkg/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrWorkspaceNotFound  = errors.New("workspace not found")
+	ErrMemberNotFound     = errors.New("member not found")
+	ErrInvitationNotFound = errors.New("invitation is invalid or expired")
+	ErrAlreadyMember      = errors.New("user is already a member of the workspace")
+	ErrLastOwner          = errors.New("workspace must keep at least one owner")
+	ErrRoleTooHigh        = errors.New("role ranks above your own")
+)
+
+const (
+	// PersonalWorkspaceName names the workspace created on first use for
+	// users without one.
+	PersonalWorkspaceName = "Personal"
+	InvitationTTL         = 7 * 24 * time.Hour
+)
+
+type WorkspaceService interface {
+	CreateWorkspace(userID int, req request.CreatedWorkspaceRequest) (entities.Workspace, error)
+	GetWorkspaces(userID int) ([]entities.Workspace, error)
+	// DefaultWorkspace is the workspace of requests naming none: the oldest
+	// one the user belongs to. A personal workspace is created, taking over
+	// the user's tasks from before workspaces, when there is none.
+	DefaultWorkspace(userID int) (int, error)
+	// GetRole returns ErrWorkspaceNotFound unless the user is a member.
+	GetRole(workspaceID int, userID int) (enum.Role, error)
+	GetMembers(workspaceID int) ([]entities.Membership, error)
+	CreateInvitation(workspaceID int, inviterID int, inviterRole enum.Role, req request.CreatedInvitationRequest) (response.CreatedInvitation, error)
+	AcceptInvitation(userID int, req request.AcceptedInvitationRequest) (entities.Membership, error)
+	LeaveWorkspace(workspaceID int, userID int) error
+	// RemoveMember removes userID on behalf of a member with role, who can't
+	// remove anyone ranking above them.
+	RemoveMember(workspaceID int, role enum.Role, userID int) error
+}
+
+type workspaceService struct {
+	repository base.BaseRepository[any]
+	events     *TaskEvents
+	log        logger.Logger
+}
+
+func NewWorkspaceService(repository base.BaseRepository[any], events *TaskEvents) WorkspaceService {
+	return &workspaceService{
+		repository: repository,
+		events:     events,
+		log:        logger.WithPrefix("service/workspace"),
+	}
+}
+
+func (s workspaceService) CreateWorkspace(userID int, req request.CreatedWorkspaceRequest) (entities.Workspace, error) {
+	var workspace entities.Workspace
+	err := s.repository.Transaction(func(tx *gorm.DB) error {
+		var err error
+		workspace, err = createWorkspace(base.Wrap[any](tx), userID, strings.TrimSpace(req.Name))
+		return err
+	})
+	if err != nil {
+		return entities.Workspace{}, err
+	}
+
+	return workspace, nil
+}
+
+func (s workspaceService) GetWorkspaces(userID int) ([]entities.Workspace, error) {
+	var memberships []entities.Membership
+	err := s.repository.Preload("Workspace").Where("user_id = ?", userID).Order("workspace_id").Find(&memberships).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces := make([]entities.Workspace, 0, len(memberships))
+	for _, membership := range memberships {
+		if membership.Workspace == nil {
+			continue
+		}
+		workspace := *membership.Workspace
+		workspace.Role = membership.Role
+		workspaces = append(workspaces, workspace)
+	}
+
+	return workspaces, nil
+}
+
+func (s workspaceService) DefaultWorkspace(userID int) (int, error) {
+	workspaceID, err := firstWorkspace(s.repository, userID)
+	if err != nil || workspaceID > 0 {
+		return workspaceID, err
+	}
+
+	// the user's tasks from before workspaces belong to none, so no single
+	// workspace setting shows them
+	var moved []int
+	err = database.AllWorkspaces(s.repository, func(repository base.BaseRepository[any]) error {
+		// locking the user keeps concurrent first requests from creating a
+		// workspace each
+		var user entities.User
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error()
+		if err != nil {
+			return err
+		}
+		workspaceID, err = firstWorkspace(repository, userID)
+		if err != nil || workspaceID > 0 {
+			return err
+		}
+
+		workspace, err := createWorkspace(repository, userID, PersonalWorkspaceName)
+		if err != nil {
+			return err
+		}
+		workspaceID = workspace.ID
+
+		return repository.Raw("UPDATE tasks SET workspace_id = ? WHERE owner_id = ? AND workspace_id IS NULL RETURNING id", workspace.ID, userID).Scan(&moved).Error()
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// the tasks are new to the workspace
+	s.events.publish(enum.TaskEventCreated, workspaceID, moved...)
+	return workspaceID, nil
+}
+
+func (s workspaceService) GetRole(workspaceID int, userID int) (enum.Role, error) {
+	var membership entities.Membership
+	err := s.repository.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&membership).Error()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrWorkspaceNotFound
+		}
+		return "", err
+	}
+
+	return membership.Role, nil
+}
+
+func (s workspaceService) GetMembers(workspaceID int) ([]entities.Membership, error) {
+	memberships := []entities.Membership{}
+	err := s.repository.Preload("User").Where("workspace_id = ?", workspaceID).Order("created_at, user_id").Find(&memberships).Error()
+	if err != nil {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+// CreateInvitation answers with the invitation token, for the inviter to
+// pass on. Only its hash is stored.
+func (s workspaceService) CreateInvitation(workspaceID int, inviterID int, inviterRole enum.Role, req request.CreatedInvitationRequest) (response.CreatedInvitation, error) {
+	role := req.Role
+	if len(role) == 0 {
+		role = enum.RoleMember
+	}
+	if role.Rank() > inviterRole.Rank() {
+		return response.CreatedInvitation{}, ErrRoleTooHigh
+	}
+
+	email := normalizeEmail(req.Email)
+	var count int64
+	err := s.repository.Model(&entities.Membership{}).
+		Joins("JOIN users ON users.id = memberships.user_id").
+		Where("memberships.workspace_id = ? AND users.email = ?", workspaceID, email).
+		Count(&count).Error()
+	if err != nil {
+		return response.CreatedInvitation{}, err
+	}
+	if count > 0 {
+		return response.CreatedInvitation{}, ErrAlreadyMember
+	}
+
+	token, err := randomToken(32)
+	if err != nil {
+		return response.CreatedInvitation{}, err
+	}
+
+	tn := time.Now()
+	invitation := entities.Invitation{
+		WorkspaceID: workspaceID,
+		Email:       email,
+		Role:        role,
+		TokenHash:   hashToken(token),
+		InvitedByID: inviterID,
+		ExpiresAt:   tn.Add(InvitationTTL),
+		CreatedAt:   tn,
+	}
+	err = s.repository.Create(&invitation).Error()
+	if err != nil {
+		return response.CreatedInvitation{}, err
+	}
+
+	return response.CreatedInvitation{Invitation: invitation, Token: token}, nil
+}
+
+// AcceptInvitation makes the user a member if they registered with the
+// invited email. The invitation is used up either way.
+func (s workspaceService) AcceptInvitation(userID int, req request.AcceptedInvitationRequest) (entities.Membership, error) {
+	var membership entities.Membership
+	err := s.repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+
+		var invitation entities.Invitation
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND expires_at > ?", hashToken(req.Token), time.Now()).
+			First(&invitation).Error()
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+
+		var user entities.User
+		err = repository.Select("id", "email").Where("id = ?", userID).First(&user).Error()
+		if err != nil {
+			return err
+		}
+		// an invitation for someone else reads as an unknown one
+		if normalizeEmail(user.Email) != invitation.Email {
+			return ErrInvitationNotFound
+		}
+
+		err = repository.Where("id = ?", invitation.ID).Delete(&entities.Invitation{}).Error()
+		if err != nil {
+			return err
+		}
+
+		membership = entities.Membership{
+			WorkspaceID: invitation.WorkspaceID,
+			UserID:      userID,
+			Role:        invitation.Role,
+			CreatedAt:   time.Now(),
+		}
+		result := repository.Clauses(clause.OnConflict{DoNothing: true}).Create(&membership)
+		err = result.Error()
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return ErrAlreadyMember
+		}
+
+		return nil
+	})
+	if err != nil {
+		return entities.Membership{}, err
+	}
+
+	return membership, nil
+}
+
+func (s workspaceService) LeaveWorkspace(workspaceID int, userID int) error {
+	return s.removeMember(workspaceID, enum.RoleOwner, userID)
+}
+
+func (s workspaceService) RemoveMember(workspaceID int, role enum.Role, userID int) error {
+	return s.removeMember(workspaceID, role, userID)
+}
+
+func (s workspaceService) removeMember(workspaceID int, role enum.Role, userID int) error {
+	return s.repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+
+		// locking every membership of the workspace serializes removals, so
+		// two owners can't remove each other at once
+		var memberships []entities.Membership
+		err := repository.Clauses(clause.Locking{Strength: "UPDATE"}).Where("workspace_id = ?", workspaceID).Find(&memberships).Error()
+		if err != nil {
+			return err
+		}
+
+		owners := 0
+		var member *entities.Membership
+		for i, membership := range memberships {
+			if membership.Role == enum.RoleOwner {
+				owners++
+			}
+			if membership.UserID == userID {
+				member = &memberships[i]
+			}
+		}
+		if member == nil {
+			return ErrMemberNotFound
+		}
+		if member.Role.Rank() > role.Rank() {
+			return ErrRoleTooHigh
+		}
+		if member.Role == enum.RoleOwner && owners == 1 {
+			return ErrLastOwner
+		}
+
+		return repository.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&entities.Membership{}).Error()
+	})
+}
+
+func firstWorkspace(repository base.BaseRepository[any], userID int) (int, error) {
+	var memberships []entities.Membership
+	err := repository.Where("user_id = ?", userID).Order("workspace_id").Limit(1).Find(&memberships).Error()
+	if err != nil {
+		return 0, err
+	}
+	if len(memberships) == 0 {
+		return 0, nil
+	}
+
+	return memberships[0].WorkspaceID, nil
+}
+
+func createWorkspace(repository base.BaseRepository[any], userID int, name string) (entities.Workspace, error) {
+	tn := time.Now()
+	workspace := entities.Workspace{
+		Name:      name,
+		CreatedAt: tn,
+		UpdatedAt: tn,
+	}
+	err := repository.Create(&workspace).Error()
+	if err != nil {
+		return entities.Workspace{}, err
+	}
+
+	err = repository.Create(&entities.Membership{
+		WorkspaceID: workspace.ID,
+		UserID:      userID,
+		Role:        enum.RoleOwner,
+		CreatedAt:   tn,
+	}).Error()
+	if err != nil {
+		return entities.Workspace{}, err
+	}
+	workspace.Role = enum.RoleOwner
+
+	return workspace, nil
+}
@@ -0,0 +1,762 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/api/models/request"
+	"todo/pkg/base"
+	"todo/pkg/logger"
+	"todo/pkg/pubsub"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const (
+	workspaceInsertQuery  = `INSERT INTO "workspaces" \("name","created_at","updated_at"\) VALUES \(\$1,\$2,\$3\) RETURNING "id"`
+	membershipInsertQuery = `INSERT INTO "memberships" \("workspace_id","user_id","role","created_at"\) VALUES \(\$1,\$2,\$3,\$4\)`
+)
+
+func Test_workspaceService_CreateWorkspace(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		req request.CreatedWorkspaceRequest
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		want    entities.Workspace
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(workspaceInsertQuery).
+						WithArgs("Team", sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+					mock.ExpectExec(membershipInsertQuery).
+						WithArgs(3, 7, "owner", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				req: request.CreatedWorkspaceRequest{Name: " Team "},
+			},
+			want:    entities.Workspace{ID: 3, Name: "Team", Role: enum.RoleOwner},
+			wantErr: false,
+		},
+		{
+			name: "create membership failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(workspaceInsertQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+					mock.ExpectExec(membershipInsertQuery).WillReturnError(errors.New("foo"))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				req: request.CreatedWorkspaceRequest{Name: "Team"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.CreateWorkspace(7, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("workspaceService.CreateWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			got.CreatedAt, got.UpdatedAt = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workspaceService.CreateWorkspace() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_GetWorkspaces(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    []entities.Workspace
+		wantErr bool
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "memberships" WHERE user_id = \$1 ORDER BY workspace_id`).
+						WithArgs(7).
+						WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "user_id", "role", "created_at"}).
+							AddRow(1, 7, "owner", tn).
+							AddRow(3, 7, "viewer", tn))
+					mock.ExpectQuery(`SELECT \* FROM "workspaces" WHERE "workspaces"."id" IN \(\$1,\$2\)`).
+						WithArgs(1, 3).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at", "updated_at"}).
+							AddRow(1, "Personal", tn, tn).
+							AddRow(3, "Team", tn, tn))
+				},
+			},
+			want: []entities.Workspace{
+				{ID: 1, Name: "Personal", CreatedAt: tn, UpdatedAt: tn, Role: enum.RoleOwner},
+				{ID: 3, Name: "Team", CreatedAt: tn, UpdatedAt: tn, Role: enum.RoleViewer},
+			},
+			wantErr: false,
+		},
+		{
+			name: "find failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(`SELECT \* FROM "memberships"`).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    nil,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetWorkspaces(7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("workspaceService.GetWorkspaces() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workspaceService.GetWorkspaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_DefaultWorkspace(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const firstQuery = `SELECT \* FROM "memberships" WHERE user_id = \$1 ORDER BY workspace_id LIMIT \$2`
+	columns := []string{"workspace_id", "user_id", "role", "created_at"}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    int
+		wantErr bool
+		// wantPublished are the tasks announced to the workspace
+		wantPublished []int
+	}{
+		{
+			name: "member of a workspace",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(firstQuery).
+						WithArgs(7, 1).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 7, "member", tn))
+				},
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "personal workspace created",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(firstQuery).WillReturnRows(sqlmock.NewRows(columns))
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(`SELECT "id" FROM "users" WHERE id = \$1 ORDER BY "users"."id" LIMIT \$2 FOR UPDATE`).
+						WithArgs(7, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+					mock.ExpectQuery(firstQuery).WillReturnRows(sqlmock.NewRows(columns))
+					mock.ExpectQuery(workspaceInsertQuery).
+						WithArgs(PersonalWorkspaceName, sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+					mock.ExpectExec(membershipInsertQuery).
+						WithArgs(4, 7, "owner", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectQuery(`UPDATE tasks SET workspace_id = \$1 WHERE owner_id = \$2 AND workspace_id IS NULL RETURNING id`).
+						WithArgs(4, 7).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10).AddRow(11))
+					mock.ExpectCommit()
+				},
+			},
+			want:          4,
+			wantErr:       false,
+			wantPublished: []int{10, 11},
+		},
+		{
+			name: "created by a concurrent request",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(firstQuery).WillReturnRows(sqlmock.NewRows(columns))
+					expectAllWorkspaces(mock)
+					mock.ExpectQuery(`SELECT "id" FROM "users"`).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+					mock.ExpectQuery(firstQuery).WillReturnRows(sqlmock.NewRows(columns).AddRow(5, 7, "owner", tn))
+					mock.ExpectCommit()
+				},
+			},
+			want:    5,
+			wantErr: false,
+		},
+		{
+			name: "find failed",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(firstQuery).WillReturnError(errors.New("foo"))
+				},
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			broker := pubsub.NewMemoryBroker(pubsub.DefaultHistory)
+			sub := broker.Subscribe(taskEventsTopic, 0)
+			defer sub.Close()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				events:     NewTaskEvents(broker, nil, 0),
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.DefaultWorkspace(7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("workspaceService.DefaultWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("workspaceService.DefaultWorkspace() = %v, want %v", got, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+			if got := publishedTaskIDs(t, sub, enum.TaskEventCreated); !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("workspaceService.DefaultWorkspace() published %v, want %v", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_GetRole(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const findQuery = `SELECT \* FROM "memberships" WHERE workspace_id = \$1 AND user_id = \$2 ORDER BY "memberships"."workspace_id" LIMIT \$3`
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    enum.Role
+		wantErr error
+	}{
+		{
+			name: "member",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).
+						WithArgs(3, 7, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"workspace_id", "user_id", "role", "created_at"}).AddRow(3, 7, "admin", tn))
+				},
+			},
+			want:    enum.RoleAdmin,
+			wantErr: nil,
+		},
+		{
+			name: "not a member",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(findQuery).WillReturnRows(sqlmock.NewRows([]string{"workspace_id"}))
+				},
+			},
+			want:    "",
+			wantErr: ErrWorkspaceNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.GetRole(3, 7)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("workspaceService.GetRole() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("workspaceService.GetRole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_CreateInvitation(t *testing.T) {
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		memberQuery = `SELECT count\(\*\) FROM "memberships" JOIN users ON users.id = memberships.user_id WHERE memberships.workspace_id = \$1 AND users.email = \$2`
+		insertQuery = `INSERT INTO "invitations" \("workspace_id","email","role","token_hash","invited_by_id","expires_at","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) RETURNING "id"`
+	)
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		role enum.Role
+		req  request.CreatedInvitationRequest
+	}
+	tests := []struct {
+		name     string
+		fields   fields
+		args     args
+		wantRole enum.Role
+		wantErr  error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(memberQuery).
+						WithArgs(3, "john@example.com").
+						WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+					mock.ExpectBegin()
+					mock.ExpectQuery(insertQuery).
+						WithArgs(3, "john@example.com", "member", sqlmock.AnyArg(), 7, sqlmock.AnyArg(), sqlmock.AnyArg()).
+						WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				role: enum.RoleAdmin,
+				req:  request.CreatedInvitationRequest{Email: "John@Example.com"},
+			},
+			wantRole: enum.RoleMember,
+			wantErr:  nil,
+		},
+		{
+			name: "role above the inviter",
+			fields: fields{
+				repository:         base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {},
+			},
+			args: args{
+				role: enum.RoleAdmin,
+				req:  request.CreatedInvitationRequest{Email: "john@example.com", Role: enum.RoleOwner},
+			},
+			wantErr: ErrRoleTooHigh,
+		},
+		{
+			name: "already a member",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectQuery(memberQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				},
+			},
+			args: args{
+				role: enum.RoleOwner,
+				req:  request.CreatedInvitationRequest{Email: "john@example.com", Role: enum.RoleAdmin},
+			},
+			wantErr: ErrAlreadyMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.CreateInvitation(3, 7, tt.args.role, tt.args.req)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("workspaceService.CreateInvitation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Role != tt.wantRole {
+				t.Errorf("workspaceService.CreateInvitation() role = %v, want %v", got.Role, tt.wantRole)
+			}
+			if len(got.Token) == 0 || got.TokenHash != hashToken(got.Token) {
+				t.Errorf("workspaceService.CreateInvitation() token hash does not match the token")
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_AcceptInvitation(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		findQuery   = `SELECT \* FROM "invitations" WHERE token_hash = \$1 AND expires_at > \$2 ORDER BY "invitations"."id" LIMIT \$3 FOR UPDATE`
+		userQuery   = `SELECT "id","email" FROM "users" WHERE id = \$1`
+		deleteQuery = `DELETE FROM "invitations" WHERE id = \$1`
+		insertQuery = `INSERT INTO "memberships" \("workspace_id","user_id","role","created_at"\) VALUES \(\$1,\$2,\$3,\$4\) ON CONFLICT DO NOTHING`
+	)
+	invitation := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "workspace_id", "email", "role", "token_hash", "invited_by_id", "expires_at", "created_at"}).
+			AddRow(1, 3, "john@example.com", "viewer", hashToken("token"), 2, tn.Add(time.Hour), tn)
+	}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		want    enum.Role
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).
+						WithArgs(hashToken("token"), sqlmock.AnyArg(), 1).
+						WillReturnRows(invitation())
+					mock.ExpectQuery(userQuery).
+						WithArgs(7, 1).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "john@example.com"))
+					mock.ExpectExec(deleteQuery).
+						WithArgs(1).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(insertQuery).
+						WithArgs(3, 7, "viewer", sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			want:    enum.RoleViewer,
+			wantErr: nil,
+		},
+		{
+			name: "unknown or expired token",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrInvitationNotFound,
+		},
+		{
+			name: "invitation for another email",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).WillReturnRows(invitation())
+					mock.ExpectQuery(userQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "jane@example.com"))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrInvitationNotFound,
+		},
+		{
+			name: "already a member",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(findQuery).WillReturnRows(invitation())
+					mock.ExpectQuery(userQuery).
+						WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "john@example.com"))
+					mock.ExpectExec(deleteQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectExec(insertQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrAlreadyMember,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			got, err := s.AcceptInvitation(7, request.AcceptedInvitationRequest{Token: "token"})
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("workspaceService.AcceptInvitation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.Role != tt.want {
+				t.Errorf("workspaceService.AcceptInvitation() role = %v, want %v", got.Role, tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_RemoveMember(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const (
+		lockQuery   = `SELECT \* FROM "memberships" WHERE workspace_id = \$1 FOR UPDATE`
+		deleteQuery = `DELETE FROM "memberships" WHERE workspace_id = \$1 AND user_id = \$2`
+	)
+	members := func(roles ...enum.Role) *sqlmock.Rows {
+		rows := sqlmock.NewRows([]string{"workspace_id", "user_id", "role", "created_at"})
+		for i, role := range roles {
+			rows.AddRow(3, i+1, role, tn)
+		}
+		return rows
+	}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	type args struct {
+		role   enum.Role
+		userID int
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "success",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).
+						WithArgs(3).
+						WillReturnRows(members(enum.RoleOwner, enum.RoleAdmin, enum.RoleMember))
+					mock.ExpectExec(deleteQuery).
+						WithArgs(3, 3).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			args: args{
+				role:   enum.RoleAdmin,
+				userID: 3,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "not a member",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).WillReturnRows(members(enum.RoleOwner))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				role:   enum.RoleOwner,
+				userID: 9,
+			},
+			wantErr: ErrMemberNotFound,
+		},
+		{
+			name: "member ranks above the caller",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).WillReturnRows(members(enum.RoleOwner, enum.RoleAdmin))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				role:   enum.RoleAdmin,
+				userID: 1,
+			},
+			wantErr: ErrRoleTooHigh,
+		},
+		{
+			name: "last owner",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).WillReturnRows(members(enum.RoleOwner, enum.RoleAdmin))
+					mock.ExpectRollback()
+				},
+			},
+			args: args{
+				role:   enum.RoleOwner,
+				userID: 1,
+			},
+			wantErr: ErrLastOwner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.RemoveMember(3, tt.args.role, tt.args.userID)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("workspaceService.RemoveMember() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func Test_workspaceService_LeaveWorkspace(t *testing.T) {
+	tn := time.Now()
+
+	sqlDB, db, mock := DbMock(t)
+	defer sqlDB.Close()
+
+	const lockQuery = `SELECT \* FROM "memberships" WHERE workspace_id = \$1 FOR UPDATE`
+	columns := []string{"workspace_id", "user_id", "role", "created_at"}
+
+	type fields struct {
+		repository         base.BaseRepository[any]
+		repositoryBehavior func()
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr error
+	}{
+		{
+			name: "one of two owners",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 7, "owner", tn).AddRow(3, 8, "owner", tn))
+					mock.ExpectExec(`DELETE FROM "memberships" WHERE workspace_id = \$1 AND user_id = \$2`).
+						WithArgs(3, 7).
+						WillReturnResult(sqlmock.NewResult(0, 1))
+					mock.ExpectCommit()
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "last owner",
+			fields: fields{
+				repository: base.NewBaseRepository[any](db),
+				repositoryBehavior: func() {
+					mock.ExpectBegin()
+					mock.ExpectQuery(lockQuery).
+						WillReturnRows(sqlmock.NewRows(columns).AddRow(3, 7, "owner", tn).AddRow(3, 8, "admin", tn))
+					mock.ExpectRollback()
+				},
+			},
+			wantErr: ErrLastOwner,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fields.repositoryBehavior()
+			s := workspaceService{
+				repository: tt.fields.repository,
+				log:        logger.WithPrefix("test"),
+			}
+
+			err := s.LeaveWorkspace(3, 7)
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("workspaceService.LeaveWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
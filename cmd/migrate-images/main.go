@@ -0,0 +1,46 @@
+// Command migrate-images moves the base64 images left in the tasks.image
+// column into blob storage and drops the column afterwards. It is safe to
+// run again after a partial failure.
+package main
+
+import (
+	"todo/api/services"
+	"todo/pkg/base"
+	"todo/pkg/config"
+	"todo/pkg/database"
+	"todo/pkg/imaging"
+	"todo/pkg/logger"
+	"todo/pkg/storage"
+)
+
+func main() {
+	err := config.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = database.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = storage.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = imaging.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	log := logger.WithPrefix("cmd/migrate-images")
+	imageService := services.NewImageService(base.NewBaseRepository[any](database.GetDatabase()), storage.GetStore(), imaging.GetProcessor(), nil)
+	migrated, err := imageService.MigrateLegacyImages()
+	if err != nil {
+		log.Wrap("migrated %d images before failing: %v", migrated, err).Error()
+		panic(err)
+	}
+
+	log.Wrap("migrated %d images", migrated).Info()
+}
@@ -1,11 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"time"
+	"todo/api/jobs"
+	"todo/api/middleware"
 	"todo/api/routes"
+	"todo/api/services"
+	"todo/pkg/auth"
+	"todo/pkg/base"
+	"todo/pkg/cache"
 	"todo/pkg/config"
 	"todo/pkg/database"
+	"todo/pkg/imaging"
+	"todo/pkg/pubsub"
+	"todo/pkg/ratelimit"
+	"todo/pkg/storage"
+	"todo/pkg/workflow"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -22,9 +35,60 @@ func main() {
 		panic(err)
 	}
 
+	err = cache.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = pubsub.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = auth.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = ratelimit.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = storage.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = imaging.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	err = workflow.Init()
+	if err != nil {
+		panic(err)
+	}
+
+	trash := config.GetConfig().Trash
+	if trash.RetentionDays > 0 && trash.SweepInterval > 0 {
+		taskService := services.NewTaskService(base.NewBaseRepository[any](database.GetDatabase()), storage.GetStore(), workflow.GetWorkflow(), nil)
+		retention := time.Duration(trash.RetentionDays) * 24 * time.Hour
+		go jobs.NewTrashSweeper(taskService, retention, trash.SweepInterval).Run(context.Background())
+	}
+
+	idempotency := config.GetConfig().Idempotency
+	if idempotency.SweepInterval > 0 {
+		idempotencyService := services.NewIdempotencyService(base.NewBaseRepository[any](database.GetDatabase()), idempotency.TTL)
+		go jobs.NewIdempotencySweeper(idempotencyService, idempotency.SweepInterval).Run(context.Background())
+	}
+
 	app := fiber.New(fiber.Config{
 		JSONEncoder: json.Marshal,
 		JSONDecoder: json.Unmarshal,
+		BodyLimit:   bodyLimit(),
+		// lets list filters such as priority=HIGH,URGENT bind to slices
+		EnableSplittingOnParsers: true,
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
 			code := fiber.StatusInternalServerError
 
@@ -37,7 +101,26 @@ func main() {
 		},
 	})
 	app.Use(cors.New())
+	if config.GetConfig().RateLimit.Enabled {
+		apiKeyService := services.NewAPIKeyService(base.NewBaseRepository[any](database.GetDatabase()))
+		app.Use(middleware.RateLimit(ratelimit.GetLimiter(), ratelimit.GetPolicy(), auth.GetTokens(), apiKeyService))
+	}
 
 	routes.NewRoutes(app)
 	app.Listen(":8080")
 }
+
+// bodyLimit fits the largest upload, an image or a task's attachments, with
+// room left for the multipart framing around it.
+func bodyLimit() int {
+	limit := imaging.GetProcessor().Limits().MaxBytes
+	attachments := config.GetConfig().Attachment.MaxTotalBytes
+	if attachments <= 0 {
+		attachments = services.DefaultAttachmentBytesPerTask
+	}
+	if attachments > limit {
+		limit = attachments
+	}
+
+	return int(limit) + 1<<20
+}
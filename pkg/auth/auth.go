@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"todo/pkg/config"
+)
+
+var (
+	ErrInvalidToken = errors.New("token is invalid")
+	ErrExpiredToken = errors.New("token has expired")
+)
+
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// AccessAudience marks access tokens. Other tokens signed with the secret,
+// such as share links, carry their own audience and are refused by Verify.
+const AccessAudience = "access"
+
+// Claims are the registered claims of an access token. Subject is the user
+// ID.
+type Claims struct {
+	Audience  string `json:"aud"`
+	Subject   string `json:"sub"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Tokens issues and verifies the short-lived access tokens that
+// authenticate API requests.
+type Tokens interface {
+	Issue(userID int) (string, time.Time, error)
+	Verify(token string) (int, error)
+	RefreshTTL() time.Duration
+}
+
+type tokens struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+var t Tokens
+
+func New(secret []byte, accessTTL time.Duration, refreshTTL time.Duration) Tokens {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTTL
+	}
+
+	return &tokens{
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+func Init() error {
+	cfg := config.GetConfig().Auth
+	if len(cfg.Secret) == 0 {
+		return errors.New("auth: secret is required")
+	}
+
+	t = New([]byte(cfg.Secret), cfg.AccessTTL, cfg.RefreshTTL)
+	return nil
+}
+
+func GetTokens() Tokens {
+	return t
+}
+
+func (t tokens) Issue(userID int) (string, time.Time, error) {
+	now := t.now()
+	expiresAt := now.Add(t.accessTTL)
+
+	token, err := Sign(Claims{
+		Audience:  AccessAudience,
+		Subject:   strconv.Itoa(userID),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, t.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+func (t tokens) Verify(token string) (int, error) {
+	var claims Claims
+	if err := Parse(token, t.secret, &claims); err != nil {
+		return 0, err
+	}
+	if claims.Audience != AccessAudience {
+		return 0, ErrInvalidToken
+	}
+
+	if t.now().Unix() >= claims.ExpiresAt {
+		return 0, ErrExpiredToken
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil || userID <= 0 {
+		return 0, ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+func (t tokens) RefreshTTL() time.Duration {
+	return t.refreshTTL
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./auth.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockTokens is a mock of Tokens interface.
+type MockTokens struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokensMockRecorder
+}
+
+// MockTokensMockRecorder is the mock recorder for MockTokens.
+type MockTokensMockRecorder struct {
+	mock *MockTokens
+}
+
+// NewMockTokens creates a new mock instance.
+func NewMockTokens(ctrl *gomock.Controller) *MockTokens {
+	mock := &MockTokens{ctrl: ctrl}
+	mock.recorder = &MockTokensMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokens) EXPECT() *MockTokensMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MockTokens) Issue(userID int) (string, time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", userID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(time.Time)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockTokensMockRecorder) Issue(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokens)(nil).Issue), userID)
+}
+
+// RefreshTTL mocks base method.
+func (m *MockTokens) RefreshTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// RefreshTTL indicates an expected call of RefreshTTL.
+func (mr *MockTokensMockRecorder) RefreshTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTTL", reflect.TypeOf((*MockTokens)(nil).RefreshTTL))
+}
+
+// Verify mocks base method.
+func (m *MockTokens) Verify(token string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", token)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTokensMockRecorder) Verify(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokens)(nil).Verify), token)
+}
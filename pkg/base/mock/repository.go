@@ -130,6 +130,25 @@ func (mr *MockBaseRepositoryMockRecorder[T]) Error() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockBaseRepository[T])(nil).Error))
 }
 
+// Exec mocks base method.
+func (m *MockBaseRepository[T]) Exec(sql string, values ...interface{}) base.BaseRepository[T] {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{sql}
+	for _, a := range values {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Exec", varargs...)
+	ret0, _ := ret[0].(base.BaseRepository[T])
+	return ret0
+}
+
+// Exec indicates an expected call of Exec.
+func (mr *MockBaseRepositoryMockRecorder[T]) Exec(sql interface{}, values ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{sql}, values...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exec", reflect.TypeOf((*MockBaseRepository[T])(nil).Exec), varargs...)
+}
+
 // Find mocks base method.
 func (m *MockBaseRepository[T]) Find(dest interface{}, conds ...interface{}) base.BaseRepository[T] {
 	m.ctrl.T.Helper()
@@ -286,6 +305,20 @@ func (mr *MockBaseRepositoryMockRecorder[T]) Model(value interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Model", reflect.TypeOf((*MockBaseRepository[T])(nil).Model), value)
 }
 
+// Offset mocks base method.
+func (m *MockBaseRepository[T]) Offset(offset int) base.BaseRepository[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Offset", offset)
+	ret0, _ := ret[0].(base.BaseRepository[T])
+	return ret0
+}
+
+// Offset indicates an expected call of Offset.
+func (mr *MockBaseRepositoryMockRecorder[T]) Offset(offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Offset", reflect.TypeOf((*MockBaseRepository[T])(nil).Offset), offset)
+}
+
 // Omit mocks base method.
 func (m *MockBaseRepository[T]) Omit(column ...string) base.BaseRepository[T] {
 	m.ctrl.T.Helper()
@@ -337,6 +370,25 @@ func (mr *MockBaseRepositoryMockRecorder[T]) Preload(query interface{}, args ...
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preload", reflect.TypeOf((*MockBaseRepository[T])(nil).Preload), varargs...)
 }
 
+// Raw mocks base method.
+func (m *MockBaseRepository[T]) Raw(sql string, values ...interface{}) base.BaseRepository[T] {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{sql}
+	for _, a := range values {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Raw", varargs...)
+	ret0, _ := ret[0].(base.BaseRepository[T])
+	return ret0
+}
+
+// Raw indicates an expected call of Raw.
+func (mr *MockBaseRepositoryMockRecorder[T]) Raw(sql interface{}, values ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{sql}, values...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Raw", reflect.TypeOf((*MockBaseRepository[T])(nil).Raw), varargs...)
+}
+
 // RowsAffected mocks base method.
 func (m *MockBaseRepository[T]) RowsAffected() int64 {
 	m.ctrl.T.Helper()
@@ -487,6 +539,20 @@ func (mr *MockBaseRepositoryMockRecorder[T]) Transaction(fc interface{}, opts ..
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockBaseRepository[T])(nil).Transaction), varargs...)
 }
 
+// Unscoped mocks base method.
+func (m *MockBaseRepository[T]) Unscoped() base.BaseRepository[T] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(base.BaseRepository[T])
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockBaseRepositoryMockRecorder[T]) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockBaseRepository[T])(nil).Unscoped))
+}
+
 // Update mocks base method.
 func (m *MockBaseRepository[T]) Update(column string, value interface{}) base.BaseRepository[T] {
 	m.ctrl.T.Helper()
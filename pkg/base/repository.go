@@ -26,14 +26,18 @@ type BaseRepository[T any] interface {
 	Having(query interface{}, args ...interface{}) BaseRepository[T]
 	Order(value interface{}) BaseRepository[T]
 	Limit(limit int) BaseRepository[T]
+	Offset(offset int) BaseRepository[T]
 	Count(count *int64) BaseRepository[T]
 	Scan(dest interface{}) BaseRepository[T]
+	Raw(sql string, values ...interface{}) BaseRepository[T]
+	Exec(sql string, values ...interface{}) BaseRepository[T]
 
 	Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error
 
 	Omit(column ...string) BaseRepository[T]
 	Model(value interface{}) BaseRepository[T]
 	Preload(query string, args ...interface{}) BaseRepository[T]
+	Unscoped() BaseRepository[T]
 
 	Session(config *gorm.Session) BaseRepository[T]
 
@@ -132,6 +136,10 @@ func (b baseRepository[T]) Limit(limit int) BaseRepository[T] {
 	return Wrap[T](b.db.Limit(limit))
 }
 
+func (b baseRepository[T]) Offset(offset int) BaseRepository[T] {
+	return Wrap[T](b.db.Offset(offset))
+}
+
 func (b baseRepository[T]) Count(count *int64) BaseRepository[T] {
 	return Wrap[T](b.db.Count(count))
 }
@@ -140,6 +148,14 @@ func (b baseRepository[T]) Scan(dest interface{}) BaseRepository[T] {
 	return Wrap[T](b.db.Scan(dest))
 }
 
+func (b baseRepository[T]) Raw(sql string, values ...interface{}) BaseRepository[T] {
+	return Wrap[T](b.db.Raw(sql, values...))
+}
+
+func (b baseRepository[T]) Exec(sql string, values ...interface{}) BaseRepository[T] {
+	return Wrap[T](b.db.Exec(sql, values...))
+}
+
 func (b baseRepository[T]) Transaction(fc func(tx *gorm.DB) error, opts ...*sql.TxOptions) error {
 	return b.db.Transaction(fc, opts...)
 }
@@ -156,6 +172,10 @@ func (b baseRepository[T]) Preload(query string, args ...interface{}) BaseReposi
 	return Wrap[T](b.db.Preload(query, args...))
 }
 
+func (b baseRepository[T]) Unscoped() BaseRepository[T] {
+	return Wrap[T](b.db.Unscoped())
+}
+
 func (b baseRepository[T]) Session(config *gorm.Session) BaseRepository[T] {
 	return Wrap[T](b.db.Session(config))
 }
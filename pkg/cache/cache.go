@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+	"todo/pkg/config"
+	"todo/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrMiss        = errors.New("cache: key not found")
+	ErrUnavailable = errors.New("cache: redis is unavailable")
+)
+
+const DefaultTimeout = 250 * time.Millisecond
+
+// Cache keeps data that can be rebuilt from the database at any time.
+// Callers treat every error like a miss and fall back to the database.
+type Cache interface {
+	// Get returns ErrMiss when key doesn't exist.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// MGet returns the values of keys in order, nil for the missing ones.
+	MGet(ctx context.Context, keys ...string) ([][]byte, error)
+	// Set stores value under key, for good when ttl is 0.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value unless key exists and reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+var (
+	client *redis.Client
+	c      Cache
+)
+
+// Init connects to Redis when it is configured. Without it the cache stays
+// nil and callers go to the database directly.
+func Init() error {
+	cfg := config.GetConfig().Redis
+	if len(cfg.Host) == 0 {
+		return nil
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 6379
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	client = redis.NewClient(&redis.Options{
+		Addr:         net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		Username:     cfg.Username,
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  timeout,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+	})
+	c = NewRedisCache(client)
+
+	// Redis being down is no reason not to start, requests go to the
+	// database until it is back
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		logger.WithPrefix("cache").Wrap("redis at %s is unavailable: %v", client.Options().Addr, err).Warn()
+	}
+
+	return nil
+}
+
+// GetClient returns the Redis client, nil unless Redis is configured.
+func GetClient() *redis.Client {
+	return client
+}
+
+// GetCache returns the cache, nil unless Redis is configured.
+func GetCache() Cache {
+	return c
+}
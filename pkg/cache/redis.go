@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retryAfter is how long Redis is left alone after it failed, so an outage
+// costs one timeout every few seconds rather than one per request.
+const retryAfter = 5 * time.Second
+
+type redisCache struct {
+	client    redis.Cmdable
+	downUntil atomic.Int64
+	now       func() time.Time
+}
+
+func NewRedisCache(client redis.Cmdable) Cache {
+	return &redisCache{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+func (c *redisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := c.do(func() error {
+		var err error
+		value, err = c.client.Get(ctx, key).Bytes()
+		return err
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+
+	return value, err
+}
+
+func (c *redisCache) MGet(ctx context.Context, keys ...string) ([][]byte, error) {
+	var values []interface{}
+	err := c.do(func() error {
+		var err error
+		values, err = c.client.MGet(ctx, keys...).Result()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([][]byte, len(values))
+	for i, value := range values {
+		if s, ok := value.(string); ok {
+			result[i] = []byte(s)
+		}
+	}
+
+	return result, nil
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.do(func() error {
+		return c.client.Set(ctx, key, value, ttl).Err()
+	})
+}
+
+func (c *redisCache) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	var ok bool
+	err := c.do(func() error {
+		var err error
+		ok, err = c.client.SetNX(ctx, key, value, ttl).Result()
+		return err
+	})
+
+	return ok, err
+}
+
+// do runs fn unless Redis failed recently. Only failures to reach Redis
+// count; a miss or an error reply means it is up.
+func (c *redisCache) do(fn func() error) error {
+	if c.now().UnixNano() < c.downUntil.Load() {
+		return ErrUnavailable
+	}
+
+	err := fn()
+	var reply redis.Error
+	if err != nil && !errors.Is(err, redis.Nil) && !errors.As(err, &reply) {
+		c.downUntil.Store(c.now().Add(retryAfter).UnixNano())
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestCache(t *testing.T) (*miniredis.Miniredis, *redisCache) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	return server, NewRedisCache(client).(*redisCache)
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestCache(t)
+
+	if _, err := c.Get(ctx, "foo"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get() of a missing key error = %v, want %v", err, ErrMiss)
+	}
+
+	if err := c.Set(ctx, "foo", []byte("bar"), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	got, err := c.Get(ctx, "foo")
+	if err != nil || string(got) != "bar" {
+		t.Fatalf("Get() = %q, %v, want bar", got, err)
+	}
+
+	values, err := c.MGet(ctx, "foo", "baz")
+	if err != nil || !reflect.DeepEqual(values, [][]byte{[]byte("bar"), nil}) {
+		t.Fatalf("MGet() = %q, %v", values, err)
+	}
+
+	ok, err := c.SetNX(ctx, "foo", []byte("qux"), 0)
+	if err != nil || ok {
+		t.Fatalf("SetNX() of an existing key = %v, %v, want false", ok, err)
+	}
+	ok, err = c.SetNX(ctx, "baz", []byte("qux"), 0)
+	if err != nil || !ok {
+		t.Fatalf("SetNX() of a missing key = %v, %v, want true", ok, err)
+	}
+
+	server.FastForward(time.Minute)
+	if _, err := c.Get(ctx, "foo"); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get() of an expired key error = %v, want %v", err, ErrMiss)
+	}
+	if _, err := c.Get(ctx, "baz"); err != nil {
+		t.Fatalf("Get() of a key without ttl error = %v", err)
+	}
+}
+
+func TestRedisCache_outage(t *testing.T) {
+	ctx := context.Background()
+	server, c := newTestCache(t)
+
+	tn := time.Now()
+	c.now = func() time.Time { return tn }
+
+	// an error reply means redis is up
+	server.SetError("READONLY")
+	if err := c.Set(ctx, "foo", []byte("bar"), 0); err == nil || errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Set() error = %v, want the reply", err)
+	}
+	server.SetError("")
+	if err := c.Set(ctx, "foo", []byte("bar"), 0); err != nil {
+		t.Fatalf("Set() after an error reply error = %v", err)
+	}
+
+	addr := server.Addr()
+	server.Close()
+	if _, err := c.Get(ctx, "foo"); err == nil || errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Get() while down error = %v, want a connection error", err)
+	}
+
+	// redis is back, but isn't tried again right away
+	if err := server.StartAddr(addr); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "foo"); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("Get() after an outage error = %v, want %v", err, ErrUnavailable)
+	}
+
+	tn = tn.Add(retryAfter)
+	if got, err := c.Get(ctx, "foo"); err != nil || string(got) != "bar" {
+		t.Fatalf("Get() once retried = %q, %v, want bar", got, err)
+	}
+}
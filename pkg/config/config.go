@@ -1,13 +1,24 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Database database `mapstructure:"database"`
-	Redis    redis    `mapstructure:"redis"`
-	Auth     auth     `mapstructure:"auth"`
+	Database    database    `mapstructure:"database"`
+	Redis       redis       `mapstructure:"redis"`
+	Auth        auth        `mapstructure:"auth"`
+	Trash       trash       `mapstructure:"trash"`
+	Workflow    workflow    `mapstructure:"workflow"`
+	Storage     storage     `mapstructure:"storage"`
+	Image       image       `mapstructure:"image"`
+	Attachment  attachment  `mapstructure:"attachment"`
+	Cache       cache       `mapstructure:"cache"`
+	RateLimit   rateLimit   `mapstructure:"rate_limit"`
+	Idempotency idempotency `mapstructure:"idempotency"`
+	Events      events      `mapstructure:"events"`
 }
 
 type database struct {
@@ -19,14 +30,90 @@ type database struct {
 }
 
 type redis struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
+	Host     string        `mapstructure:"host"`
+	Port     int           `mapstructure:"port"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	DB       int           `mapstructure:"db"`
+	Timeout  time.Duration `mapstructure:"timeout"`
 }
 
 type auth struct {
-	Secret string `mapstructure:"secret"`
+	Secret     string        `mapstructure:"secret"`
+	AccessTTL  time.Duration `mapstructure:"access_ttl"`
+	RefreshTTL time.Duration `mapstructure:"refresh_ttl"`
+}
+
+type trash struct {
+	RetentionDays int           `mapstructure:"retention_days"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+type workflow struct {
+	Terminal    []string     `mapstructure:"terminal"`
+	Transitions []transition `mapstructure:"transitions"`
+}
+
+type transition struct {
+	From string   `mapstructure:"from"`
+	To   []string `mapstructure:"to"`
+}
+
+type storage struct {
+	Driver string       `mapstructure:"driver"`
+	Local  localStorage `mapstructure:"local"`
+	S3     s3Storage    `mapstructure:"s3"`
+}
+
+type localStorage struct {
+	Root string `mapstructure:"root"`
+}
+
+type s3Storage struct {
+	Endpoint  string `mapstructure:"endpoint"`
+	Region    string `mapstructure:"region"`
+	Bucket    string `mapstructure:"bucket"`
+	AccessKey string `mapstructure:"access_key"`
+	SecretKey string `mapstructure:"secret_key"`
+	PathStyle bool   `mapstructure:"path_style"`
+}
+
+type image struct {
+	MaxBytes      int64 `mapstructure:"max_bytes"`
+	MaxWidth      int   `mapstructure:"max_width"`
+	MaxHeight     int   `mapstructure:"max_height"`
+	ThumbnailSize int   `mapstructure:"thumbnail_size"`
+}
+
+type attachment struct {
+	MaxPerTask    int   `mapstructure:"max_per_task"`
+	MaxTotalBytes int64 `mapstructure:"max_total_bytes"`
+}
+
+type cache struct {
+	TaskListTTL time.Duration `mapstructure:"task_list_ttl"`
+}
+
+type rateLimit struct {
+	Enabled bool            `mapstructure:"enabled"`
+	Default rateLimitRule   `mapstructure:"default"`
+	Rules   []rateLimitRule `mapstructure:"rules"`
+}
+
+type rateLimitRule struct {
+	Method string        `mapstructure:"method"`
+	Path   string        `mapstructure:"path"`
+	Limit  int           `mapstructure:"limit"`
+	Window time.Duration `mapstructure:"window"`
+}
+
+type idempotency struct {
+	TTL           time.Duration `mapstructure:"ttl"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+}
+
+type events struct {
+	History int `mapstructure:"history"`
 }
 
 var config Config
@@ -40,10 +127,13 @@ func Init() error {
 		return err
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	// decoded afresh so keys missing from the file don't keep old values
+	var cfg Config
+	if err := viper.Unmarshal(&cfg); err != nil {
 		return err
 	}
 
+	config = cfg
 	return nil
 }
 
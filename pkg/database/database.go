@@ -1,6 +1,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"todo/api/entities"
 	"todo/pkg/config"
@@ -11,6 +12,9 @@ import (
 
 var db *gorm.DB
 
+// models are migrated in order, referenced tables first.
+var models = []interface{}{&entities.User{}, &entities.RefreshToken{}, &entities.APIKey{}, &entities.Workspace{}, &entities.Membership{}, &entities.Invitation{}, &entities.Project{}, &entities.Task{}, &entities.TaskDependency{}, &entities.Tag{}, &entities.Comment{}, &entities.Attachment{}, &entities.ShareLink{}, &entities.IdempotencyKey{}}
+
 func Init() error {
 	config := config.GetConfig()
 	psqlConn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable TimeZone=Asia/Bangkok", config.Database.Host, config.Database.Username, config.Database.Password, config.Database.DatabaseName, config.Database.Port)
@@ -21,7 +25,35 @@ func Init() error {
 		return err
 	}
 
-	db.AutoMigrate(&entities.Task{})
+	db.AutoMigrate(models...)
+
+	// tag names used to be unique across workspaces
+	if db.Migrator().HasIndex(&entities.Tag{}, "idx_tags_name") {
+		err = db.Migrator().DropIndex(&entities.Tag{}, "idx_tags_name")
+		if err != nil {
+			return err
+		}
+	}
+
+	err = migratePolicies(db)
+	if err != nil {
+		return err
+	}
+
+	return checkRole(db)
+}
+
+// checkRole refuses roles the policies don't apply to, which would let
+// every request see every workspace.
+func checkRole(db *gorm.DB) error {
+	var bypass bool
+	err := db.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass).Error
+	if err != nil {
+		return err
+	}
+	if bypass {
+		return errors.New("database role bypasses row-level security, connect as one that is neither superuser nor BYPASSRLS")
+	}
 
 	return nil
 }
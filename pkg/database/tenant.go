@@ -0,0 +1,71 @@
+package database
+
+import (
+	"strconv"
+	"todo/pkg/base"
+
+	"gorm.io/gorm"
+)
+
+// WorkspaceSetting is the session variable the row-level security policies
+// read the current workspace from.
+const WorkspaceSetting = "app.workspace_id"
+
+// AllWorkspacesSetting lifts the policies for migrations and background
+// jobs, which act on every workspace.
+const AllWorkspacesSetting = "app.all_workspaces"
+
+// workspaceCheck passes rows of the workspace in WorkspaceSetting. It fails
+// closed: sessions that set neither setting see no rows at all.
+const workspaceCheck = `workspace_id = nullif(current_setting('` + WorkspaceSetting + `', true), '')::int
+	OR current_setting('` + AllWorkspacesSetting + `', true) = 'on'`
+
+// policies keep tasks of other workspaces out of reach even for queries
+// that forget to filter on the workspace. FORCE applies them to the table
+// owner too; superusers and BYPASSRLS roles still skip them, so the app
+// must not connect as one.
+var policies = []string{
+	`ALTER TABLE tasks ENABLE ROW LEVEL SECURITY`,
+	`ALTER TABLE tasks FORCE ROW LEVEL SECURITY`,
+	`DROP POLICY IF EXISTS tasks_workspace ON tasks`,
+	`CREATE POLICY tasks_workspace ON tasks USING (` + workspaceCheck + `) WITH CHECK (` + workspaceCheck + `)`,
+}
+
+// InWorkspace runs fc in a transaction that can only read and write tasks
+// of the workspace, whatever its queries filter on.
+func InWorkspace(repository base.BaseRepository[any], workspaceID int, fc func(repository base.BaseRepository[any]) error) error {
+	return withSetting(repository, WorkspaceSetting, strconv.Itoa(workspaceID), fc)
+}
+
+// AllWorkspaces runs fc in a transaction that reads and writes the tasks of
+// every workspace. It is meant for jobs and migrations, never for requests.
+func AllWorkspaces(repository base.BaseRepository[any], fc func(repository base.BaseRepository[any]) error) error {
+	return withSetting(repository, AllWorkspacesSetting, "on", fc)
+}
+
+func withSetting(repository base.BaseRepository[any], name string, value string, fc func(repository base.BaseRepository[any]) error) error {
+	return repository.Transaction(func(tx *gorm.DB) error {
+		repository := base.Wrap[any](tx)
+
+		// is_local scopes the setting to the transaction, so it never
+		// leaks to the next user of the pooled connection
+		err := repository.Exec("SELECT set_config(?, ?, true)", name, value).Error()
+		if err != nil {
+			return err
+		}
+
+		return fc(repository)
+	})
+}
+
+func migratePolicies(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, policy := range policies {
+			err := tx.Exec(policy).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,280 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+	"todo/api/entities"
+	"todo/api/enum"
+	"todo/pkg/base"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testDSN names a Postgres database for the row-level security suite, which
+// is skipped without it. Everything it creates is rolled back.
+const testDSN = "TODO_TEST_DATABASE_DSN"
+
+func TestInWorkspace(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		behavior func()
+		fc       func(repository base.BaseRepository[any]) error
+		wantErr  bool
+	}{
+		{
+			name: "setting applies to the queries of fc",
+			behavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+					WithArgs(WorkspaceSetting, "3").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery(`SELECT \* FROM "tasks"`).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+			},
+			fc: func(repository base.BaseRepository[any]) error {
+				var tasks []entities.Task
+				return repository.Find(&tasks).Error()
+			},
+			wantErr: false,
+		},
+		{
+			name: "fc failed",
+			behavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT set_config`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			fc: func(repository base.BaseRepository[any]) error {
+				return errors.New("foo")
+			},
+			wantErr: true,
+		},
+		{
+			name: "set config failed",
+			behavior: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec(`SELECT set_config`).WillReturnError(errors.New("foo"))
+				mock.ExpectRollback()
+			},
+			fc: func(repository base.BaseRepository[any]) error {
+				t.Error("fc ran without the setting")
+				return nil
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.behavior()
+
+			err := InWorkspace(base.NewBaseRepository[any](db), 3, tt.fc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InWorkspace() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestAllWorkspaces(t *testing.T) {
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sqlDB.Close()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\(\$1, \$2, true\)`).
+		WithArgs(AllWorkspacesSetting, "on").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM "tasks"`).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	err = AllWorkspaces(base.NewBaseRepository[any](db), func(repository base.BaseRepository[any]) error {
+		return repository.Unscoped().Where("deleted_at IS NOT NULL").Delete(&entities.Task{}).Error()
+	})
+	if err != nil {
+		t.Errorf("AllWorkspaces() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestRowLevelSecurity proves the policies hold for queries that don't
+// filter on the workspace at all.
+func TestRowLevelSecurity(t *testing.T) {
+	dsn := os.Getenv(testDSN)
+	if len(dsn) == 0 {
+		t.Skipf("%s is not set", testDSN)
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	defer tx.Rollback()
+
+	// a schema of its own keeps the suite away from existing tables
+	mustExec(t, tx, "CREATE SCHEMA todo_rls_test")
+	mustExec(t, tx, "SET LOCAL search_path TO todo_rls_test")
+	err = tx.AutoMigrate(models...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migratePolicies(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tn := time.Now()
+	workspaces := []entities.Workspace{{Name: "a", CreatedAt: tn, UpdatedAt: tn}, {Name: "b", CreatedAt: tn, UpdatedAt: tn}}
+	err = tx.Create(&workspaces).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := workspaces[0].ID, workspaces[1].ID
+	// the policies apply to the table owner too
+	mustExec(t, tx, "SET LOCAL "+AllWorkspacesSetting+" = on")
+	tasks := []entities.Task{
+		{WorkspaceID: &a, Title: "a1", Status: enum.TaskStatusTodo, Priority: enum.TaskPriorityMedium, Version: 1, CreatedAt: tn, UpdatedAt: tn},
+		{WorkspaceID: &a, Title: "a2", Status: enum.TaskStatusTodo, Priority: enum.TaskPriorityMedium, Version: 1, CreatedAt: tn, UpdatedAt: tn},
+		{WorkspaceID: &b, Title: "b1", Status: enum.TaskStatusTodo, Priority: enum.TaskPriorityMedium, Version: 1, CreatedAt: tn, UpdatedAt: tn},
+	}
+	err = tx.Create(&tasks).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := tasks[2].ID
+	mustExec(t, tx, "SET LOCAL "+AllWorkspacesSetting+" = off")
+
+	// superusers and BYPASSRLS roles skip the policies, so the suite runs
+	// as a plain role then
+	var bypass bool
+	err = tx.Raw("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bypass {
+		mustExec(t, tx, "CREATE ROLE todo_rls_test NOLOGIN")
+		mustExec(t, tx, "GRANT USAGE ON SCHEMA todo_rls_test TO todo_rls_test")
+		mustExec(t, tx, "GRANT ALL ON ALL TABLES IN SCHEMA todo_rls_test TO todo_rls_test")
+		mustExec(t, tx, "GRANT ALL ON ALL SEQUENCES IN SCHEMA todo_rls_test TO todo_rls_test")
+		mustExec(t, tx, "SET LOCAL ROLE todo_rls_test")
+	}
+	repository := base.Wrap[any](tx)
+
+	t.Run("session without a workspace sees no tasks", func(t *testing.T) {
+		var got []entities.Task
+		err := repository.Find(&got).Error()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("tasks = %v, want none", got)
+		}
+	})
+
+	t.Run("query without a workspace filter only sees the workspace", func(t *testing.T) {
+		var got []entities.Task
+		err := InWorkspace(repository, a, func(repository base.BaseRepository[any]) error {
+			return repository.Order("id").Find(&got).Error()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 2 || got[0].ID != tasks[0].ID || got[1].ID != tasks[1].ID {
+			t.Errorf("tasks = %v, want a1 and a2", got)
+		}
+	})
+
+	t.Run("task of another workspace can't be looked up", func(t *testing.T) {
+		err := InWorkspace(repository, a, func(repository base.BaseRepository[any]) error {
+			var task entities.Task
+			return repository.Where("id = ?", other).First(&task).Error()
+		})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Errorf("error = %v, want %v", err, gorm.ErrRecordNotFound)
+		}
+	})
+
+	t.Run("task of another workspace can't be updated", func(t *testing.T) {
+		var affected int64
+		err := InWorkspace(repository, a, func(repository base.BaseRepository[any]) error {
+			result := repository.Model(&entities.Task{}).Where("id = ?", other).Update("title", "moved")
+			affected = result.RowsAffected()
+			return result.Error()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if affected != 0 {
+			t.Errorf("rows affected = %d, want 0", affected)
+		}
+	})
+
+	t.Run("task can't be created in another workspace", func(t *testing.T) {
+		err := InWorkspace(repository, a, func(repository base.BaseRepository[any]) error {
+			return repository.Create(&entities.Task{WorkspaceID: &b, Title: "b2", Status: enum.TaskStatusTodo, Priority: enum.TaskPriorityMedium, Version: 1, CreatedAt: tn, UpdatedAt: tn}).Error()
+		})
+		if err == nil {
+			t.Error("task was created in another workspace")
+		}
+	})
+
+	t.Run("task can't be moved to another workspace", func(t *testing.T) {
+		err := InWorkspace(repository, a, func(repository base.BaseRepository[any]) error {
+			return repository.Model(&entities.Task{}).Where("id = ?", tasks[0].ID).Update("workspace_id", b).Error()
+		})
+		if err == nil {
+			t.Error("task was moved to another workspace")
+		}
+	})
+
+	// last, since the setting outlives the savepoint of AllWorkspaces
+	t.Run("all workspaces sees every task", func(t *testing.T) {
+		var got []entities.Task
+		err := AllWorkspaces(repository, func(repository base.BaseRepository[any]) error {
+			return repository.Find(&got).Error()
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(tasks) {
+			t.Errorf("tasks = %v, want all %d", got, len(tasks))
+		}
+	})
+}
+
+func mustExec(t *testing.T, tx *gorm.DB, sql string) {
+	t.Helper()
+	err := tx.Exec(sql).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,190 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"todo/pkg/config"
+
+	"golang.org/x/image/webp"
+)
+
+var (
+	ErrUnsupported = errors.New("image must be PNG, JPEG, GIF or WebP")
+	ErrTooLarge    = errors.New("image exceeds the size limit")
+	ErrInvalid     = errors.New("image is corrupt")
+)
+
+const (
+	defaultMaxBytes      = 10 << 20
+	defaultMaxDimension  = 4096
+	defaultThumbnailSize = 256
+)
+
+type Limits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+	// ThumbnailSize is the edge of the square thumbnail in pixels.
+	ThumbnailSize int
+}
+
+// Result is an image ready to be stored: metadata such as EXIF and GPS
+// is gone and the thumbnail is a ThumbnailSize square.
+type Result struct {
+	Image                []byte
+	ContentType          string
+	Width                int
+	Height               int
+	Thumbnail            []byte
+	ThumbnailContentType string
+}
+
+type Processor interface {
+	Limits() Limits
+	// Process checks an uploaded image against the limits, strips its
+	// metadata and renders its thumbnail.
+	Process(data []byte) (Result, error)
+}
+
+type processor struct {
+	limits Limits
+}
+
+var p Processor
+
+func New(limits Limits) Processor {
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = defaultMaxBytes
+	}
+	if limits.MaxWidth <= 0 {
+		limits.MaxWidth = defaultMaxDimension
+	}
+	if limits.MaxHeight <= 0 {
+		limits.MaxHeight = defaultMaxDimension
+	}
+	if limits.ThumbnailSize <= 0 {
+		limits.ThumbnailSize = defaultThumbnailSize
+	}
+
+	return &processor{limits: limits}
+}
+
+func Init() error {
+	cfg := config.GetConfig().Image
+	p = New(Limits{
+		MaxBytes:      cfg.MaxBytes,
+		MaxWidth:      cfg.MaxWidth,
+		MaxHeight:     cfg.MaxHeight,
+		ThumbnailSize: cfg.ThumbnailSize,
+	})
+	return nil
+}
+
+func GetProcessor() Processor {
+	return p
+}
+
+func (p processor) Limits() Limits {
+	return p.limits
+}
+
+func (p processor) Process(data []byte) (Result, error) {
+	if int64(len(data)) > p.limits.MaxBytes {
+		return Result{}, ErrTooLarge
+	}
+
+	// the declared content type can't be trusted, only the bytes can
+	contentType := http.DetectContentType(data)
+	decodeConfig, decode, strip := formatOf(contentType)
+	if decodeConfig == nil {
+		return Result{}, ErrUnsupported
+	}
+
+	// the header is enough to turn away decompression bombs before any
+	// pixels are allocated
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrInvalid
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return Result{}, ErrInvalid
+	}
+	if cfg.Width > p.limits.MaxWidth || cfg.Height > p.limits.MaxHeight {
+		return Result{}, ErrTooLarge
+	}
+
+	img, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return Result{}, ErrInvalid
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	stripped, err := strip(data)
+	if err != nil {
+		return Result{}, ErrInvalid
+	}
+	result := Result{
+		Image:       stripped,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+	}
+	if orientation != 1 {
+		// the orientation went away with EXIF, so it is baked into the
+		// pixels instead
+		img = orient(img, orientation)
+		var buf bytes.Buffer
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90})
+		if err != nil {
+			return Result{}, err
+		}
+		result.Image = buf.Bytes()
+		result.Width, result.Height = img.Bounds().Dx(), img.Bounds().Dy()
+	}
+
+	result.Thumbnail, result.ThumbnailContentType, err = thumbnail(img, p.limits.ThumbnailSize)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
+
+type (
+	decodeConfigFunc func(r *bytes.Reader) (image.Config, error)
+	decodeFunc       func(r *bytes.Reader) (image.Image, error)
+	stripFunc        func(data []byte) ([]byte, error)
+)
+
+func formatOf(contentType string) (decodeConfigFunc, decodeFunc, stripFunc) {
+	switch contentType {
+	case "image/png":
+		return func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) },
+			stripPNG
+	case "image/jpeg":
+		return func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) },
+			stripJPEG
+	case "image/gif":
+		// only the first frame is decoded, it is all the thumbnail needs
+		return func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) },
+			stripGIF
+	case "image/webp":
+		return func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) },
+			stripWebP
+	}
+
+	return nil, nil, nil
+}
@@ -0,0 +1,318 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gpsMarker stands in for the GPS position the fixtures carry in their
+// metadata.
+const gpsMarker = "GPS 52.3702N 4.8952E"
+
+// twoTone is a w x h image, red in its top half and blue in its bottom
+// half.
+func twoTone(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if y >= h/2 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, frames int) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{}
+	for n := 0; n < frames; n++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, 4, 4), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withGIFComment inserts a comment extension before the trailer.
+func withGIFComment(data []byte, comment string) []byte {
+	out := append([]byte(nil), data[:len(data)-1]...)
+	out = append(out, 0x21, 0xfe, byte(len(comment)))
+	out = append(out, comment...)
+	return append(out, 0, 0x3b)
+}
+
+// pngChunk encodes a chunk with its length and CRC.
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+// withPNGChunks inserts chunks right after IHDR.
+func withPNGChunks(data []byte, chunks ...[]byte) []byte {
+	const afterIHDR = 8 + 12 + 13
+	out := append([]byte(nil), data[:afterIHDR]...)
+	for _, chunk := range chunks {
+		out = append(out, chunk...)
+	}
+	return append(out, data[afterIHDR:]...)
+}
+
+// jpegSegment encodes a marker segment with its length.
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xff, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// withJPEGSegments inserts segments right after SOI.
+func withJPEGSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte(nil), data[:2]...)
+	for _, segment := range segments {
+		out = append(out, segment...)
+	}
+	return append(out, data[2:]...)
+}
+
+// exif is a TIFF structure holding the orientation and a GPS IFD that
+// points at gpsMarker.
+func exif(order binary.AppendByteOrder, orientation int) []byte {
+	tiff := []byte("MM\x00\x2a")
+	if order == binary.LittleEndian {
+		tiff = []byte("II\x2a\x00")
+	}
+	tiff = order.AppendUint32(tiff, 8)
+
+	const entries = 2
+	gpsOffset := 8 + 2 + entries*12 + 4
+	tiff = order.AppendUint16(tiff, entries)
+	// orientation, a SHORT
+	tiff = order.AppendUint16(tiff, 0x0112)
+	tiff = order.AppendUint16(tiff, 3)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0, 0)
+	// GPS IFD pointer, a LONG
+	tiff = order.AppendUint16(tiff, 0x8825)
+	tiff = order.AppendUint16(tiff, 4)
+	tiff = order.AppendUint32(tiff, 1)
+	tiff = order.AppendUint32(tiff, uint32(gpsOffset))
+	// no next IFD
+	tiff = order.AppendUint32(tiff, 0)
+
+	return append(tiff, gpsMarker...)
+}
+
+func exifSegment(orientation int) []byte {
+	return jpegSegment(0xe1, append([]byte("Exif\x00\x00"), exif(binary.BigEndian, orientation)...))
+}
+
+func TestProcessor_Process(t *testing.T) {
+	jpegData := encodeJPEG(t, twoTone(32, 16))
+	pngData := encodePNG(t, twoTone(32, 16))
+	gifData := encodeGIF(t, 1)
+
+	// a PNG declaring 100000 x 100000 pixels with no pixel data; it is
+	// only turned away as too large if the header is checked first
+	bomb := append([]byte(pngSignature), pngChunk("IHDR", []byte{0, 1, 0x86, 0xa0, 0, 1, 0x86, 0xa0, 8, 6, 0, 0, 0})...)
+
+	tests := []struct {
+		name            string
+		limits          Limits
+		data            []byte
+		wantContentType string
+		wantErr         error
+	}{
+		{
+			name:            "jpeg with exif and gps",
+			data:            withJPEGSegments(jpegData, exifSegment(1), jpegSegment(0xfe, []byte("taken at home"))),
+			wantContentType: "image/jpeg",
+		},
+		{
+			name:            "png with exif and text",
+			data:            withPNGChunks(pngData, pngChunk("eXIf", exif(binary.BigEndian, 1)), pngChunk("tEXt", []byte("Comment\x00"+gpsMarker))),
+			wantContentType: "image/png",
+		},
+		{
+			name:            "gif with a comment",
+			data:            withGIFComment(gifData, "taken at home"),
+			wantContentType: "image/gif",
+		},
+		{
+			name:    "too many bytes",
+			limits:  Limits{MaxBytes: int64(len(pngData) - 1)},
+			data:    pngData,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "too wide",
+			limits:  Limits{MaxWidth: 31},
+			data:    pngData,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "too high",
+			limits:  Limits{MaxHeight: 15},
+			data:    jpegData,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "decompression bomb",
+			data:    bomb,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "not an image",
+			data:    []byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"),
+			wantErr: ErrUnsupported,
+		},
+		{
+			name:    "truncated png",
+			data:    pngData[:len(pngData)/2],
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "truncated jpeg",
+			data:    jpegData[:len(jpegData)/2],
+			wantErr: ErrInvalid,
+		},
+		{
+			name:    "png header only",
+			data:    pngData[:8],
+			wantErr: ErrInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New(tt.limits)
+
+			got, err := p.Process(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Process() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.ContentType != tt.wantContentType {
+				t.Errorf("Process() ContentType = %q, want %q", got.ContentType, tt.wantContentType)
+			}
+			for _, metadata := range []string{gpsMarker, "Exif\x00\x00", "eXIf", "tEXt", "taken at home"} {
+				if bytes.Contains(got.Image, []byte(metadata)) {
+					t.Errorf("Process() kept %q in the image", metadata)
+				}
+			}
+
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(got.Image))
+			if err != nil {
+				t.Fatalf("Process() image doesn't decode: %v", err)
+			}
+			if cfg.Width != got.Width || cfg.Height != got.Height {
+				t.Errorf("Process() image is %dx%d, reported %dx%d", cfg.Width, cfg.Height, got.Width, got.Height)
+			}
+
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(got.Thumbnail))
+			if err != nil {
+				t.Fatalf("Process() thumbnail doesn't decode: %v", err)
+			}
+			if thumb.Width != defaultThumbnailSize || thumb.Height != defaultThumbnailSize {
+				t.Errorf("Process() thumbnail is %dx%d, want %[3]dx%[3]d", thumb.Width, thumb.Height, defaultThumbnailSize)
+			}
+		})
+	}
+}
+
+func TestProcessor_Process_orientation(t *testing.T) {
+	// red on top and blue at the bottom, as stored by the camera
+	data := encodeJPEG(t, twoTone(64, 32))
+
+	tests := []struct {
+		name        string
+		orientation int
+		wantWidth   int
+		wantHeight  int
+		// the colours of the top corners of the thumbnail
+		wantTopLeft  string
+		wantTopRight string
+	}{
+		{name: "upright", orientation: 1, wantWidth: 64, wantHeight: 32, wantTopLeft: "red", wantTopRight: "red"},
+		{name: "upside down", orientation: 3, wantWidth: 64, wantHeight: 32, wantTopLeft: "blue", wantTopRight: "blue"},
+		{name: "rotated clockwise", orientation: 6, wantWidth: 32, wantHeight: 64, wantTopLeft: "blue", wantTopRight: "red"},
+		{name: "rotated counterclockwise", orientation: 8, wantWidth: 32, wantHeight: 64, wantTopLeft: "red", wantTopRight: "blue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(Limits{ThumbnailSize: 16}).Process(withJPEGSegments(data, exifSegment(tt.orientation)))
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if got.Width != tt.wantWidth || got.Height != tt.wantHeight {
+				t.Errorf("Process() = %dx%d, want %dx%d", got.Width, got.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if bytes.Contains(got.Image, []byte("Exif\x00\x00")) {
+				t.Error("Process() kept the EXIF segment")
+			}
+
+			thumb, err := jpeg.Decode(bytes.NewReader(got.Thumbnail))
+			if err != nil {
+				t.Fatalf("Process() thumbnail doesn't decode: %v", err)
+			}
+			if c := colorName(thumb.At(0, 0)); c != tt.wantTopLeft {
+				t.Errorf("Process() thumbnail top left = %s, want %s", c, tt.wantTopLeft)
+			}
+			if c := colorName(thumb.At(15, 0)); c != tt.wantTopRight {
+				t.Errorf("Process() thumbnail top right = %s, want %s", c, tt.wantTopRight)
+			}
+		})
+	}
+}
+
+// colorName tells the red and blue halves of the fixtures apart.
+func colorName(c color.Color) string {
+	r, _, b, _ := c.RGBA()
+	switch {
+	case r > 0xc000 && b < 0x4000:
+		return "red"
+	case b > 0xc000 && r < 0x4000:
+		return "blue"
+	}
+	return "mixed"
+}
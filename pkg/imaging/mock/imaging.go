@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/imaging/imaging.go
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	reflect "reflect"
+	imaging "todo/pkg/imaging"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProcessor is a mock of Processor interface.
+type MockProcessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockProcessorMockRecorder
+}
+
+// MockProcessorMockRecorder is the mock recorder for MockProcessor.
+type MockProcessorMockRecorder struct {
+	mock *MockProcessor
+}
+
+// NewMockProcessor creates a new mock instance.
+func NewMockProcessor(ctrl *gomock.Controller) *MockProcessor {
+	mock := &MockProcessor{ctrl: ctrl}
+	mock.recorder = &MockProcessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProcessor) EXPECT() *MockProcessorMockRecorder {
+	return m.recorder
+}
+
+// Limits mocks base method.
+func (m *MockProcessor) Limits() imaging.Limits {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Limits")
+	ret0, _ := ret[0].(imaging.Limits)
+	return ret0
+}
+
+// Limits indicates an expected call of Limits.
+func (mr *MockProcessorMockRecorder) Limits() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Limits", reflect.TypeOf((*MockProcessor)(nil).Limits))
+}
+
+// Process mocks base method.
+func (m *MockProcessor) Process(data []byte) (imaging.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", data)
+	ret0, _ := ret[0].(imaging.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockProcessorMockRecorder) Process(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockProcessor)(nil).Process), data)
+}
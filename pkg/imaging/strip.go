@@ -0,0 +1,301 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// The strippers drop metadata at the container level and copy the image
+// data as is, so stripping never costs quality.
+
+var errTruncated = errors.New("image is truncated")
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadata are the chunks that carry EXIF, text and timestamps.
+var pngMetadata = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"zTXt": true,
+	"iTXt": true,
+	"tIME": true,
+}
+
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrInvalid
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, errTruncated
+		}
+		end := i + 12 + int(binary.BigEndian.Uint32(data[i:]))
+		if end > len(data) || end < i {
+			return nil, errTruncated
+		}
+
+		chunk := string(data[i+4 : i+8])
+		if !pngMetadata[chunk] {
+			out.Write(data[i:end])
+		}
+		i = end
+		if chunk == "IEND" {
+			break
+		}
+	}
+
+	return out.Bytes(), nil
+}
+
+// stripJPEG keeps JFIF, the ICC profile and the Adobe colour transform and
+// drops every other application segment and comment.
+func stripJPEG(data []byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != 0xff || data[1] != 0xd8 {
+		return nil, ErrInvalid
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:2])
+	for i := 2; i < len(data); {
+		if data[i] != 0xff {
+			return nil, ErrInvalid
+		}
+		if i+1 >= len(data) {
+			return nil, errTruncated
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xff:
+			// fill byte
+			i++
+			continue
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			out.Write(data[i : i+2])
+			i += 2
+			continue
+		case marker == 0xd9:
+			out.Write(data[i : i+2])
+			return out.Bytes(), nil
+		}
+
+		if i+4 > len(data) {
+			return nil, errTruncated
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return nil, errTruncated
+		}
+
+		if marker == 0xda {
+			// start of scan: the rest is entropy-coded data
+			out.Write(data[i:])
+			return out.Bytes(), nil
+		}
+		if keepJPEGSegment(marker, data[i+4:end]) {
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+
+	return out.Bytes(), nil
+}
+
+func keepJPEGSegment(marker byte, payload []byte) bool {
+	switch {
+	case marker == 0xe0:
+		return bytes.HasPrefix(payload, []byte("JFIF\x00"))
+	case marker == 0xe2:
+		return bytes.HasPrefix(payload, []byte("ICC_PROFILE\x00"))
+	case marker == 0xee:
+		return bytes.HasPrefix(payload, []byte("Adobe"))
+	case marker >= 0xe1 && marker <= 0xef, marker == 0xfe:
+		return false
+	}
+	return true
+}
+
+// jpegOrientation reads the EXIF orientation tag, 1 when there is none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xda || marker == 0xd9 {
+			return 1
+		}
+		end := i + 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+		if end > len(data) {
+			return 1
+		}
+		payload := data[i+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(payload, []byte("Exif\x00\x00")) {
+			return exifOrientation(payload[6:])
+		}
+		i = end
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
+
+// stripGIF drops comment and application extensions except the one that
+// makes an animation loop.
+func stripGIF(data []byte) ([]byte, error) {
+	if len(data) < 13 || !bytes.HasPrefix(data, []byte("GIF")) {
+		return nil, ErrInvalid
+	}
+
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+	if i > len(data) {
+		return nil, errTruncated
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:i])
+	for i < len(data) {
+		start := i
+		switch data[i] {
+		case 0x3b:
+			out.WriteByte(0x3b)
+			return out.Bytes(), nil
+		case 0x21:
+			if i+2 > len(data) {
+				return nil, errTruncated
+			}
+			label := data[i+1]
+			end, err := skipSubBlocks(data, i+2)
+			if err != nil {
+				return nil, err
+			}
+			if keepGIFExtension(label, data[i+2:end]) {
+				out.Write(data[start:end])
+			}
+			i = end
+		case 0x2c:
+			if i+10 > len(data) {
+				return nil, errTruncated
+			}
+			i += 10
+			if data[start+9]&0x80 != 0 {
+				i += 3 << (data[start+9]&0x07 + 1)
+			}
+			// LZW minimum code size
+			i++
+			end, err := skipSubBlocks(data, i)
+			if err != nil {
+				return nil, err
+			}
+			out.Write(data[start:end])
+			i = end
+		default:
+			return nil, ErrInvalid
+		}
+	}
+
+	return nil, errTruncated
+}
+
+func keepGIFExtension(label byte, blocks []byte) bool {
+	switch label {
+	case 0xfe:
+		// comment
+		return false
+	case 0xff:
+		return len(blocks) >= 12 && blocks[0] == 11 &&
+			(string(blocks[1:12]) == "NETSCAPE2.0" || string(blocks[1:12]) == "ANIMEXTS1.0")
+	}
+	return true
+}
+
+// skipSubBlocks returns the offset after the block terminator.
+func skipSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, errTruncated
+		}
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, nil
+		}
+		i += size
+	}
+}
+
+// stripWebP drops the EXIF and XMP chunks and clears their VP8X flags.
+func stripWebP(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, ErrInvalid
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:12])
+	for i := 12; i < len(data); {
+		if i+8 > len(data) {
+			return nil, errTruncated
+		}
+		size := int(binary.LittleEndian.Uint32(data[i+4:]))
+		end := i + 8 + size + size&1
+		if end > len(data) || end < i {
+			return nil, errTruncated
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			chunk := append([]byte(nil), data[i:end]...)
+			if len(chunk) > 8 {
+				chunk[8] &^= 0x08 | 0x04
+			}
+			out.Write(chunk)
+		default:
+			out.Write(data[i:end])
+		}
+		i = end
+	}
+
+	stripped := out.Bytes()
+	binary.LittleEndian.PutUint32(stripped[4:], uint32(len(stripped)-8))
+	return stripped, nil
+}
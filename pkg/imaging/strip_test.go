@@ -0,0 +1,237 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestStripPNG(t *testing.T) {
+	data := encodePNG(t, twoTone(4, 4))
+	withMetadata := withPNGChunks(data,
+		pngChunk("eXIf", exif(binary.BigEndian, 6)),
+		pngChunk("tEXt", []byte("Comment\x00"+gpsMarker)),
+		pngChunk("iTXt", []byte("XML:com.adobe.xmp\x00\x00\x00\x00\x00<x:xmpmeta/>")),
+		pngChunk("tIME", []byte{0x07, 0xe8, 1, 1, 0, 0, 0}),
+		pngChunk("gAMA", []byte{0, 0, 0xb1, 0x8f}),
+	)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr error
+	}{
+		{name: "without metadata", data: data, want: data},
+		{name: "with metadata", data: withMetadata, want: withPNGChunks(data, pngChunk("gAMA", []byte{0, 0, 0xb1, 0x8f}))},
+		{name: "data after IEND", data: append(append([]byte(nil), data...), "trailing"...), want: data},
+		{name: "not a png", data: []byte("GIF89a"), wantErr: ErrInvalid},
+		{name: "truncated chunk header", data: data[:len(pngSignature)+4], wantErr: errTruncated},
+		{name: "truncated chunk", data: data[:len(data)-6], wantErr: errTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripPNG(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stripPNG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("stripPNG() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := encodeJPEG(t, twoTone(8, 8))
+	jfif := jpegSegment(0xe0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	icc := jpegSegment(0xe2, []byte("ICC_PROFILE\x00\x01\x01profile"))
+	adobe := jpegSegment(0xee, []byte("Adobe\x00\x64\x00\x00\x00\x00\x01"))
+	xmp := jpegSegment(0xe1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>"))
+	comment := jpegSegment(0xfe, []byte("taken at home"))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr error
+	}{
+		{name: "without metadata", data: data, want: data},
+		{
+			name: "with metadata",
+			data: withJPEGSegments(data, jfif, exifSegment(6), icc, xmp, comment, adobe),
+			want: withJPEGSegments(data, jfif, icc, adobe),
+		},
+		{name: "fill bytes", data: withJPEGSegments(data, []byte{0xff, 0xff}, comment), want: data},
+		{name: "not a jpeg", data: []byte("\x89PNG"), wantErr: ErrInvalid},
+		{name: "garbage between segments", data: withJPEGSegments(data, []byte{0x00}), wantErr: ErrInvalid},
+		{name: "truncated segment", data: withJPEGSegments(data, comment)[:10], wantErr: errTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripJPEG(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stripJPEG() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("stripJPEG() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripGIF(t *testing.T) {
+	still := encodeGIF(t, 1)
+	animated := encodeGIF(t, 2)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr error
+	}{
+		{name: "still", data: still, want: still},
+		{name: "with a comment", data: withGIFComment(still, "taken at home"), want: still},
+		{name: "animation keeps looping", data: withGIFComment(animated, "taken at home"), want: animated},
+		{name: "not a gif", data: []byte("RIFF\x00\x00\x00\x00WEBP"), wantErr: ErrInvalid},
+		{name: "missing trailer", data: still[:len(still)-1], wantErr: errTruncated},
+		{name: "truncated image", data: still[:len(still)-4], wantErr: errTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripGIF(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stripGIF() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("stripGIF() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if !bytes.Contains(animated, []byte("NETSCAPE2.0")) {
+		t.Error("animated fixture has no loop extension")
+	}
+}
+
+// webpChunk encodes a RIFF chunk, padded to an even size.
+func webpChunk(kind string, payload []byte) []byte {
+	chunk := append([]byte(kind), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+// riffWebP wraps chunks in a WebP container.
+func riffWebP(chunks ...[]byte) []byte {
+	var body []byte
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	data := append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)+4))...)
+	data = append(data, "WEBP"...)
+	return append(data, body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X flags: ICC 0x20, alpha 0x10, EXIF 0x08, XMP 0x04
+	vp8x := func(flags byte) []byte {
+		return webpChunk("VP8X", []byte{flags, 0, 0, 0, 3, 0, 0, 3, 0, 0})
+	}
+	pixels := webpChunk("VP8L", []byte("pixels"))
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    []byte
+		wantErr error
+	}{
+		{name: "simple", data: riffWebP(pixels), want: riffWebP(pixels)},
+		{
+			name: "extended with metadata",
+			data: riffWebP(vp8x(0x20|0x10|0x08|0x04), webpChunk("ICCP", []byte("profile")), pixels, webpChunk("EXIF", exif(binary.LittleEndian, 6)), webpChunk("XMP ", []byte("<x:xmpmeta/>"))),
+			want: riffWebP(vp8x(0x20|0x10), webpChunk("ICCP", []byte("profile")), pixels),
+		},
+		{name: "not a webp", data: []byte("RIFF\x00\x00\x00\x00WAVE"), wantErr: ErrInvalid},
+		{name: "truncated chunk header", data: riffWebP(pixels)[:16], wantErr: errTruncated},
+		{name: "truncated chunk", data: riffWebP(pixels)[:22], wantErr: errTruncated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripWebP(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("stripWebP() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("stripWebP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	data := encodeJPEG(t, twoTone(8, 8))
+	littleEndian := jpegSegment(0xe1, append([]byte("Exif\x00\x00"), exif(binary.LittleEndian, 8)...))
+	outOfRange := exifSegment(9)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{name: "no exif", data: data, want: 1},
+		{name: "big endian", data: withJPEGSegments(data, exifSegment(6)), want: 6},
+		{name: "little endian", data: withJPEGSegments(data, littleEndian), want: 8},
+		{name: "after other segments", data: withJPEGSegments(data, jpegSegment(0xfe, []byte("comment")), exifSegment(3)), want: 3},
+		{name: "out of range", data: withJPEGSegments(data, outOfRange), want: 1},
+		{name: "truncated exif", data: withJPEGSegments(data, exifSegment(6))[:24], want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("jpegOrientation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// a 2x1 image, red on the left and blue on the right
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	red, blue := color.NRGBA{R: 255, A: 255}, color.NRGBA{B: 255, A: 255}
+	img.SetNRGBA(0, 0, red)
+	img.SetNRGBA(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		// the pixels of the result, row by row
+		want [][]color.NRGBA
+	}{
+		{orientation: 1, want: [][]color.NRGBA{{red, blue}}},
+		{orientation: 2, want: [][]color.NRGBA{{blue, red}}},
+		{orientation: 3, want: [][]color.NRGBA{{blue, red}}},
+		{orientation: 4, want: [][]color.NRGBA{{red, blue}}},
+		{orientation: 5, want: [][]color.NRGBA{{red}, {blue}}},
+		{orientation: 6, want: [][]color.NRGBA{{red}, {blue}}},
+		{orientation: 7, want: [][]color.NRGBA{{blue}, {red}}},
+		{orientation: 8, want: [][]color.NRGBA{{blue}, {red}}},
+	}
+	for _, tt := range tests {
+		got := orient(img, tt.orientation).(*image.NRGBA)
+		if got.Bounds().Dy() != len(tt.want) || got.Bounds().Dx() != len(tt.want[0]) {
+			t.Errorf("orient(%d) = %v, want %dx%d", tt.orientation, got.Bounds(), len(tt.want[0]), len(tt.want))
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if c := got.NRGBAAt(x, y); c != want {
+					t.Errorf("orient(%d) at %d,%d = %v, want %v", tt.orientation, x, y, c, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// thumbnail scales img to cover a size x size square and crops the
+// overflow evenly. Opaque images become JPEG, the rest keep their
+// transparency as PNG.
+func thumbnail(img image.Image, size int) ([]byte, string, error) {
+	b := img.Bounds()
+	crop := b
+	if b.Dx() > b.Dy() {
+		offset := (b.Dx() - b.Dy()) / 2
+		crop = image.Rect(b.Min.X+offset, b.Min.Y, b.Min.X+offset+b.Dy(), b.Max.Y)
+	} else if b.Dy() > b.Dx() {
+		offset := (b.Dy() - b.Dx()) / 2
+		crop = image.Rect(b.Min.X, b.Min.Y+offset, b.Max.X, b.Min.Y+offset+b.Dx())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+
+	var buf bytes.Buffer
+	if dst.Opaque() {
+		err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80})
+		return buf.Bytes(), "image/jpeg", err
+	}
+	err := png.Encode(&buf, dst)
+	return buf.Bytes(), "image/png", err
+}
+
+// orient applies an EXIF orientation so that the image is upright.
+func orient(img image.Image, orientation int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = w-1-y, x
+			case 7:
+				dx, dy = w-1-y, h-1-x
+			case 8:
+				dx, dy = y, h-1-x
+			default:
+				dx, dy = x, y
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
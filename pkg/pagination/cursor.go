@@ -0,0 +1,43 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+// Cursor points at the last row of a page. Value holds the sort column of
+// that row and ID breaks ties so the ordering stays stable. SortBy and
+// SortOrder are the ordering of the list, which the next page must keep.
+type Cursor struct {
+	Value     string `json:"v"`
+	ID        int    `json:"id"`
+	SortBy    string `json:"sb,omitempty"`
+	SortOrder string `json:"so,omitempty"`
+}
+
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func Decode(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
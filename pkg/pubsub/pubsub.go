@@ -0,0 +1,197 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"todo/pkg/cache"
+	"todo/pkg/config"
+)
+
+// DefaultHistory is how many messages of a topic are kept for subscribers
+// resuming after a disconnect.
+const DefaultHistory = 1000
+
+// subscriberBuffer is how many messages a subscriber may fall behind before
+// it is dropped.
+const subscriberBuffer = 100
+
+// Message is data published on a topic. IDs grow by one with every message
+// of the topic, so a gap shows that messages were lost.
+type Message struct {
+	ID   int64
+	Data []byte
+}
+
+// Subscription receives the messages of a topic on C. C is closed when the
+// subscription is closed, falls behind or messages were lost; subscribe
+// again to resume from the last message received.
+type Subscription struct {
+	C <-chan Message
+	// Missed is set when messages after the one resumed from are no
+	// longer kept, so they could not be replayed.
+	Missed bool
+	// Latest is the ID of the newest message when subscribing, to resume
+	// from after a miss. It is 0 while the broker hasn't seen one yet.
+	Latest int64
+	close  func()
+}
+
+// Close stops the subscription and closes C. It is safe to call more than
+// once.
+func (s *Subscription) Close() {
+	s.close()
+}
+
+// Broker fans messages out to the subscribers of every replica.
+type Broker interface {
+	// Publish numbers data and sends it to the subscribers of topic.
+	Publish(ctx context.Context, topic string, data []byte) error
+	// Subscribe replays the kept messages of topic after the one with ID
+	// after, then follows new ones. An after of 0 only follows new ones.
+	Subscribe(topic string, after int64) *Subscription
+}
+
+var broker Broker
+
+// Init publishes through Redis when it is configured, so subscribers on
+// every replica receive the messages, and in memory otherwise.
+func Init() error {
+	history := config.GetConfig().Events.History
+	if history <= 0 {
+		history = DefaultHistory
+	}
+
+	if client := cache.GetClient(); client != nil {
+		broker = NewRedisBroker(context.Background(), client, history)
+	} else {
+		broker = NewMemoryBroker(history)
+	}
+
+	return nil
+}
+
+func GetBroker() Broker {
+	return broker
+}
+
+// hub keeps the history and the subscribers of topics on this replica.
+type hub struct {
+	mu   sync.Mutex
+	size int
+	// first is the ID a topic starts counting after
+	first  int64
+	topics map[string]*topic
+}
+
+type topic struct {
+	mu          sync.Mutex
+	last        int64
+	history     []Message
+	subscribers map[chan Message]struct{}
+}
+
+func newHub(size int, first int64) *hub {
+	return &hub{
+		size:   size,
+		first:  first,
+		topics: make(map[string]*topic),
+	}
+}
+
+func (h *hub) topic(name string) *topic {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{last: h.first, subscribers: make(map[chan Message]struct{})}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// publish numbers data itself, for brokers that see every message first.
+func (h *hub) publish(name string, data []byte) {
+	t := h.topic(name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	h.append(t, Message{ID: t.last + 1, Data: data})
+}
+
+// deliver takes a message numbered elsewhere.
+func (h *hub) deliver(name string, m Message) {
+	t := h.topic(name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.last > 0 && m.ID != t.last+1 {
+		// messages were lost, e.g. while Redis was unreachable; subscribers
+		// have to resume to find out that they missed some
+		t.history = nil
+		for ch := range t.subscribers {
+			close(ch)
+			delete(t.subscribers, ch)
+		}
+	}
+	h.append(t, m)
+}
+
+func (h *hub) append(t *topic, m Message) {
+	t.last = m.ID
+	t.history = append(t.history, m)
+	if len(t.history) > h.size {
+		t.history = t.history[len(t.history)-h.size:]
+	}
+
+	for ch := range t.subscribers {
+		select {
+		case ch <- m:
+		default:
+			// too slow to keep up, it resumes from history instead
+			close(ch)
+			delete(t.subscribers, ch)
+		}
+	}
+}
+
+func (h *hub) subscribe(name string, after int64) *Subscription {
+	t := h.topic(name)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var replay []Message
+	missed := false
+	if after > 0 && after != t.last {
+		switch {
+		case after > t.last:
+			// an ID from before a restart, or of messages not seen yet
+			missed = true
+		case len(t.history) == 0 || t.history[0].ID > after+1:
+			missed = true
+		default:
+			replay = t.history[after+1-t.history[0].ID:]
+		}
+	}
+
+	ch := make(chan Message, len(replay)+subscriberBuffer)
+	for _, m := range replay {
+		ch <- m
+	}
+	t.subscribers[ch] = struct{}{}
+
+	return &Subscription{
+		C:      ch,
+		Missed: missed,
+		Latest: t.last,
+		close: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+
+			if _, ok := t.subscribers[ch]; ok {
+				close(ch)
+				delete(t.subscribers, ch)
+			}
+		},
+	}
+}
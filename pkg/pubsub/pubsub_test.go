@@ -0,0 +1,178 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func receive(t *testing.T, sub *Subscription) Message {
+	t.Helper()
+
+	select {
+	case m, ok := <-sub.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return m
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+	return Message{}
+}
+
+func TestMemoryBroker(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBroker(2)
+
+	live := b.Subscribe("tasks", 0)
+	defer live.Close()
+	other := b.Subscribe("projects", 0)
+	defer other.Close()
+
+	for i := 1; i <= 3; i++ {
+		if err := b.Publish(ctx, "tasks", []byte(fmt.Sprint(i))); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	var ids []int64
+	for i := 1; i <= 3; i++ {
+		m := receive(t, live)
+		if string(m.Data) != fmt.Sprint(i) {
+			t.Fatalf("message %d = %s", i, m.Data)
+		}
+		ids = append(ids, m.ID)
+	}
+	if ids[1] != ids[0]+1 || ids[2] != ids[1]+1 {
+		t.Fatalf("IDs %v are not consecutive", ids)
+	}
+	if len(other.C) > 0 {
+		t.Fatal("message of another topic received")
+	}
+
+	// the last two messages are kept
+	resumed := b.Subscribe("tasks", ids[0])
+	if resumed.Missed || resumed.Latest != ids[2] {
+		t.Fatalf("Subscribe() missed = %v, latest = %v", resumed.Missed, resumed.Latest)
+	}
+	if m := receive(t, resumed); m.ID != ids[1] {
+		t.Fatalf("first replayed message = %v, want %v", m.ID, ids[1])
+	}
+	if m := receive(t, resumed); m.ID != ids[2] {
+		t.Fatalf("second replayed message = %v, want %v", m.ID, ids[2])
+	}
+	resumed.Close()
+	resumed.Close()
+	if _, ok := <-resumed.C; ok {
+		t.Fatal("closed subscription still receives")
+	}
+
+	upToDate := b.Subscribe("tasks", ids[2])
+	defer upToDate.Close()
+	if upToDate.Missed || len(upToDate.C) > 0 {
+		t.Fatalf("Subscribe() of the latest message missed = %v, replayed %d", upToDate.Missed, len(upToDate.C))
+	}
+
+	for _, after := range []int64{ids[0] - 1, ids[2] + 1, 1} {
+		sub := b.Subscribe("tasks", after)
+		if !sub.Missed || len(sub.C) > 0 {
+			t.Errorf("Subscribe(%d) missed = %v, replayed %d, want a miss", after, sub.Missed, len(sub.C))
+		}
+		sub.Close()
+	}
+}
+
+func TestMemoryBroker_slowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	b := NewMemoryBroker(DefaultHistory)
+
+	sub := b.Subscribe("tasks", 0)
+	defer sub.Close()
+	for i := 0; i <= subscriberBuffer; i++ {
+		_ = b.Publish(ctx, "tasks", nil)
+	}
+
+	n := 0
+	for range sub.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Fatalf("received %d messages before being dropped, want %d", n, subscriberBuffer)
+	}
+}
+
+func TestHub_gap(t *testing.T) {
+	h := newHub(DefaultHistory, 0)
+
+	h.deliver("tasks", Message{ID: 7})
+	sub := h.subscribe("tasks", 0)
+	h.deliver("tasks", Message{ID: 8})
+	h.deliver("tasks", Message{ID: 10})
+
+	if m := receive(t, sub); m.ID != 8 {
+		t.Fatalf("message = %v, want 8", m.ID)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatal("subscription not closed after a gap")
+	}
+
+	resumed := h.subscribe("tasks", 8)
+	defer resumed.Close()
+	if !resumed.Missed || resumed.Latest != 10 {
+		t.Fatalf("Subscribe() after a gap missed = %v, latest = %v", resumed.Missed, resumed.Latest)
+	}
+}
+
+func TestRedisBroker_replicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := miniredis.RunT(t)
+	newBroker := func() Broker {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { client.Close() })
+		return NewRedisBroker(ctx, client, DefaultHistory)
+	}
+	a, b := newBroker(), newBroker()
+
+	subA := a.Subscribe("tasks", 0)
+	defer subA.Close()
+	subB := b.Subscribe("tasks", 0)
+	defer subB.Close()
+
+	// wait for both brokers to listen
+	deadline := time.Now().Add(time.Second)
+	for server.PubSubNumPat() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("brokers did not subscribe")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := a.Publish(ctx, "tasks", []byte("foo")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if err := b.Publish(ctx, "tasks", []byte("bar:baz")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	for _, sub := range []*Subscription{subA, subB} {
+		if m := receive(t, sub); m.ID != 1 || string(m.Data) != "foo" {
+			t.Errorf("first message = %v %s, want 1 foo", m.ID, m.Data)
+		}
+		if m := receive(t, sub); m.ID != 2 || string(m.Data) != "bar:baz" {
+			t.Errorf("second message = %v %s, want 2 bar:baz", m.ID, m.Data)
+		}
+	}
+
+	resumed := b.Subscribe("tasks", 1)
+	defer resumed.Close()
+	if m := receive(t, resumed); resumed.Missed || m.ID != 2 {
+		t.Fatalf("Subscribe() missed = %v, replayed %v, want 2", resumed.Missed, m.ID)
+	}
+}
@@ -0,0 +1,82 @@
+package pubsub
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"todo/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// channelPrefix names the Redis channels of topics and, followed by the
+// topic and ":id", their counters.
+const channelPrefix = "pubsub:"
+
+// publishScript numbers ARGV[2] with the counter KEYS[1] and publishes it
+// on the channel ARGV[1]. Doing both in one script keeps the messages of a
+// topic in the order of their IDs.
+var publishScript = redis.NewScript(`
+local id = redis.call('INCR', KEYS[1])
+redis.call('PUBLISH', ARGV[1], id .. ':' .. ARGV[2])
+return id
+`)
+
+type redisBroker struct {
+	client redis.UniversalClient
+	hub    *hub
+	log    logger.Logger
+}
+
+// NewRedisBroker publishes through Redis, which numbers the messages of a
+// topic for every replica alike. It receives them until ctx is done. While
+// Redis is unreachable, publishing fails.
+func NewRedisBroker(ctx context.Context, client redis.UniversalClient, history int) Broker {
+	b := &redisBroker{
+		client: client,
+		hub:    newHub(history, 0),
+		log:    logger.WithPrefix("pubsub"),
+	}
+
+	pubsub := client.PSubscribe(ctx, channelPrefix+"*")
+	go b.receive(ctx, pubsub)
+
+	return b
+}
+
+func (b *redisBroker) Publish(ctx context.Context, topic string, data []byte) error {
+	channel := channelPrefix + topic
+	return publishScript.Run(ctx, b.client, []string{channel + ":id"}, channel, data).Err()
+}
+
+func (b *redisBroker) Subscribe(topic string, after int64) *Subscription {
+	return b.hub.subscribe(topic, after)
+}
+
+// receive hands the messages of every topic to the hub. The channel
+// reconnects by itself; messages published meanwhile are lost, which the
+// hub notices from the gap in their IDs.
+func (b *redisBroker) receive(ctx context.Context, pubsub *redis.PubSub) {
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			topic := strings.TrimPrefix(msg.Channel, channelPrefix)
+			id, data, found := strings.Cut(msg.Payload, ":")
+			n, err := strconv.ParseInt(id, 10, 64)
+			if !found || err != nil {
+				b.log.Wrap("malformed message on %s", msg.Channel).Warn()
+				continue
+			}
+			b.hub.deliver(topic, Message{ID: n, Data: []byte(data)})
+		}
+	}
+}
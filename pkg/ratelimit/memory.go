@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often counts that no longer weigh in are dropped.
+const sweepInterval = time.Minute
+
+type counts struct {
+	size     time.Duration
+	index    int64
+	previous int
+	current  int
+}
+
+type memoryLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*counts
+	swept   time.Time
+	now     func() time.Time
+}
+
+// NewMemoryLimiter keeps the counts in the process; every replica limits on
+// its own.
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		windows: map[string]*counts{},
+		now:     time.Now,
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := l.now()
+	index, elapsed, weight := window(now, rule.Window)
+	key = rule.Key() + ":" + key
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	w, ok := l.windows[key]
+	if !ok || w.size != rule.Window {
+		w = &counts{size: rule.Window, index: index}
+		l.windows[key] = w
+	}
+	switch w.index {
+	case index:
+	case index - 1:
+		w.index, w.previous, w.current = index, w.current, 0
+	default:
+		w.index, w.previous, w.current = index, 0, 0
+	}
+
+	r := result(rule, w.previous, w.current, elapsed, weight)
+	if r.Allowed {
+		w.current++
+	}
+
+	return r, nil
+}
+
+func (l *memoryLimiter) sweep(now time.Time) {
+	l.swept = now
+	for key, w := range l.windows {
+		if w.index < now.UnixNano()/int64(w.size)-1 {
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+	"todo/pkg/cache"
+	"todo/pkg/config"
+)
+
+// Rule limits the requests matching Method and Path to Limit per Window.
+// An empty Method matches every method and a Path ending in /* every path
+// below it. A Limit of 0 lifts the limit.
+type Rule struct {
+	Method string
+	Path   string
+	Limit  int
+	Window time.Duration
+}
+
+// Key tells the counters of rules apart.
+func (r Rule) Key() string {
+	if len(r.Path) == 0 {
+		return "default"
+	}
+	return r.Method + " " + r.Path
+}
+
+func (r Rule) matches(method string, path string) bool {
+	if len(r.Method) > 0 && !strings.EqualFold(r.Method, method) {
+		return false
+	}
+	if prefix, ok := strings.CutSuffix(r.Path, "/*"); ok {
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	return path == r.Path
+}
+
+// Policy is the default rule and the rules of single routes, which take
+// precedence in order.
+type Policy struct {
+	Default Rule
+	Rules   []Rule
+}
+
+// Match returns the rule of a request.
+func (p Policy) Match(method string, path string) Rule {
+	for _, rule := range p.Rules {
+		if rule.matches(method, path) {
+			return rule
+		}
+	}
+	return p.Default
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time left until the current window ends.
+	Reset time.Duration
+	// RetryAfter is set when the request was refused.
+	RetryAfter time.Duration
+}
+
+// Limiter counts requests in sliding windows. A window is approximated
+// from two fixed ones: the count of the current one plus the count of the
+// previous one, weighted by how much of it still overlaps.
+type Limiter interface {
+	// Allow counts a request of key against rule unless it is over the
+	// limit.
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+var (
+	limiter Limiter
+	policy  Policy
+)
+
+// Init limits with Redis when it is configured, so limits hold across
+// replicas, and in memory otherwise.
+func Init() error {
+	cfg := config.GetConfig().RateLimit
+
+	policy = Policy{Default: withDefaults(Rule{Limit: cfg.Default.Limit, Window: cfg.Default.Window})}
+	for _, r := range cfg.Rules {
+		policy.Rules = append(policy.Rules, withDefaults(Rule{Method: r.Method, Path: r.Path, Limit: r.Limit, Window: r.Window}))
+	}
+
+	memory := NewMemoryLimiter()
+	if client := cache.GetClient(); client != nil {
+		limiter = NewRedisLimiter(client, memory)
+	} else {
+		limiter = memory
+	}
+
+	return nil
+}
+
+func withDefaults(rule Rule) Rule {
+	if rule.Window <= 0 {
+		rule.Window = time.Minute
+	}
+	return rule
+}
+
+func GetLimiter() Limiter {
+	return limiter
+}
+
+func GetPolicy() Policy {
+	return policy
+}
+
+// window splits now into the index of its fixed window, the time elapsed
+// in it and the weight left to the previous one.
+func window(now time.Time, size time.Duration) (int64, time.Duration, float64) {
+	index := now.UnixNano() / int64(size)
+	elapsed := time.Duration(now.UnixNano() - index*int64(size))
+	return index, elapsed, 1 - float64(elapsed)/float64(size)
+}
+
+// result tells whether one more request fits next to the counts of the
+// previous and the current window.
+func result(rule Rule, previous int, current int, elapsed time.Duration, weight float64) Result {
+	count := int(math.Floor(float64(previous)*weight)) + current
+	r := Result{
+		Allowed: count < rule.Limit,
+		Limit:   rule.Limit,
+		Reset:   rule.Window - elapsed,
+	}
+	if r.Allowed {
+		r.Remaining = rule.Limit - count - 1
+		return r
+	}
+
+	if current < rule.Limit {
+		// wait for the previous window to slide out far enough
+		needed := 1 - float64(rule.Limit-current)/float64(previous)
+		r.RetryAfter = time.Duration(needed*float64(rule.Window)) - elapsed
+	} else {
+		// the current window alone is full, it has to become the previous
+		// one and slide out in turn
+		needed := 1 - float64(rule.Limit)/float64(current)
+		r.RetryAfter = rule.Window - elapsed + time.Duration(needed*float64(rule.Window))
+	}
+	// the count has to drop below the limit, not just reach it
+	r.RetryAfter = max(r.RetryAfter, 0) + time.Millisecond
+
+	return r
+}
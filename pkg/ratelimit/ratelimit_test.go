@@ -0,0 +1,157 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestPolicy_Match(t *testing.T) {
+	policy := Policy{
+		Default: Rule{Limit: 300},
+		Rules: []Rule{
+			{Method: "POST", Path: "/api/auth/login", Limit: 10},
+			{Path: "/api/shared/*", Limit: 60},
+		},
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{method: "POST", path: "/api/auth/login", want: 10},
+		{method: "post", path: "/api/auth/login", want: 10},
+		{method: "GET", path: "/api/auth/login", want: 300},
+		{method: "GET", path: "/api/shared/token", want: 60},
+		{method: "POST", path: "/api/shared/token/comments", want: 60},
+		{method: "GET", path: "/api/sharedfoo", want: 300},
+		{method: "GET", path: "/api/tasks", want: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if got := policy.Match(tt.method, tt.path); got.Limit != tt.want {
+				t.Errorf("Policy.Match() limit = %v, want %v", got.Limit, tt.want)
+			}
+		})
+	}
+}
+
+// testLimiter runs the sliding window scenario every limiter must pass. now
+// moves the clock of the limiter under test.
+func testLimiter(t *testing.T, limiter Limiter, now *time.Time) {
+	t.Helper()
+	ctx := context.Background()
+	rule := Rule{Limit: 4, Window: time.Minute}
+
+	allow := func(key string, want bool) Result {
+		t.Helper()
+		r, err := limiter.Allow(ctx, key, rule)
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if r.Allowed != want {
+			t.Fatalf("Allow() at %v allowed = %v, want %v", now.Format(time.TimeOnly), r.Allowed, want)
+		}
+		return r
+	}
+
+	for i := 0; i < 4; i++ {
+		r := allow("a", true)
+		if r.Remaining != 3-i || r.Limit != 4 || r.Reset != time.Minute {
+			t.Fatalf("Allow() = %+v", r)
+		}
+	}
+	r := allow("a", false)
+	// the four requests weigh in fully until the next window starts
+	if r.RetryAfter != time.Minute+time.Millisecond {
+		t.Fatalf("Allow() retry after = %v", r.RetryAfter)
+	}
+	// other clients have counts of their own
+	allow("b", true)
+
+	*now = now.Add(time.Minute + 30*time.Second)
+	// half of the previous window still counts, 2 requests
+	allow("a", true)
+	allow("a", true)
+	r = allow("a", false)
+	if r.RetryAfter != time.Millisecond {
+		t.Fatalf("Allow() retry after = %v", r.RetryAfter)
+	}
+
+	// a quarter of the previous window is left, 1 request
+	*now = now.Add(15 * time.Second)
+	allow("a", true)
+	r = allow("a", false)
+	// the quarter counts as one request only until it shrinks
+	if r.RetryAfter != time.Millisecond {
+		t.Fatalf("Allow() retry after = %v", r.RetryAfter)
+	}
+
+	*now = now.Add(2 * time.Minute)
+	for i := 0; i < 4; i++ {
+		allow("a", true)
+	}
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter().(*memoryLimiter)
+	limiter.now = func() time.Time { return now }
+
+	testLimiter(t, limiter, &now)
+
+	now = now.Add(time.Hour)
+	limiter.Allow(context.Background(), "c", Rule{Limit: 1, Window: time.Minute})
+	if len(limiter.windows) != 1 {
+		t.Errorf("memoryLimiter kept %d stale counts", len(limiter.windows)-1)
+	}
+}
+
+func TestRedisLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, NewMemoryLimiter()).(*redisLimiter)
+	limiter.now = func() time.Time { return now }
+
+	testLimiter(t, limiter, &now)
+}
+
+func TestRedisLimiter_replicas(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule := Rule{Limit: 2, Window: time.Minute}
+
+	server := miniredis.RunT(t)
+	var replicas []*redisLimiter
+	for i := 0; i < 2; i++ {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+		defer client.Close()
+
+		limiter := NewRedisLimiter(client, NewMemoryLimiter()).(*redisLimiter)
+		limiter.now = func() time.Time { return now }
+		replicas = append(replicas, limiter)
+	}
+
+	for i, want := range []bool{true, true, false} {
+		r, err := replicas[i%2].Allow(ctx, "a", rule)
+		if err != nil || r.Allowed != want {
+			t.Fatalf("Allow() #%d = %+v, %v, want allowed %v", i, r, err, want)
+		}
+	}
+
+	// while redis is down every replica limits on its own
+	server.Close()
+	for i, want := range []bool{true, true, false} {
+		r, err := replicas[0].Allow(ctx, "a", rule)
+		if err != nil || r.Allowed != want {
+			t.Fatalf("Allow() #%d while down = %+v, %v, want allowed %v", i, r, err, want)
+		}
+	}
+}
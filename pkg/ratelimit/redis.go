@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+	"todo/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// retryAfter is how long Redis is left alone after it failed.
+const retryAfter = 5 * time.Second
+
+// allowScript counts a request in KEYS[1], the current window, unless it
+// and KEYS[2], the previous window weighted by ARGV[2], reach the limit
+// ARGV[1]. A window is kept for ARGV[3] milliseconds, long enough to serve
+// as the previous one. It returns whether the request was counted and the
+// counts before it.
+var allowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if math.floor(previous * tonumber(ARGV[2])) + current >= tonumber(ARGV[1]) then
+	return {0, previous, current}
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return {1, previous, current}
+`)
+
+type redisLimiter struct {
+	client    redis.Scripter
+	fallback  Limiter
+	downUntil atomic.Int64
+	now       func() time.Time
+	log       logger.Logger
+}
+
+// NewRedisLimiter keeps the counts in Redis, shared by every replica. While
+// Redis fails, fallback limits instead.
+func NewRedisLimiter(client redis.Scripter, fallback Limiter) Limiter {
+	return &redisLimiter{
+		client:   client,
+		fallback: fallback,
+		now:      time.Now,
+		log:      logger.WithPrefix("ratelimit"),
+	}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	now := l.now()
+	if now.UnixNano() < l.downUntil.Load() {
+		return l.fallback.Allow(ctx, key, rule)
+	}
+
+	index, elapsed, weight := window(now, rule.Window)
+	prefix := "ratelimit:" + rule.Key() + ":" + key + ":"
+	keys := []string{prefix + strconv.FormatInt(index, 10), prefix + strconv.FormatInt(index-1, 10)}
+
+	counts, err := allowScript.Run(ctx, l.client, keys, rule.Limit, weight, (2 * rule.Window).Milliseconds()).Int64Slice()
+	if err != nil || len(counts) != 3 {
+		l.downUntil.Store(now.Add(retryAfter).UnixNano())
+		l.log.Wrap("redis failed, limiting in memory for %s: %v", retryAfter, errorOf(err, counts)).Warn()
+		return l.fallback.Allow(ctx, key, rule)
+	}
+
+	// the script weighs the counts the way result does, so both agree on
+	// whether the request was counted
+	return result(rule, int(counts[1]), int(counts[2]), elapsed, weight), nil
+}
+
+func errorOf(err error, counts []int64) error {
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("unexpected reply %v", counts)
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	if err := s.Put(ctx, "tasks/1/a.png", strings.NewReader("hello"), 5, "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "tasks", "1", "a.png")); err != nil {
+		t.Errorf("Put() left no file under root: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Join(root, "tasks", "1"))
+	if len(entries) != 1 {
+		t.Errorf("Put() left %d files, want only the blob", len(entries))
+	}
+
+	r, obj, err := s.Get(ctx, "tasks/1/a.png")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(r)
+	r.Close()
+	if string(body) != "hello" || obj.ContentType != "image/png" || obj.Size != 5 {
+		t.Errorf("Get() = %q, %+v, want hello as image/png of 5 bytes", body, obj)
+	}
+
+	if err := s.Put(ctx, "tasks/1/a", strings.NewReader("hello"), 5, ""); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	r, obj, err = s.Get(ctx, "tasks/1/a")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	r.Close()
+	if obj.ContentType != "application/octet-stream" {
+		t.Errorf("Get() of a key without extension ContentType = %q, want application/octet-stream", obj.ContentType)
+	}
+
+	if err := s.Delete(ctx, "tasks/1/a.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, _, err := s.Get(ctx, "tasks/1/a.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a deleted blob error = %v, want %v", err, ErrNotFound)
+	}
+	if err := s.Delete(ctx, "tasks/1/a.png"); err != nil {
+		t.Errorf("Delete() of a missing blob error = %v", err)
+	}
+}
+
+func TestLocalStore_path(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStore(filepath.Join(root, "blobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a file next to root that escaping keys would reach
+	if err := os.WriteFile(filepath.Join(root, "secret"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "nested", key: "tasks/1/a.png", want: filepath.Join(root, "blobs", "tasks", "1", "a.png")},
+		{name: "leading slash", key: "/tasks/a.png", want: filepath.Join(root, "blobs", "tasks", "a.png")},
+		{name: "dot segments inside root", key: "tasks/../a.png", want: filepath.Join(root, "blobs", "a.png")},
+		{name: "parent", key: "../secret", wantErr: true},
+		{name: "parent of a nested key", key: "tasks/../../secret", wantErr: true},
+		{name: "sibling with the prefix of root", key: "../blobs-other/a.png", wantErr: true},
+		{name: "root itself", key: "", wantErr: true},
+		{name: "dot", key: ".", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.(*localStore).path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("path() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("path() = %q, want %q", got, tt.want)
+			}
+			if !tt.wantErr {
+				return
+			}
+
+			if err := s.Put(ctx, tt.key, strings.NewReader("x"), 1, ""); err == nil {
+				t.Errorf("Put() error = nil, want the key refused")
+			}
+			if _, _, err := s.Get(ctx, tt.key); err == nil || errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() error = %v, want the key refused", err)
+			}
+			if err := s.Delete(ctx, tt.key); err == nil {
+				t.Errorf("Delete() error = nil, want the key refused")
+			}
+		})
+	}
+
+	body, err := os.ReadFile(filepath.Join(root, "secret"))
+	if err != nil || string(body) != "secret" {
+		t.Errorf("file outside root = %q, %v, want it untouched", body, err)
+	}
+}
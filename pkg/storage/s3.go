@@ -0,0 +1,264 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// unsignedPayload lets uploads stream without hashing the body up front.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+type S3Options struct {
+	// Endpoint is the base URL of the service, e.g. https://s3.amazonaws.com
+	// or http://localhost:9000 for a local stand-in such as MinIO.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses objects as endpoint/bucket/key instead of
+	// bucket.endpoint/key. Most local stand-ins need it.
+	PathStyle bool
+	Client    *http.Client
+}
+
+type s3Store struct {
+	endpoint *url.URL
+	opts     S3Options
+	client   *http.Client
+}
+
+// NewS3Store talks to any S3-compatible service with Signature Version 4.
+func NewS3Store(opts S3Options) (BlobStore, error) {
+	if len(opts.Bucket) == 0 {
+		return nil, errors.New("storage: s3 bucket is required")
+	}
+
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || len(endpoint.Host) == 0 {
+		return nil, fmt.Errorf("storage: s3 endpoint %q is invalid", opts.Endpoint)
+	}
+	if len(opts.Region) == 0 {
+		opts.Region = "us-east-1"
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+
+	return &s3Store{endpoint: endpoint, opts: opts, client: client}, nil
+}
+
+func (s s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s s3Store) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, Object{}, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, Object{}, err
+	}
+
+	return resp.Body, Object{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}, nil
+}
+
+func (s s3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s s3Store) request(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	path := "/" + strings.TrimPrefix(key, "/")
+	if s.opts.PathStyle {
+		path = "/" + s.opts.Bucket + path
+	} else {
+		u.Host = s.opts.Bucket + "." + u.Host
+	}
+	u.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + escapePath(path)
+	u.Path = strings.TrimSuffix(s.endpoint.Path, "/") + path
+
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs and sends req. Responses other than 2xx are turned into errors
+// and their body is closed.
+func (s s3Store) do(req *http.Request) (*http.Response, error) {
+	s.sign(req, time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("storage: s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req. It
+// signs the host and every header set on req, and the payload hash in
+// X-Amz-Content-Sha256, which defaults to an unsigned payload.
+func (s s3Store) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	payloadHash := req.Header.Get("X-Amz-Content-Sha256")
+	if len(payloadHash) == 0 {
+		payloadHash = unsignedPayload
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	host := req.Host
+	if len(host) == 0 {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "authorization" {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, s.opts.Region)
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts the parameters and encodes them like escapePath,
+// except that slashes are encoded too.
+func canonicalQuery(query url.Values) string {
+	params := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			params = append(params, escapeQuery(name)+"="+escapeQuery(value))
+		}
+	}
+	sort.Strings(params)
+
+	return strings.Join(params, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath percent-encodes everything but unreserved characters and
+// slashes, as the canonical request expects.
+func escapePath(path string) string {
+	return escape(path, true)
+}
+
+func escapeQuery(value string) string {
+	return escape(value, false)
+}
+
+func escape(value string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (slash && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
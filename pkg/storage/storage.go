@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"todo/pkg/config"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// BlobStore keeps binary objects such as task images outside the database.
+// Keys are slash-separated paths like "tasks/1/3f2a.png".
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get returns the object's content, which the caller must close.
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type Object struct {
+	ContentType string
+	Size        int64
+}
+
+var store BlobStore
+
+func Init() error {
+	cfg := config.GetConfig().Storage
+	switch cfg.Driver {
+	case "", "local":
+		root := cfg.Local.Root
+		if len(root) == 0 {
+			root = "./data/blobs"
+		}
+		s, err := NewLocalStore(root)
+		if err != nil {
+			return err
+		}
+		store = s
+	case "s3":
+		s, err := NewS3Store(S3Options{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+		})
+		if err != nil {
+			return err
+		}
+		store = s
+	default:
+		return fmt.Errorf("storage: driver %q is invalid", cfg.Driver)
+	}
+
+	return nil
+}
+
+func GetStore() BlobStore {
+	return store
+}
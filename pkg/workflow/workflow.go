@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"todo/api/enum"
+	"todo/pkg/config"
+)
+
+type Workflow interface {
+	CanTransition(from enum.TaskStatus, to enum.TaskStatus) bool
+	Next(from enum.TaskStatus) []enum.TaskStatus
+	IsTerminal(status enum.TaskStatus) bool
+	Terminal() []enum.TaskStatus
+}
+
+type workflow struct {
+	transitions map[enum.TaskStatus][]enum.TaskStatus
+	terminal    []enum.TaskStatus
+}
+
+var wf Workflow
+
+func New(transitions map[enum.TaskStatus][]enum.TaskStatus, terminal []enum.TaskStatus) Workflow {
+	return &workflow{
+		transitions: transitions,
+		terminal:    terminal,
+	}
+}
+
+// Default is used when config.yaml does not define a workflow.
+func Default() Workflow {
+	return New(map[enum.TaskStatus][]enum.TaskStatus{
+		enum.TaskStatusTodo:       {enum.TaskStatusInProgress, enum.TaskStatusBlocked, enum.TaskStatusCancelled},
+		enum.TaskStatusInProgress: {enum.TaskStatusTodo, enum.TaskStatusBlocked, enum.TaskStatusInReview, enum.TaskStatusCompleted, enum.TaskStatusCancelled},
+		enum.TaskStatusBlocked:    {enum.TaskStatusTodo, enum.TaskStatusInProgress, enum.TaskStatusCancelled},
+		enum.TaskStatusInReview:   {enum.TaskStatusInProgress, enum.TaskStatusCompleted, enum.TaskStatusCancelled},
+		enum.TaskStatusCompleted:  {enum.TaskStatusInProgress},
+		enum.TaskStatusCancelled:  {enum.TaskStatusTodo},
+	}, []enum.TaskStatus{enum.TaskStatusCompleted, enum.TaskStatusCancelled})
+}
+
+func Init() error {
+	cfg := config.GetConfig().Workflow
+	if len(cfg.Transitions) == 0 {
+		wf = Default()
+		return nil
+	}
+
+	transitions := make(map[enum.TaskStatus][]enum.TaskStatus)
+	for _, t := range cfg.Transitions {
+		from := enum.TaskStatus(t.From)
+		if !from.IsValid() {
+			return fmt.Errorf("workflow: status %q is invalid", t.From)
+		}
+
+		for _, next := range t.To {
+			to := enum.TaskStatus(next)
+			if !to.IsValid() {
+				return fmt.Errorf("workflow: status %q is invalid", next)
+			}
+			transitions[from] = append(transitions[from], to)
+		}
+	}
+
+	var terminal []enum.TaskStatus
+	for _, t := range cfg.Terminal {
+		status := enum.TaskStatus(t)
+		if !status.IsValid() {
+			return fmt.Errorf("workflow: status %q is invalid", t)
+		}
+		terminal = append(terminal, status)
+	}
+	if len(terminal) == 0 {
+		return errors.New("workflow: a terminal status is required")
+	}
+
+	wf = New(transitions, terminal)
+	return nil
+}
+
+func GetWorkflow() Workflow {
+	return wf
+}
+
+func (w workflow) CanTransition(from enum.TaskStatus, to enum.TaskStatus) bool {
+	if from == to {
+		return true
+	}
+
+	for _, next := range w.transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (w workflow) Next(from enum.TaskStatus) []enum.TaskStatus {
+	return w.transitions[from]
+}
+
+func (w workflow) IsTerminal(status enum.TaskStatus) bool {
+	for _, t := range w.terminal {
+		if t == status {
+			return true
+		}
+	}
+	return false
+}
+
+func (w workflow) Terminal() []enum.TaskStatus {
+	return w.terminal
+}
@@ -0,0 +1,202 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"todo/api/enum"
+	"todo/pkg/config"
+
+	"github.com/spf13/viper"
+)
+
+// loadConfig loads a config.yaml holding data.
+func loadConfig(t *testing.T, data string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.AddConfigPath(dir)
+	if err := config.Init(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    Workflow
+		wantErr string
+	}{
+		{
+			name: "success",
+			config: `
+workflow:
+  terminal: [COMPLETED]
+  transitions:
+    - from: TODO
+      to: [IN_PROGRESS, COMPLETED]
+    - from: IN_PROGRESS
+      to: [COMPLETED]
+    - from: TODO
+      to: [BLOCKED]
+`,
+			want: New(map[enum.TaskStatus][]enum.TaskStatus{
+				enum.TaskStatusTodo:       {enum.TaskStatusInProgress, enum.TaskStatusCompleted, enum.TaskStatusBlocked},
+				enum.TaskStatusInProgress: {enum.TaskStatusCompleted},
+			}, []enum.TaskStatus{enum.TaskStatusCompleted}),
+		},
+		{
+			name: "no transitions",
+			config: `
+workflow:
+  terminal: [COMPLETED]
+  transitions: []
+`,
+			want: Default(),
+		},
+		{
+			name:   "no workflow",
+			config: "workflow: {}\n",
+			want:   Default(),
+		},
+		{
+			name: "unknown from status",
+			config: `
+workflow:
+  terminal: [COMPLETED]
+  transitions:
+    - from: DONE
+      to: [TODO]
+`,
+			wantErr: `workflow: status "DONE" is invalid`,
+		},
+		{
+			name: "unknown to status",
+			config: `
+workflow:
+  terminal: [COMPLETED]
+  transitions:
+    - from: TODO
+      to: [todo]
+`,
+			wantErr: `workflow: status "todo" is invalid`,
+		},
+		{
+			name: "unknown terminal status",
+			config: `
+workflow:
+  terminal: [DONE]
+  transitions:
+    - from: TODO
+      to: [COMPLETED]
+`,
+			wantErr: `workflow: status "DONE" is invalid`,
+		},
+		{
+			name: "missing terminal",
+			config: `
+workflow:
+  transitions:
+    - from: TODO
+      to: [COMPLETED]
+`,
+			wantErr: "workflow: a terminal status is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loadConfig(t, tt.config)
+			wf = nil
+
+			err := Init()
+			if (err != nil) != (tt.wantErr != "") || err != nil && err.Error() != tt.wantErr {
+				t.Fatalf("Init() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := GetWorkflow(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetWorkflow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workflow_CanTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		from enum.TaskStatus
+		to   enum.TaskStatus
+		want bool
+	}{
+		{name: "allowed", from: enum.TaskStatusTodo, to: enum.TaskStatusInProgress, want: true},
+		{name: "not allowed", from: enum.TaskStatusTodo, to: enum.TaskStatusCompleted, want: false},
+		{name: "allowed one way only", from: enum.TaskStatusCancelled, to: enum.TaskStatusBlocked, want: false},
+		{name: "out of a terminal status", from: enum.TaskStatusCompleted, to: enum.TaskStatusInProgress, want: true},
+		{name: "same status", from: enum.TaskStatusCompleted, to: enum.TaskStatusCompleted, want: true},
+		{name: "unknown status", from: "DONE", to: enum.TaskStatusTodo, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default().CanTransition(tt.from, tt.to); got != tt.want {
+				t.Errorf("workflow.CanTransition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workflow_Next(t *testing.T) {
+	tests := []struct {
+		name string
+		from enum.TaskStatus
+		want []enum.TaskStatus
+	}{
+		{
+			name: "in progress",
+			from: enum.TaskStatusInProgress,
+			want: []enum.TaskStatus{enum.TaskStatusTodo, enum.TaskStatusBlocked, enum.TaskStatusInReview, enum.TaskStatusCompleted, enum.TaskStatusCancelled},
+		},
+		{
+			name: "cancelled",
+			from: enum.TaskStatusCancelled,
+			want: []enum.TaskStatus{enum.TaskStatusTodo},
+		},
+		{
+			name: "unknown status",
+			from: "DONE",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default().Next(tt.from); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workflow.Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_workflow_IsTerminal(t *testing.T) {
+	tests := []struct {
+		name   string
+		status enum.TaskStatus
+		want   bool
+	}{
+		{name: "completed", status: enum.TaskStatusCompleted, want: true},
+		{name: "cancelled", status: enum.TaskStatusCancelled, want: true},
+		{name: "in review", status: enum.TaskStatusInReview, want: false},
+		{name: "unknown status", status: "DONE", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Default().IsTerminal(tt.status); got != tt.want {
+				t.Errorf("workflow.IsTerminal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}